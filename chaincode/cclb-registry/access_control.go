@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// cclbMSPID is the MSP of the Central Land Ledger Board org (see network/configtx.yaml)
+	cclbMSPID = "CCLEBMSP"
+
+	// cclbAdminRole is the certificate 'role' attribute carried by CCLB administrators
+	cclbAdminRole = "cclb_admin"
+)
+
+// requireCCLBAdmin ensures the caller is enrolled under the CCLB MSP with the admin role
+func requireCCLBAdmin(ctx contractapi.TransactionContextInterface) error {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read caller MSP ID: %v", err)
+	}
+	if mspID != cclbMSPID {
+		return fmt.Errorf("access denied for MSP: %s", mspID)
+	}
+
+	role, found, err := ctx.GetClientIdentity().GetAttributeValue("role")
+	if err != nil || !found {
+		return fmt.Errorf("role attribute missing")
+	}
+	if role != cclbAdminRole {
+		return fmt.Errorf("access denied for role: %s", role)
+	}
+
+	return nil
+}
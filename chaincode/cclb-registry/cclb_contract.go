@@ -1,7 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -26,45 +30,210 @@ func (c *CCLBRegistryContract) InitLedger(ctx contractapi.TransactionContextInte
 type PropertyID struct {
 	ID              string `json:"id"`              // Format: CCLB-YEAR-STATE-SEQUENCE
 	StateCode       string `json:"stateCode"`       // TS, KA, AP, etc.
+	Year            int    `json:"year"`            // Issuance year (from tx timestamp)
+	Sequence        int    `json:"sequence"`        // Per-state, per-year sequence
+	SurveyNo        string `json:"surveyNo"`        // Survey tuple submitted by the state;
+	District        string `json:"district"`        // re-checked by VerifyStateRecord
+	Mandal          string `json:"mandal"`          // Mandal / taluk
+	Village         string `json:"village"`         // Revenue village
 	SubmittedBy     string `json:"submittedBy"`     // State organization MSP ID
 	CreatedAt       string `json:"createdAt"`       // Timestamp
 	VerificationSig string `json:"verificationSig"` // CCLB signature/attestation
 }
 
+// PropertyIDSequence tracks the last issued sequence per state per year
+// Stored under COUNTER:<STATE>:<YEAR> on cclb-global
+type PropertyIDSequence struct {
+	StateCode string `json:"stateCode"`
+	Year      int    `json:"year"`
+	Sequence  int    `json:"sequence"`
+}
+
 // StateRegistry maps state codes to organization MSPs and channels
 type StateRegistry struct {
 	StateCode      string `json:"stateCode"`      // TS, KA, AP
 	StateName      string `json:"stateName"`      // Telangana, Karnataka
 	OrgMSPID       string `json:"orgMSPID"`       // StateTS-MSP
 	StateChannelID string `json:"stateChannelID"` // state-ts
+	Status         string `json:"status"`         // ACTIVE, SUSPENDED, DEACTIVATED
+	StatusReason   string `json:"statusReason,omitempty"`
 	InitializedAt  string `json:"initializedAt"`
+	UpdatedAt      string `json:"updatedAt"`
+}
+
+// StateRecordVerification is CCLB's verdict on a state record bound to a Property ID
+// Stored under VERIFY:<propertyID>; TxID is passed back to the state chaincode
+type StateRecordVerification struct {
+	PropertyID string `json:"propertyId"`
+	StateCode  string `json:"stateCode"`
+	SurveyNo   string `json:"surveyNo"`
+	District   string `json:"district"`
+	Mandal     string `json:"mandal"`
+	Village    string `json:"village"`
+	StateTxID  string `json:"stateTxId"` // State tx that created the record
+	Status     string `json:"status"`    // VERIFIED, REJECTED
+	Reason     string `json:"reason,omitempty"`
+	VerifiedBy string `json:"verifiedBy"` // CCLB MSP ID
+	VerifiedAt string `json:"verifiedAt"`
+	TxID       string `json:"txId"` // CCLB verification tx
 }
 
+// Verification outcomes
+const (
+	VerificationVerified = "VERIFIED"
+	VerificationRejected = "REJECTED"
+)
+
+// State registration statuses
+const (
+	StateStatusActive      = "ACTIVE"
+	StateStatusSuspended   = "SUSPENDED"
+	StateStatusDeactivated = "DEACTIVATED"
+)
+
+const (
+	sequenceKeyPrefix     = "COUNTER"
+	stateRegistryPrefix   = "STATE:"
+	verificationKeyPrefix = "VERIFY:"
+)
+
+// channelIDPattern matches valid Fabric channel names
+var channelIDPattern = regexp.MustCompile(`^[a-z][a-z0-9.-]*$`)
+
 // IssuePropertyID is called by state ledgers to request a globally unique Property ID
-// CCLB endorsement policy ensures this transaction is signed by CCLB admin
 // Parameters:
-//   - stateCode: State code (TS, KA, AP)
-//   - requesterMSP: MSP ID of the requesting state org
+//   - stateCode: State code (TS, KA, AP), must be registered and ACTIVE
+//   - surveyNo, district, mandal, village: survey tuple of the parcel being registered
 //
+// The submitting MSP is taken from the client identity and must be the
+// state's registered org MSP (or CCLB itself).
 // Returns: PropertyID with CCLB-generated ID
 func (c *CCLBRegistryContract) IssuePropertyID(
 	ctx contractapi.TransactionContextInterface,
 	stateCode string,
+	surveyNo string,
+	district string,
+	mandal string,
+	village string,
 ) (*PropertyID, error) {
-	// TODO: Implement role validation (CCLB admin only)
-	// TODO: Validate stateCode against registered states
-	// TODO: Generate atomic sequence number per state per year
-	// TODO: Create composite ID: CCLB-<YEAR>-<STATE>-<SEQ>
-	// TODO: Persist to state on cclb-global channel
-	// TODO: Emit PropertyIDIssuedEvent
-
-	return &PropertyID{
-		ID:              fmt.Sprintf("CCLB-2026-%s-000001", stateCode),
-		StateCode:       stateCode,
-		SubmittedBy:     "TS-MSP",
-		CreatedAt:       "2026-01-26T00:00:00Z",
-		VerificationSig: "CCLB-VERIFIED",
-	}, nil
+	code, err := validateStateCode(stateCode)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(surveyNo) == "" || strings.TrimSpace(district) == "" ||
+		strings.TrimSpace(mandal) == "" || strings.TrimSpace(village) == "" {
+		return nil, fmt.Errorf("survey number, district, mandal and village are required")
+	}
+
+	// Only registered, active states may obtain new IDs
+	registry, err := getStateRegistry(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if registry.Status != StateStatusActive {
+		return nil, fmt.Errorf("state %s is %s and cannot be issued Property IDs", code, registry.Status)
+	}
+
+	// Caller must be the state's own org, or CCLB issuing on its behalf
+	submittedBy, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read submitter MSP ID: %v", err)
+	}
+	if submittedBy != registry.OrgMSPID && submittedBy != cclbMSPID {
+		return nil, fmt.Errorf("MSP %s cannot request Property IDs for state %s", submittedBy, code)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	year := txTime.Year()
+
+	// Allocate next sequence for this state/year (atomic within the transaction;
+	// concurrent issuers conflict on the counter key and one is invalidated at commit)
+	sequence, err := nextSequence(ctx, code, year)
+	if err != nil {
+		return nil, err
+	}
+
+	propertyID := &PropertyID{
+		ID:              fmt.Sprintf("CCLB-%d-%s-%06d", year, code, sequence),
+		StateCode:       code,
+		Year:            year,
+		Sequence:        sequence,
+		SurveyNo:        strings.TrimSpace(surveyNo),
+		District:        strings.TrimSpace(district),
+		Mandal:          strings.TrimSpace(mandal),
+		Village:         strings.TrimSpace(village),
+		SubmittedBy:     submittedBy,
+		CreatedAt:       txTime.Format(time.RFC3339),
+		VerificationSig: ctx.GetStub().GetTxID(),
+	}
+
+	existing, err := ctx.GetStub().GetState(propertyID.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("property ID %s already issued", propertyID.ID)
+	}
+
+	propJSON, err := json.Marshal(propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal property ID: %v", err)
+	}
+	if err := ctx.GetStub().PutState(propertyID.ID, propJSON); err != nil {
+		return nil, fmt.Errorf("failed to store property ID: %v", err)
+	}
+
+	if err := c.emitPropertyIDIssuedEvent(ctx, propertyID.ID, code, submittedBy); err != nil {
+		return nil, fmt.Errorf("failed to emit PropertyIDIssued event: %v", err)
+	}
+
+	return propertyID, nil
+}
+
+// nextSequence increments and persists the Property ID counter for a state/year
+func nextSequence(
+	ctx contractapi.TransactionContextInterface,
+	stateCode string,
+	year int,
+) (int, error) {
+	counterKey := fmt.Sprintf("%s:%s:%d", sequenceKeyPrefix, stateCode, year)
+
+	counterJSON, err := ctx.GetStub().GetState(counterKey)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read counter: %v", err)
+	}
+
+	counter := PropertyIDSequence{StateCode: stateCode, Year: year}
+	if counterJSON != nil {
+		if err := json.Unmarshal(counterJSON, &counter); err != nil {
+			return 0, fmt.Errorf("failed to parse counter: %v", err)
+		}
+	}
+
+	counter.Sequence++
+
+	updatedJSON, err := json.Marshal(counter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal counter: %v", err)
+	}
+	if err := ctx.GetStub().PutState(counterKey, updatedJSON); err != nil {
+		return 0, fmt.Errorf("failed to update counter: %v", err)
+	}
+
+	return counter.Sequence, nil
+}
+
+// txTimestamp returns the transaction header timestamp
+// Identical on every endorsing peer, unlike time.Now()
+func txTimestamp(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read transaction timestamp: %v", err)
+	}
+	return ts.AsTime().UTC(), nil
 }
 
 // QueryPropertyID retrieves a Property ID from the national registry
@@ -81,8 +250,12 @@ func (c *CCLBRegistryContract) QueryPropertyID(
 		return nil, fmt.Errorf("property ID %s does not exist in national registry", propertyID)
 	}
 
-	// TODO: Parse and return PropertyID
-	return nil, nil
+	var prop PropertyID
+	if err := json.Unmarshal(propJSON, &prop); err != nil {
+		return nil, fmt.Errorf("failed to parse property ID %s: %v", propertyID, err)
+	}
+
+	return &prop, nil
 }
 
 // RegisterState is called once per state to establish the state-<code> channel relationship
@@ -94,18 +267,57 @@ func (c *CCLBRegistryContract) RegisterState(
 	orgMSPID string,
 	stateChannelID string,
 ) (*StateRegistry, error) {
-	// TODO: Validate caller is CCLB
-	// TODO: Validate stateCode format
-	// TODO: Store StateRegistry record
-	// TODO: Emit StateRegisteredEvent
-
-	return &StateRegistry{
-		StateCode:      stateCode,
-		StateName:      stateName,
-		OrgMSPID:       orgMSPID,
+	if err := requireCCLBAdmin(ctx); err != nil {
+		return nil, fmt.Errorf("only CCLB admins can register states: %v", err)
+	}
+
+	code, err := validateStateCode(stateCode)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(stateName) == "" {
+		return nil, fmt.Errorf("state name is required")
+	}
+	if strings.TrimSpace(orgMSPID) == "" {
+		return nil, fmt.Errorf("org MSP ID is required")
+	}
+	if !channelIDPattern.MatchString(stateChannelID) {
+		return nil, fmt.Errorf("invalid channel ID: %s", stateChannelID)
+	}
+
+	registryKey := stateRegistryKey(code)
+	existing, err := ctx.GetStub().GetState(registryKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state registry: %v", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("state %s is already registered", code)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &StateRegistry{
+		StateCode:      code,
+		StateName:      strings.TrimSpace(stateName),
+		OrgMSPID:       strings.TrimSpace(orgMSPID),
 		StateChannelID: stateChannelID,
-		InitializedAt:  "2026-01-26T00:00:00Z",
-	}, nil
+		Status:         StateStatusActive,
+		InitializedAt:  txTime.Format(time.RFC3339),
+		UpdatedAt:      txTime.Format(time.RFC3339),
+	}
+
+	if err := putStateRegistry(ctx, registry); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitStateRegisteredEvent(ctx, code, registry.StateName, stateChannelID); err != nil {
+		return nil, fmt.Errorf("failed to emit StateRegistered event: %v", err)
+	}
+
+	return registry, nil
 }
 
 // QueryStateRegistry retrieves a state's channel and org information
@@ -114,8 +326,146 @@ func (c *CCLBRegistryContract) QueryStateRegistry(
 	ctx contractapi.TransactionContextInterface,
 	stateCode string,
 ) (*StateRegistry, error) {
-	registryKey := fmt.Sprintf("STATE:%s", stateCode)
-	registryJSON, err := ctx.GetStub().GetState(registryKey)
+	code, err := validateStateCode(stateCode)
+	if err != nil {
+		return nil, err
+	}
+	return getStateRegistry(ctx, code)
+}
+
+// ListStates returns every registered state, including suspended and deactivated ones
+// The backend multi-channel router builds its channel table from this
+func (c *CCLBRegistryContract) ListStates(
+	ctx contractapi.TransactionContextInterface,
+) ([]*StateRegistry, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByRange(stateRegistryPrefix, stateRegistryPrefix+"~")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state registry: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	states := []*StateRegistry{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var registry StateRegistry
+		if err := json.Unmarshal(queryResponse.Value, &registry); err != nil {
+			return nil, fmt.Errorf("failed to parse state registry %s: %v", queryResponse.Key, err)
+		}
+		states = append(states, &registry)
+	}
+
+	return states, nil
+}
+
+// SuspendState temporarily stops a state from obtaining new Property IDs
+// Only CCLB can call this; ReactivateState lifts the suspension
+func (c *CCLBRegistryContract) SuspendState(
+	ctx contractapi.TransactionContextInterface,
+	stateCode string,
+	reason string,
+) (*StateRegistry, error) {
+	return c.setStateStatus(ctx, stateCode, StateStatusSuspended, reason)
+}
+
+// DeactivateState permanently retires a state registration
+// Only CCLB can call this; a deactivated state cannot be reactivated
+func (c *CCLBRegistryContract) DeactivateState(
+	ctx contractapi.TransactionContextInterface,
+	stateCode string,
+	reason string,
+) (*StateRegistry, error) {
+	return c.setStateStatus(ctx, stateCode, StateStatusDeactivated, reason)
+}
+
+// ReactivateState returns a suspended state to ACTIVE
+// Only CCLB can call this
+func (c *CCLBRegistryContract) ReactivateState(
+	ctx contractapi.TransactionContextInterface,
+	stateCode string,
+) (*StateRegistry, error) {
+	return c.setStateStatus(ctx, stateCode, StateStatusActive, "")
+}
+
+// setStateStatus applies a status transition to a registered state
+func (c *CCLBRegistryContract) setStateStatus(
+	ctx contractapi.TransactionContextInterface,
+	stateCode string,
+	status string,
+	reason string,
+) (*StateRegistry, error) {
+	if err := requireCCLBAdmin(ctx); err != nil {
+		return nil, fmt.Errorf("only CCLB admins can change state status: %v", err)
+	}
+
+	code, err := validateStateCode(stateCode)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := getStateRegistry(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if registry.Status == StateStatusDeactivated {
+		return nil, fmt.Errorf("state %s is deactivated", code)
+	}
+	if registry.Status == status {
+		return nil, fmt.Errorf("state %s is already %s", code, status)
+	}
+	if status != StateStatusActive && strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a reason is required to set state %s to %s", code, status)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := registry.Status
+	registry.Status = status
+	registry.StatusReason = reason
+	registry.UpdatedAt = txTime.Format(time.RFC3339)
+
+	if err := putStateRegistry(ctx, registry); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitStateStatusChangedEvent(ctx, code, previous, status, reason); err != nil {
+		return nil, fmt.Errorf("failed to emit StateStatusChanged event: %v", err)
+	}
+
+	return registry, nil
+}
+
+// validateStateCode checks a two-letter state code against the known state set
+func validateStateCode(stateCode string) (string, error) {
+	trimmed := strings.TrimSpace(stateCode)
+	if len(trimmed) != 2 {
+		return "", fmt.Errorf("malformed state code: %q (expected two-letter code)", stateCode)
+	}
+	code := normalizeStateCode(trimmed)
+	if code == "" {
+		return "", fmt.Errorf("unknown state code: %s", stateCode)
+	}
+	return code, nil
+}
+
+// stateRegistryKey returns the world state key for a state registration
+func stateRegistryKey(stateCode string) string {
+	return stateRegistryPrefix + stateCode
+}
+
+// getStateRegistry loads a registered state by its canonical code
+func getStateRegistry(
+	ctx contractapi.TransactionContextInterface,
+	stateCode string,
+) (*StateRegistry, error) {
+	registryJSON, err := ctx.GetStub().GetState(stateRegistryKey(stateCode))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read state registry: %v", err)
 	}
@@ -123,24 +473,198 @@ func (c *CCLBRegistryContract) QueryStateRegistry(
 		return nil, fmt.Errorf("state %s is not registered", stateCode)
 	}
 
-	// TODO: Parse and return StateRegistry
-	return nil, nil
+	var registry StateRegistry
+	if err := json.Unmarshal(registryJSON, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse state registry: %v", err)
+	}
+
+	return &registry, nil
+}
+
+// putStateRegistry persists a state registration under STATE:<code>
+func putStateRegistry(
+	ctx contractapi.TransactionContextInterface,
+	registry *StateRegistry,
+) error {
+	registryJSON, err := json.Marshal(registry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal state registry: %v", err)
+	}
+	if err := ctx.GetStub().PutState(stateRegistryKey(registry.StateCode), registryJSON); err != nil {
+		return fmt.Errorf("failed to store state registry: %v", err)
+	}
+	return nil
 }
 
 // VerifyStateRecord is called after a state creates a land record
 // CCLB verifies the record references a valid CCLB Property ID
-// Cross-channel verification via channel events
+// Cross-channel verification via channel events:
+//  1. State emits StateRecordCreated on state-<code>
+//  2. CCLB relays the tuple here; the verdict is stored and VerificationCompleted emitted
+//  3. CCLB relays the verdict (with this tx ID) to AcceptCCLBVerification on state-<code>
+//
+// A mismatch is not an error: it is recorded as REJECTED with a reason.
 func (c *CCLBRegistryContract) VerifyStateRecord(
 	ctx contractapi.TransactionContextInterface,
 	propertyID string,
 	stateCode string,
-) (bool, error) {
-	// TODO: Query cclb-global ledger for propertyID
-	// TODO: Verify ownership matches CCLB registry
-	// TODO: Record verification timestamp
-	// TODO: Emit VerificationCompletedEvent
+	surveyNo string,
+	district string,
+	mandal string,
+	village string,
+	stateTxID string,
+) (*StateRecordVerification, error) {
+	if err := requireCCLBAdmin(ctx); err != nil {
+		return nil, fmt.Errorf("only CCLB admins can verify state records: %v", err)
+	}
+
+	code, err := validateStateCode(stateCode)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := getVerification(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil && previous.Status == VerificationVerified {
+		return nil, fmt.Errorf("property ID %s is already verified (tx %s)", propertyID, previous.TxID)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &StateRecordVerification{
+		PropertyID: propertyID,
+		StateCode:  code,
+		SurveyNo:   surveyNo,
+		District:   district,
+		Mandal:     mandal,
+		Village:    village,
+		StateTxID:  stateTxID,
+		Status:     VerificationVerified,
+		VerifiedBy: cclbMSPID,
+		VerifiedAt: txTime.Format(time.RFC3339),
+		TxID:       ctx.GetStub().GetTxID(),
+	}
+
+	reason, err := checkStateRecord(ctx, propertyID, code, surveyNo, district, mandal, village)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		verification.Status = VerificationRejected
+		verification.Reason = reason
+	}
+
+	verificationJSON, err := json.Marshal(verification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal verification: %v", err)
+	}
+	if err := ctx.GetStub().PutState(verificationKeyPrefix+propertyID, verificationJSON); err != nil {
+		return nil, fmt.Errorf("failed to store verification: %v", err)
+	}
+
+	if err := c.emitVerificationCompletedEvent(
+		ctx,
+		propertyID,
+		code,
+		verification.Status,
+		verification.Reason,
+	); err != nil {
+		return nil, fmt.Errorf("failed to emit VerificationCompleted event: %v", err)
+	}
+
+	return verification, nil
+}
+
+// QueryVerification returns the latest CCLB verdict for a Property ID
+func (c *CCLBRegistryContract) QueryVerification(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) (*StateRecordVerification, error) {
+	verification, err := getVerification(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return nil, fmt.Errorf("property ID %s has not been verified", propertyID)
+	}
+	return verification, nil
+}
+
+// getVerification loads the stored verdict for a Property ID
+// Returns nil (no error) when the Property ID has never been verified.
+func getVerification(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) (*StateRecordVerification, error) {
+	verificationJSON, err := ctx.GetStub().GetState(verificationKeyPrefix + propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification: %v", err)
+	}
+	if verificationJSON == nil {
+		return nil, nil
+	}
+
+	var verification StateRecordVerification
+	if err := json.Unmarshal(verificationJSON, &verification); err != nil {
+		return nil, fmt.Errorf("failed to parse verification: %v", err)
+	}
+
+	return &verification, nil
+}
+
+// checkStateRecord compares a state submission against the CCLB registry
+// Returns a rejection reason, or "" when the record matches
+func checkStateRecord(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	stateCode string,
+	surveyNo string,
+	district string,
+	mandal string,
+	village string,
+) (string, error) {
+	propJSON, err := ctx.GetStub().GetState(propertyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if propJSON == nil {
+		return fmt.Sprintf("property ID %s was not issued by CCLB", propertyID), nil
+	}
+
+	var prop PropertyID
+	if err := json.Unmarshal(propJSON, &prop); err != nil {
+		return "", fmt.Errorf("failed to parse property ID %s: %v", propertyID, err)
+	}
+
+	if prop.StateCode != stateCode {
+		return fmt.Sprintf("property ID %s was issued to state %s, not %s", propertyID, prop.StateCode, stateCode), nil
+	}
+
+	var mismatched []string
+	for _, field := range []struct {
+		name      string
+		issued    string
+		submitted string
+	}{
+		{"surveyNo", prop.SurveyNo, surveyNo},
+		{"district", prop.District, district},
+		{"mandal", prop.Mandal, mandal},
+		{"village", prop.Village, village},
+	} {
+		if !strings.EqualFold(strings.TrimSpace(field.issued), strings.TrimSpace(field.submitted)) {
+			mismatched = append(mismatched, fmt.Sprintf("%s (issued %q, submitted %q)", field.name, field.issued, field.submitted))
+		}
+	}
+	if len(mismatched) > 0 {
+		return "survey tuple mismatch: " + strings.Join(mismatched, ", "), nil
+	}
 
-	return true, nil
+	return "", nil
 }
 
 func main() {
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// cclbLedger runs transactions against a single cclb-global MockStub
+type cclbLedger struct {
+	t        *testing.T
+	contract *CCLBRegistryContract
+	stub     *shimtest.MockStub
+	ctx      *contractapi.TransactionContext
+	admin    []byte
+	txTime   time.Time
+	txCount  int
+}
+
+func newCCLBLedger(t *testing.T) *cclbLedger {
+	stub := shimtest.NewMockStub("cclb-global", nil)
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(stub)
+	return &cclbLedger{
+		t:        t,
+		contract: new(CCLBRegistryContract),
+		stub:     stub,
+		ctx:      ctx,
+		admin:    testCreator(t, cclbMSPID, cclbAdminRole),
+		txTime:   time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC),
+	}
+}
+
+// invoke runs fn as one transaction submitted by creator at l.txTime
+// Events emitted by the transaction are returned by name.
+func (l *cclbLedger) invoke(creator []byte, fn func(ctx contractapi.TransactionContextInterface) (interface{}, error)) (interface{}, map[string][]byte, error) {
+	l.t.Helper()
+
+	l.txCount++
+	txID := fmt.Sprintf("tx-%d", l.txCount)
+	l.stub.MockTransactionStart(txID)
+	l.stub.TxTimestamp = timestamppb.New(l.txTime)
+	l.stub.Creator = creator
+	clientIdentity, err := cid.New(l.stub)
+	if err != nil {
+		l.t.Fatalf("failed to load client identity: %v", err)
+	}
+	l.ctx.SetClientIdentity(clientIdentity)
+
+	result, err := fn(l.ctx)
+	l.stub.MockTransactionEnd(txID)
+
+	events := map[string][]byte{}
+	for len(l.stub.ChaincodeEventsChannel) > 0 {
+		event := <-l.stub.ChaincodeEventsChannel
+		events[event.EventName] = event.Payload
+	}
+	return result, events, err
+}
+
+// must runs fn as the CCLB admin and fails the test if it returns an error
+func (l *cclbLedger) must(fn func(ctx contractapi.TransactionContextInterface) (interface{}, error)) interface{} {
+	l.t.Helper()
+
+	result, _, err := l.invoke(l.admin, fn)
+	if err != nil {
+		l.t.Fatalf("transaction failed: %v", err)
+	}
+	return result
+}
+
+// register adds a state with its conventional MSP and channel names
+func (l *cclbLedger) register(code string, name string) {
+	l.t.Helper()
+
+	l.must(func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return l.contract.RegisterState(ctx, code, name, "StateOrg"+code+"MSP", "state-"+strings.ToLower(code))
+	})
+}
+
+// issue requests a Property ID for a parcel in Rangareddy as the given MSP
+func (l *cclbLedger) issue(creator []byte, code string, surveyNo string) (*PropertyID, error) {
+	l.t.Helper()
+
+	result, _, err := l.invoke(creator, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return l.contract.IssuePropertyID(ctx, code, surveyNo, "Rangareddy", "Shamshabad", "Kothur")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PropertyID), nil
+}
+
+func TestIssuePropertyIDSequencesPerStateAndYear(t *testing.T) {
+	l := newCCLBLedger(t)
+	l.register("TS", "Telangana")
+	l.register("KA", "Karnataka")
+	telangana := testCreator(t, "StateOrgTSMSP", "registrar")
+	karnataka := testCreator(t, "StateOrgKAMSP", "registrar")
+
+	tests := []struct {
+		creator     []byte
+		submittedBy string
+		state       string
+		year        int
+		want        string
+	}{
+		{telangana, "StateOrgTSMSP", "TS", 2025, "CCLB-2025-TS-000001"},
+		{telangana, "StateOrgTSMSP", "ts", 2025, "CCLB-2025-TS-000002"},
+		{karnataka, "StateOrgKAMSP", "KA", 2025, "CCLB-2025-KA-000001"}, // Each state counts on its own
+		{l.admin, cclbMSPID, "TS", 2025, "CCLB-2025-TS-000003"},         // CCLB may issue on a state's behalf
+		{telangana, "StateOrgTSMSP", "TS", 2026, "CCLB-2026-TS-000001"}, // A new year starts again at 1
+		{karnataka, "StateOrgKAMSP", "KA", 2026, "CCLB-2026-KA-000001"},
+		{telangana, "StateOrgTSMSP", "TS", 2026, "CCLB-2026-TS-000002"},
+	}
+	for i, tc := range tests {
+		l.txTime = time.Date(tc.year, 6, 1, 9, 0, 0, 0, time.UTC)
+		issued, err := l.issue(tc.creator, tc.state, fmt.Sprintf("101/%d", i))
+		if err != nil {
+			t.Fatalf("issue %d failed: %v", i+1, err)
+		}
+		if issued.ID != tc.want {
+			t.Fatalf("issue %d = %s, want %s", i+1, issued.ID, tc.want)
+		}
+
+		stored, err := l.contract.QueryPropertyID(l.ctx, tc.want)
+		if err != nil {
+			t.Fatalf("issued Property ID %s was not stored: %v", tc.want, err)
+		}
+		if stored.SubmittedBy != tc.submittedBy || stored.Year != tc.year || stored.SurveyNo != issued.SurveyNo {
+			t.Fatalf("stored Property ID %+v, want submitted by %s in %d", stored, tc.submittedBy, tc.year)
+		}
+	}
+
+	// Another state's org cannot draw on Telangana's sequence
+	if _, err := l.issue(karnataka, "TS", "999"); err == nil || !strings.Contains(err.Error(), "cannot request Property IDs") {
+		t.Fatalf("Karnataka issuing for Telangana: err = %v, want a denial", err)
+	}
+	if _, err := l.issue(telangana, "AP", "999"); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Fatalf("issuing for an unregistered state: err = %v, want it refused", err)
+	}
+	if _, err := l.issue(telangana, "TS", " "); err == nil || !strings.Contains(err.Error(), "required") {
+		t.Fatalf("issuing without a survey number: err = %v, want it refused", err)
+	}
+	if next, err := l.issue(telangana, "TS", "102"); err != nil || next.ID != "CCLB-2026-TS-000003" {
+		t.Fatalf("issue after refusals = %v (%v), want CCLB-2026-TS-000003", next, err)
+	}
+}
+
+func TestRegisterStateValidation(t *testing.T) {
+	l := newCCLBLedger(t)
+	l.register("TS", "Telangana")
+
+	tests := []struct {
+		name    string
+		creator []byte
+		code    string
+		channel string
+		err     string
+	}{
+		{"state registrar", testCreator(t, "StateOrgTSMSP", "registrar"), "KA", "state-ka", "only CCLB admins"},
+		{"CCLB auditor", testCreator(t, cclbMSPID, "auditor"), "KA", "state-ka", "only CCLB admins"},
+		{"duplicate", l.admin, "ts", "state-ts", "already registered"},
+		{"unknown code", l.admin, "XX", "state-xx", "unknown state code"},
+		{"full name", l.admin, "Karnataka", "state-ka", "malformed state code"},
+		{"bad channel", l.admin, "KA", "State_KA", "invalid channel ID"},
+	}
+	for _, tc := range tests {
+		_, events, err := l.invoke(tc.creator, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return l.contract.RegisterState(ctx, tc.code, "Karnataka", "StateOrgKAMSP", tc.channel)
+		})
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.err)
+		}
+		if len(events) != 0 {
+			t.Errorf("%s: refused registration emitted %v", tc.name, events)
+		}
+	}
+
+	result, events, err := l.invoke(l.admin, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return l.contract.RegisterState(ctx, " ka ", "Karnataka", "StateOrgKAMSP", "state-ka")
+	})
+	if err != nil {
+		t.Fatalf("registering Karnataka failed: %v", err)
+	}
+	if registry := result.(*StateRegistry); registry.StateCode != "KA" || registry.Status != StateStatusActive {
+		t.Fatalf("registered %+v, want KA active", registry)
+	}
+	var registered StateRegisteredEvent
+	if err := json.Unmarshal(events["StateRegistered"], &registered); err != nil || registered.ChannelID != "state-ka" {
+		t.Fatalf("StateRegistered event %s (%v), want channel state-ka", events["StateRegistered"], err)
+	}
+
+	stored, err := l.contract.QueryStateRegistry(l.ctx, "ka")
+	if err != nil || stored.OrgMSPID != "StateOrgKAMSP" || stored.StateChannelID != "state-ka" {
+		t.Fatalf("QueryStateRegistry(ka) = %+v (%v), want Karnataka's registration", stored, err)
+	}
+	if _, err := l.contract.QueryStateRegistry(l.ctx, "AP"); err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Fatalf("QueryStateRegistry(AP): err = %v, want it not registered", err)
+	}
+	states, err := l.contract.ListStates(l.ctx)
+	if err != nil || len(states) != 2 || states[0].StateCode != "KA" || states[1].StateCode != "TS" {
+		t.Fatalf("ListStates = %+v (%v), want KA and TS", states, err)
+	}
+}
+
+func TestStateStatusLifecycle(t *testing.T) {
+	l := newCCLBLedger(t)
+	l.register("TS", "Telangana")
+	telangana := testCreator(t, "StateOrgTSMSP", "registrar")
+
+	setStatus := func(creator []byte, change func(ctx contractapi.TransactionContextInterface) (*StateRegistry, error)) (*StateRegistry, map[string][]byte, error) {
+		result, events, err := l.invoke(creator, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return change(ctx)
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return result.(*StateRegistry), events, nil
+	}
+	suspend := func(reason string) func(ctx contractapi.TransactionContextInterface) (*StateRegistry, error) {
+		return func(ctx contractapi.TransactionContextInterface) (*StateRegistry, error) {
+			return l.contract.SuspendState(ctx, "TS", reason)
+		}
+	}
+	deactivate := func(reason string) func(ctx contractapi.TransactionContextInterface) (*StateRegistry, error) {
+		return func(ctx contractapi.TransactionContextInterface) (*StateRegistry, error) {
+			return l.contract.DeactivateState(ctx, "TS", reason)
+		}
+	}
+	reactivate := func(ctx contractapi.TransactionContextInterface) (*StateRegistry, error) {
+		return l.contract.ReactivateState(ctx, "TS")
+	}
+
+	if _, _, err := setStatus(telangana, suspend("Audit pending")); err == nil || !strings.Contains(err.Error(), "only CCLB admins") {
+		t.Fatalf("state registrar suspending: err = %v, want a denial", err)
+	}
+	if _, _, err := setStatus(l.admin, suspend(" ")); err == nil || !strings.Contains(err.Error(), "reason is required") {
+		t.Fatalf("suspending without a reason: err = %v, want it refused", err)
+	}
+	if _, _, err := setStatus(l.admin, reactivate); err == nil || !strings.Contains(err.Error(), "already ACTIVE") {
+		t.Fatalf("reactivating an active state: err = %v, want it refused", err)
+	}
+
+	suspended, events, err := setStatus(l.admin, suspend("Audit pending"))
+	if err != nil || suspended.Status != StateStatusSuspended || suspended.StatusReason != "Audit pending" {
+		t.Fatalf("SuspendState = %+v (%v), want TS suspended for the audit", suspended, err)
+	}
+	var changed StateStatusChangedEvent
+	if err := json.Unmarshal(events["StateStatusChanged"], &changed); err != nil ||
+		changed.PreviousStatus != StateStatusActive || changed.Status != StateStatusSuspended {
+		t.Fatalf("StateStatusChanged event %s (%v), want ACTIVE to SUSPENDED", events["StateStatusChanged"], err)
+	}
+	if _, err := l.issue(telangana, "TS", "101"); err == nil || !strings.Contains(err.Error(), "cannot be issued Property IDs") {
+		t.Fatalf("issuing while suspended: err = %v, want it refused", err)
+	}
+	if _, _, err := setStatus(l.admin, suspend("Audit pending")); err == nil || !strings.Contains(err.Error(), "already SUSPENDED") {
+		t.Fatalf("suspending twice: err = %v, want it refused", err)
+	}
+
+	reactivated, _, err := setStatus(l.admin, reactivate)
+	if err != nil || reactivated.Status != StateStatusActive || reactivated.StatusReason != "" {
+		t.Fatalf("ReactivateState = %+v (%v), want TS active again", reactivated, err)
+	}
+	if issued, err := l.issue(telangana, "TS", "101"); err != nil || issued.ID != "CCLB-2025-TS-000001" {
+		t.Fatalf("issuing after reactivation = %v (%v), want CCLB-2025-TS-000001", issued, err)
+	}
+
+	if _, _, err := setStatus(l.admin, deactivate("Merged into another registration")); err != nil {
+		t.Fatalf("DeactivateState failed: %v", err)
+	}
+	if _, _, err := setStatus(l.admin, reactivate); err == nil || !strings.Contains(err.Error(), "is deactivated") {
+		t.Fatalf("reactivating a deactivated state: err = %v, want it refused", err)
+	}
+	if _, err := l.issue(telangana, "TS", "102"); err == nil || !strings.Contains(err.Error(), "cannot be issued Property IDs") {
+		t.Fatalf("issuing while deactivated: err = %v, want it refused", err)
+	}
+	if stored, err := l.contract.QueryStateRegistry(l.ctx, "TS"); err != nil || stored.Status != StateStatusDeactivated {
+		t.Fatalf("stored registration %+v (%v), want it deactivated", stored, err)
+	}
+}
+
+func TestVerifyStateRecord(t *testing.T) {
+	l := newCCLBLedger(t)
+	l.register("TS", "Telangana")
+	l.register("KA", "Karnataka")
+	issued, err := l.issue(testCreator(t, "StateOrgTSMSP", "registrar"), "TS", "101/A")
+	if err != nil {
+		t.Fatalf("issuing the Property ID failed: %v", err)
+	}
+
+	verify := func(creator []byte, propertyID string, stateCode string, surveyNo string, village string) (*StateRecordVerification, map[string][]byte, error) {
+		result, events, err := l.invoke(creator, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return l.contract.VerifyStateRecord(ctx, propertyID, stateCode, surveyNo, "Rangareddy", "Shamshabad", village, "state-tx-1")
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return result.(*StateRecordVerification), events, nil
+	}
+
+	if _, _, err := verify(testCreator(t, "StateOrgTSMSP", "registrar"), issued.ID, "TS", "101/A", "Kothur"); err == nil ||
+		!strings.Contains(err.Error(), "only CCLB admins") {
+		t.Fatalf("state registrar verifying: err = %v, want a denial", err)
+	}
+	if _, err := l.contract.QueryVerification(l.ctx, issued.ID); err == nil || !strings.Contains(err.Error(), "has not been verified") {
+		t.Fatalf("QueryVerification before verifying: err = %v, want none recorded", err)
+	}
+
+	rejections := []struct {
+		name       string
+		propertyID string
+		stateCode  string
+		surveyNo   string
+		village    string
+		reason     string
+	}{
+		{"never issued", "CCLB-2025-TS-000099", "TS", "101/A", "Kothur", "was not issued by CCLB"},
+		{"other state", issued.ID, "KA", "101/A", "Kothur", "was issued to state TS"},
+		{"other village", issued.ID, "TS", "101/A", "Tondupally", "survey tuple mismatch: village"},
+		{"other survey", issued.ID, "TS", "101/B", "Kothur", "survey tuple mismatch: surveyNo"},
+	}
+	for _, tc := range rejections {
+		verdict, events, err := verify(l.admin, tc.propertyID, tc.stateCode, tc.surveyNo, tc.village)
+		if err != nil {
+			t.Fatalf("%s: a mismatch should be recorded, not fail: %v", tc.name, err)
+		}
+		if verdict.Status != VerificationRejected || !strings.Contains(verdict.Reason, tc.reason) {
+			t.Fatalf("%s: verdict %s %q, want REJECTED for %q", tc.name, verdict.Status, verdict.Reason, tc.reason)
+		}
+		var completed VerificationCompletedEvent
+		if err := json.Unmarshal(events["VerificationCompleted"], &completed); err != nil ||
+			completed.Status != VerificationRejected || completed.Reason != verdict.Reason {
+			t.Fatalf("%s: VerificationCompleted event %s (%v), want the rejection", tc.name, events["VerificationCompleted"], err)
+		}
+	}
+	if stored, err := l.contract.QueryVerification(l.ctx, issued.ID); err != nil || stored.Status != VerificationRejected {
+		t.Fatalf("stored verdict %+v (%v), want the latest rejection", stored, err)
+	}
+
+	// The state corrects its record; whitespace and case differences are not mismatches
+	verdict, events, err := verify(l.admin, issued.ID, "ts", " 101/a ", "KOTHUR")
+	if err != nil || verdict.Status != VerificationVerified || verdict.Reason != "" {
+		t.Fatalf("verifying the corrected record = %+v (%v), want VERIFIED", verdict, err)
+	}
+	var completed VerificationCompletedEvent
+	if err := json.Unmarshal(events["VerificationCompleted"], &completed); err != nil || completed.TxID != verdict.TxID {
+		t.Fatalf("VerificationCompleted event %s (%v), want CCLB tx %s for AcceptCCLBVerification", events["VerificationCompleted"], err, verdict.TxID)
+	}
+	if stored, err := l.contract.QueryVerification(l.ctx, issued.ID); err != nil || stored.Status != VerificationVerified || stored.TxID != verdict.TxID {
+		t.Fatalf("stored verdict %+v (%v), want VERIFIED in %s", stored, err, verdict.TxID)
+	}
+	if _, _, err := verify(l.admin, issued.ID, "TS", "101/A", "Kothur"); err == nil || !strings.Contains(err.Error(), "already verified") {
+		t.Fatalf("verifying twice: err = %v, want it refused", err)
+	}
+}
+
+// testCreator returns a serialized identity for mspID whose certificate
+// carries role as a Fabric CA attribute
+func testCreator(t *testing.T, mspID string, role string) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	attrs, err := json.Marshal(map[string]map[string]string{"attrs": {"role": role}})
+	if err != nil {
+		t.Fatalf("failed to marshal attributes: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: role + "@" + mspID},
+		NotBefore:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC),
+		ExtraExtensions: []pkix.Extension{{
+			Id:    asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}, // Fabric CA attributes
+			Value: attrs,
+		}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal identity: %v", err)
+	}
+	return creator
+}
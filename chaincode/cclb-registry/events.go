@@ -1,6 +1,12 @@
 package main
 
-import "github.com/hyperledger/fabric-contract-api-go/contractapi"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
 
 // PropertyIDIssuedEvent emitted when CCLB issues a new Property ID
 type PropertyIDIssuedEvent struct {
@@ -19,6 +25,15 @@ type StateRegisteredEvent struct {
 	Timestamp string `json:"timestamp"`
 }
 
+// StateStatusChangedEvent emitted when CCLB suspends, deactivates or reactivates a state
+type StateStatusChangedEvent struct {
+	StateCode      string `json:"stateCode"`
+	PreviousStatus string `json:"previousStatus"`
+	Status         string `json:"status"`
+	Reason         string `json:"reason"`
+	Timestamp      string `json:"timestamp"`
+}
+
 // VerificationCompletedEvent emitted when CCLB verifies a state record
 type VerificationCompletedEvent struct {
 	PropertyID string `json:"propertyId"`
@@ -26,6 +41,7 @@ type VerificationCompletedEvent struct {
 	Status     string `json:"status"` // VERIFIED, REJECTED, PENDING
 	Reason     string `json:"reason"`
 	Timestamp  string `json:"timestamp"`
+	TxID       string `json:"txId"` // Passed to AcceptCCLBVerification on the state channel
 }
 
 // emitPropertyIDIssuedEvent emits an event when a Property ID is issued
@@ -35,15 +51,24 @@ func (c *CCLBRegistryContract) emitPropertyIDIssuedEvent(
 	stateCode string,
 	submittedBy string,
 ) error {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	event := PropertyIDIssuedEvent{
 		PropertyID:  propertyID,
 		StateCode:   stateCode,
 		SubmittedBy: submittedBy,
-		Timestamp:   "2026-01-26T00:00:00Z", // TODO: use real timestamp
+		Timestamp:   txTime.Format(time.RFC3339),
 		VerifiedBy:  "CCLB-ADMIN",
 	}
 
-	return ctx.GetStub().SetEvent("PropertyIDIssued", marshalEvent(event))
+	eventJSON, err := marshalEvent(event)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("PropertyIDIssued", eventJSON)
 }
 
 // emitStateRegisteredEvent emits an event when a state is registered
@@ -53,14 +78,51 @@ func (c *CCLBRegistryContract) emitStateRegisteredEvent(
 	stateName string,
 	channelID string,
 ) error {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	event := StateRegisteredEvent{
 		StateCode: stateCode,
 		StateName: stateName,
 		ChannelID: channelID,
-		Timestamp: "2026-01-26T00:00:00Z", // TODO: use real timestamp
+		Timestamp: txTime.Format(time.RFC3339),
 	}
 
-	return ctx.GetStub().SetEvent("StateRegistered", marshalEvent(event))
+	eventJSON, err := marshalEvent(event)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("StateRegistered", eventJSON)
+}
+
+// emitStateStatusChangedEvent emits an event when a state's registration status changes
+func (c *CCLBRegistryContract) emitStateStatusChangedEvent(
+	ctx contractapi.TransactionContextInterface,
+	stateCode string,
+	previousStatus string,
+	status string,
+	reason string,
+) error {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := StateStatusChangedEvent{
+		StateCode:      stateCode,
+		PreviousStatus: previousStatus,
+		Status:         status,
+		Reason:         reason,
+		Timestamp:      txTime.Format(time.RFC3339),
+	}
+
+	eventJSON, err := marshalEvent(event)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("StateStatusChanged", eventJSON)
 }
 
 // emitVerificationCompletedEvent emits verification result
@@ -71,19 +133,32 @@ func (c *CCLBRegistryContract) emitVerificationCompletedEvent(
 	status string,
 	reason string,
 ) error {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	event := VerificationCompletedEvent{
 		PropertyID: propertyID,
 		StateCode:  stateCode,
 		Status:     status,
 		Reason:     reason,
-		Timestamp:  "2026-01-26T00:00:00Z", // TODO: use real timestamp
+		Timestamp:  txTime.Format(time.RFC3339),
+		TxID:       ctx.GetStub().GetTxID(),
 	}
 
-	return ctx.GetStub().SetEvent("VerificationCompleted", marshalEvent(event))
+	eventJSON, err := marshalEvent(event)
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().SetEvent("VerificationCompleted", eventJSON)
 }
 
 // Helper to marshal events to JSON bytes
-func marshalEvent(event interface{}) []byte {
-	// TODO: Implement JSON marshaling
-	return []byte{}
+func marshalEvent(event interface{}) ([]byte, error) {
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T: %v", event, err)
+	}
+	return eventJSON, nil
 }
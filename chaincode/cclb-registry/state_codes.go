@@ -0,0 +1,59 @@
+package main
+
+import "strings"
+
+// normalizeStateCode converts full state name to two-letter code
+// Supports Indian states
+func normalizeStateCode(state string) string {
+	stateMappings := map[string]string{
+		"telangana":         "TS",
+		"andhra pradesh":    "AP",
+		"karnataka":         "KA",
+		"tamil nadu":        "TN",
+		"maharashtra":       "MH",
+		"uttar pradesh":     "UP",
+		"uttarakhand":       "UK",
+		"himachal pradesh":  "HP",
+		"punjab":            "PB",
+		"haryana":           "HR",
+		"delhi":             "DL",
+		"rajasthan":         "RJ",
+		"goa":               "GA",
+		"west bengal":       "WB",
+		"odisha":            "OD",
+		"jharkhand":         "JH",
+		"bihar":             "BR",
+		"madhya pradesh":    "MP",
+		"chhattisgarh":      "CT",
+		"assam":             "AS",
+		"manipur":           "MN",
+		"meghalaya":         "ML",
+		"mizoram":           "MZ",
+		"nagaland":          "NL",
+		"tripura":           "TR",
+		"arunachal pradesh": "AR",
+		"sikkim":            "SK",
+		"kerala":            "KL",
+		"puducherry":        "PY",
+		"ladakh":            "LA",
+		"jammu & kashmir":   "JK",
+		"jammu and kashmir": "JK",
+	}
+
+	normalized := strings.ToLower(strings.TrimSpace(state))
+	if code, exists := stateMappings[normalized]; exists {
+		return code
+	}
+
+	// If already a two-letter code, validate and return
+	if len(state) == 2 {
+		upperCode := strings.ToUpper(state)
+		for _, v := range stateMappings {
+			if v == upperCode {
+				return upperCode
+			}
+		}
+	}
+
+	return ""
+}
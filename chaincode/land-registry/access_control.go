@@ -19,6 +19,31 @@ func requireRole(ctx contractapi.TransactionContextInterface, allowed ...string)
 		}
 	}
 
+	// CCLB registrars may act as a registrar in every state
+	if role == cclbOverrideRole && contains(allowed, "registrar") {
+		if err := requireMSP(ctx, cclbMSPID); err == nil {
+			return nil
+		}
+	}
+
 	return fmt.Errorf("access denied for role: %s", role)
 }
 
+// cclbMSPID is the Central Land Ledger Board org, a member of every state channel
+const cclbMSPID = "CCLEBMSP"
+
+func requireMSP(ctx contractapi.TransactionContextInterface, allowed ...string) error {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read caller MSP ID: %v", err)
+	}
+
+	for _, m := range allowed {
+		if mspID == m {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("access denied for MSP: %s", mspID)
+}
+
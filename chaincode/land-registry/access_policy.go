@@ -0,0 +1,521 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Jurisdiction attributes on caller certificates (issued by the org's Fabric CA)
+const (
+	stateAttribute    = "state"    // State code, e.g. TS; defaults to the caller's StateOrg MSP
+	districtAttribute = "district" // Optional; limits the caller to one district
+	sroAttribute      = "sro"      // Optional sub-registrar office, recorded in audit events
+)
+
+// cclbOverrideRole lets a CCLB registrar act as a registrar in every state
+const cclbOverrideRole = "cclb_registrar"
+
+// jurisdictionRoles are the state officials whose land record writes are
+// limited to their jurisdiction
+var jurisdictionRoles = []string{"registrar", "jt_sub_registrar", "tahsildar", "tax_officer", "legal_officer"}
+
+// stateOrgMSPPattern matches state organization MSP IDs such as StateOrgTSMSP
+var stateOrgMSPPattern = regexp.MustCompile(`^StateOrg([A-Z]{2})MSP$`)
+
+// accessRule is one allowed (MSP, role, jurisdiction) tuple for a transaction
+type accessRule struct {
+	msp    func(mspID string) bool
+	role   string // Required role attribute; "" allows any role
+	scoped bool   // Caller's jurisdiction must contain every location the transaction touches
+}
+
+// accessPolicy lists who may invoke one transaction
+type accessPolicy struct {
+	rules   []accessRule
+	targets targetResolver // Locations touched by the transaction, for scoped rules
+}
+
+// jurisdiction is a state, optionally narrowed to one district
+type jurisdiction struct {
+	StateCode string
+	District  string
+	SRO       string // Caller's office; land records do not carry one
+}
+
+// targetResolver finds the locations a transaction touches from its raw parameters
+type targetResolver func(ctx contractapi.TransactionContextInterface, params []string) ([]jurisdiction, error)
+
+// accessPolicies maps every contract transaction to its access policy
+// Transactions without an entry are denied. Checks inside transactions that
+// depend on the record (seller is the owner, lender MSP, ...) still apply.
+var accessPolicies = map[string]accessPolicy{
+	// Reads
+	"ReadLandRecord":               anyone(),
+	"QueryLandBySurvey":            anyone(),
+	"QueryLandByLocation":          anyone(),
+	"GetAllLandRecords":            anyone(),
+	"GetAllLandRecordsPaginated":   anyone(),
+	"QueryLandByDistrictPaginated": anyone(),
+	"QueryLandByLandTypePaginated": anyone(),
+	"GetTransactionHistory":        anyone(),
+	"GetPropertyLineage":           anyone(),
+	"ReadLineageOperation":         anyone(),
+	"GetParcelConflictReport":      anyone(),
+	"ListDrafts":                   anyone(),
+	"GetPropertyIDCounter":         anyone(),
+	"ReadTransferRequest":          anyone(),
+	"GetConveyanceSummary":         anyone(),
+	"GetActiveTransfer":            anyone(),
+	"ReadMutationCase":             anyone(),
+	"ReadSuccessionCase":           anyone(),
+	"GetEncumbrances":              anyone(),
+	"GetEncumbranceCertificate":    anyone(),
+	"GetLeases":                    anyone(),
+	"GetActiveLeases":              anyone(),
+	"GetCourtOrders":               anyone(),
+	"GetTaxRateTable":              anyone(),
+	"GetTaxAssessments":            anyone(),
+	"GetTaxArrears":                anyone(),
+	"VerifyPrivateDetail":          anyone(),
+
+	// Members of the private collection (the owner indexes live there)
+	"QueryLandByOwner":          allow(role(stateOrgMSP, ""), role(exactMSP(cclbMSPID), "")),
+	"QueryLandByOwnerPaginated": allow(role(stateOrgMSP, ""), role(exactMSP(cclbMSPID), "")),
+	"GetMyProperties":           allow(role(stateOrgMSP, ""), role(exactMSP(cclbMSPID), "")),
+
+	// Deadline-driven transitions and self-registration
+	"ExpireDraft":             anyone(),
+	"ExpireTransfer":          anyone(),
+	"ExpireCourtOrder":        anyone(),
+	"RegisterPerson":          anyone(),
+	"FileMutationObjection":   anyone(),
+	"FileSuccessionObjection": anyone(),
+
+	// Citizens
+	"SubmitLandApplication":  allow(role(anyMSP, "citizen")),
+	"InitiateTransfer":       allow(role(anyMSP, "citizen")),
+	"InitiateConveyance":     allow(role(anyMSP, "citizen")),
+	"AcceptTransfer":         allow(role(anyMSP, "citizen")),
+	"ConsentToShareTransfer": allow(role(anyMSP, "citizen")),
+	"CancelTransfer":         allow(role(anyMSP, "citizen")),
+	"SeverJointTenancy":      allow(role(anyMSP, "citizen")),
+
+	// State registry officials, limited to their jurisdiction
+	"RequestPropertyID":       allow(scoped(stateOrgMSP, "registrar")).on(locationParams(0, 3)),
+	"CreateStateRecord":       allow(scoped(stateOrgMSP, "registrar")).on(draftParam(1)),
+	"CancelDraft":             allow(scoped(stateOrgMSP, "registrar")).on(draftParam(0)),
+	"GeneratePropertyID":      allow(scoped(stateOrgMSP, "registrar")).on(locationParams(0, -1)),
+	"LinkDocumentHash":        allow(scoped(stateOrgMSP, "registrar")).on(propertyParam(0)),
+	"LinkOwnerPerson":         allow(scoped(stateOrgMSP, "registrar")).on(propertyParam(0)),
+	"RegisterLease":           allow(scoped(stateOrgMSP, "registrar")).on(propertyParam(0)),
+	"RenewLease":              allow(scoped(stateOrgMSP, "registrar")).on(propertyParam(0)),
+	"TerminateLease":          allow(scoped(stateOrgMSP, "registrar")).on(propertyParam(0)),
+	"SubdivideProperty":       allow(scoped(stateOrgMSP, "registrar")).on(propertyParam(0)),
+	"AmalgamateProperties":    allow(scoped(stateOrgMSP, "registrar")).on(propertyListParam(0)),
+	"VerifyTransferDocuments": allow(scoped(stateOrgMSP, "jt_sub_registrar")).on(transferParam(0)),
+	"ApproveTransfer":         allow(scoped(stateOrgMSP, "registrar")).on(transferParam(0)),
+	"RejectTransfer": allow(
+		role(anyMSP, "citizen"),
+		scoped(stateOrgMSP, "jt_sub_registrar"),
+		scoped(stateOrgMSP, "registrar"),
+	).on(transferParam(0)),
+	"DecideMutation":    allow(scoped(stateOrgMSP, "tahsildar")).on(mutationParam(0)),
+	"DeclareHeirs":      allow(scoped(stateOrgMSP, "registrar")).on(successionParam(0)),
+	"DecideSuccession":  allow(scoped(stateOrgMSP, "registrar")).on(successionParam(0)),
+	"AssessPropertyTax": allow(scoped(stateOrgMSP, "tax_officer")).on(propertyParam(0)),
+	"RecordCourtOrder":  allow(scoped(stateOrgMSP, "legal_officer")).on(propertyParam(0)),
+	"LiftCourtOrder":    allow(scoped(stateOrgMSP, "legal_officer")).on(propertyParam(0)),
+	"RecordTaxPayment":  allow(scoped(stateOrgMSP, "tax_officer")).on(propertyParam(0)),
+	"ReadLandPrivateDetails": allow(
+		scoped(stateOrgMSP, "registrar"),
+		scoped(stateOrgMSP, "jt_sub_registrar"),
+		scoped(stateOrgMSP, "tahsildar"),
+		scoped(stateOrgMSP, "tax_officer"),
+	).on(propertyParam(0)),
+
+	// State-wide administration (the channel is already per state)
+	"SetTaxRateTable":    allow(role(stateOrgMSP, "tax_officer")),
+	"MintLandToken":      allow(role(stateOrgMSP, "jt_sub_registrar")),
+	"CreateLandRecord":   allow(role(stateOrgMSP, "registrar")),
+	"TransferLandRecord": allow(role(stateOrgMSP, "registrar")),
+
+	// A death devolves and unblocks the deceased's parcels wherever they lie
+	"RegisterDeath": allow(scoped(stateOrgMSP, "registrar")).on(wholeState()),
+
+	// Maintenance jobs rewrite every record on the channel, so a registrar
+	// limited to one district cannot run them
+	"MigrateKeyLayout":      allow(scoped(stateOrgMSP, "registrar")).on(wholeState()),
+	"MigrateRecords":        allow(scoped(stateOrgMSP, "registrar")).on(wholeState()),
+	"MigrateLandQuantities": allow(scoped(stateOrgMSP, "registrar")).on(wholeState()),
+	"RebuildLandIndexes":    allow(scoped(stateOrgMSP, "registrar")).on(wholeState()),
+
+	// Banks (the lender's own MSP is checked against the encumbrance)
+	"RecordEncumbrance":  allow(role(anyMSP, "bank_officer")),
+	"ReleaseEncumbrance": allow(role(anyMSP, "bank_officer")),
+	"ConsentToTransfer":  allow(role(anyMSP, "bank_officer")),
+
+	// CCLB relay
+	"AcceptCCLBVerification": allow(role(exactMSP(cclbMSPID), "")),
+}
+
+// enforceAccessPolicy is the contract's BeforeTransaction hook
+func enforceAccessPolicy(ctx contractapi.TransactionContextInterface) error {
+	name := transactionName(ctx)
+	_, params := ctx.GetStub().GetFunctionAndParameters()
+
+	policy, ok := accessPolicies[name]
+	if !ok {
+		return fmt.Errorf("access denied: no access policy for %s", name)
+	}
+	return policy.authorize(ctx, name, params)
+}
+
+// authorize succeeds if any rule admits the caller
+func (p accessPolicy) authorize(ctx contractapi.TransactionContextInterface, name string, params []string) error {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read caller MSP ID: %v", err)
+	}
+	callerRole, _, err := ctx.GetClientIdentity().GetAttributeValue("role")
+	if err != nil {
+		return fmt.Errorf("failed to read caller role: %v", err)
+	}
+
+	var outside error
+	for _, rule := range p.rules {
+		if rule.scoped && rule.role == "registrar" && jurisdictionOverride(mspID, callerRole) {
+			return nil
+		}
+		if !rule.msp(mspID) || (rule.role != "" && rule.role != callerRole) {
+			continue
+		}
+		if !rule.scoped {
+			return nil
+		}
+		if outside = p.checkJurisdiction(ctx, name, mspID); outside == nil {
+			return nil
+		}
+	}
+
+	if outside != nil {
+		return fmt.Errorf("access denied for %s: %v", name, outside)
+	}
+	return fmt.Errorf("access denied for %s: role %q of MSP %s is not allowed", name, callerRole, mspID)
+}
+
+// checkJurisdiction requires every location the transaction touches to lie
+// within the caller's state and, if the caller has one, district
+func (p accessPolicy) checkJurisdiction(ctx contractapi.TransactionContextInterface, name string, mspID string) error {
+	caller, err := callerJurisdiction(ctx, mspID)
+	if err != nil {
+		return err
+	}
+	_, params := ctx.GetStub().GetFunctionAndParameters()
+	targets, err := p.targets(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		if !caller.contains(target) {
+			auditJurisdictionDenial(ctx, name, "", caller, target)
+			return fmt.Errorf("%s is outside the caller's jurisdiction %s", target, caller)
+		}
+	}
+	return nil
+}
+
+// requireRecordJurisdiction stops a state official from writing a land record
+// outside their jurisdiction, whichever transaction performs the write
+func requireRecordJurisdiction(ctx contractapi.TransactionContextInterface, landRecord *LandRecord) error {
+	mspID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return fmt.Errorf("failed to read caller MSP ID: %v", err)
+	}
+	callerRole, _, err := ctx.GetClientIdentity().GetAttributeValue("role")
+	if err != nil {
+		return fmt.Errorf("failed to read caller role: %v", err)
+	}
+	if !contains(jurisdictionRoles, callerRole) || jurisdictionOverride(mspID, callerRole) {
+		return nil
+	}
+
+	caller, err := callerJurisdiction(ctx, mspID)
+	if err != nil {
+		return fmt.Errorf("access denied: %v", err)
+	}
+	target := jurisdiction{StateCode: landRecord.StateCode, District: landRecord.District}
+	if !caller.contains(target) {
+		auditJurisdictionDenial(ctx, transactionName(ctx), landRecord.PropertyID, caller, target)
+		return fmt.Errorf("access denied: land record %s in %s is outside the caller's jurisdiction %s",
+			landRecord.PropertyID, target, caller)
+	}
+	return nil
+}
+
+// auditJurisdictionDenial logs a cross-jurisdiction attempt
+// Fabric commits nothing from a rejected proposal, events included, so the
+// chaincode log kept by the peer is the only record of a denial.
+func auditJurisdictionDenial(
+	ctx contractapi.TransactionContextInterface,
+	function string,
+	propertyID string,
+	caller jurisdiction,
+	target jurisdiction,
+) {
+	mspID, _ := ctx.GetClientIdentity().GetMSPID()
+	callerRole, _, _ := ctx.GetClientIdentity().GetAttributeValue("role")
+	callerID, _ := ctx.GetClientIdentity().GetID()
+
+	subject := target.String()
+	if propertyID != "" {
+		subject = propertyID + " in " + subject
+	}
+	fmt.Printf("audit: %s denied for %s: caller %s (%s, %s, %s, sro %q), tx %s\n",
+		function, subject, callerID, mspID, callerRole, caller, caller.SRO, ctx.GetStub().GetTxID())
+}
+
+// callerJurisdiction reads the caller's state and district attributes
+func callerJurisdiction(ctx contractapi.TransactionContextInterface, mspID string) (jurisdiction, error) {
+	state, _, err := ctx.GetClientIdentity().GetAttributeValue(stateAttribute)
+	if err != nil {
+		return jurisdiction{}, fmt.Errorf("failed to read caller state: %v", err)
+	}
+	if state == "" {
+		state = stateCodeFromMSP(mspID)
+	}
+	if state == "" {
+		return jurisdiction{}, fmt.Errorf("caller has no %s attribute", stateAttribute)
+	}
+
+	district, _, err := ctx.GetClientIdentity().GetAttributeValue(districtAttribute)
+	if err != nil {
+		return jurisdiction{}, fmt.Errorf("failed to read caller district: %v", err)
+	}
+	sro, _, err := ctx.GetClientIdentity().GetAttributeValue(sroAttribute)
+	if err != nil {
+		return jurisdiction{}, fmt.Errorf("failed to read caller SRO: %v", err)
+	}
+	return jurisdiction{StateCode: strings.ToUpper(state), District: district, SRO: sro}, nil
+}
+
+// jurisdictionOverride reports whether the caller is a CCLB registrar
+func jurisdictionOverride(mspID string, role string) bool {
+	return mspID == cclbMSPID && role == cclbOverrideRole
+}
+
+// transactionName returns the invoked transaction without its contract prefix
+func transactionName(ctx contractapi.TransactionContextInterface) string {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	return function[strings.LastIndex(function, ":")+1:]
+}
+
+// contains reports whether a target location lies inside this jurisdiction
+// A state-wide target (no district) is only inside a state-wide jurisdiction.
+func (j jurisdiction) contains(target jurisdiction) bool {
+	if !strings.EqualFold(j.StateCode, target.StateCode) {
+		return false
+	}
+	return j.District == "" || normalizeLookup(j.District) == normalizeLookup(target.District)
+}
+
+func (j jurisdiction) String() string {
+	if j.District == "" {
+		return j.StateCode
+	}
+	return j.StateCode + "/" + j.District
+}
+
+// Policy table helpers
+
+func allow(rules ...accessRule) accessPolicy {
+	return accessPolicy{rules: rules}
+}
+
+func anyone() accessPolicy {
+	return allow(role(anyMSP, ""))
+}
+
+// on sets how a policy with scoped rules finds the locations a transaction touches
+func (p accessPolicy) on(targets targetResolver) accessPolicy {
+	p.targets = targets
+	return p
+}
+
+func role(msp func(string) bool, role string) accessRule {
+	return accessRule{msp: msp, role: role}
+}
+
+func scoped(msp func(string) bool, role string) accessRule {
+	return accessRule{msp: msp, role: role, scoped: true}
+}
+
+func anyMSP(string) bool {
+	return true
+}
+
+func stateOrgMSP(mspID string) bool {
+	return stateCodeFromMSP(mspID) != ""
+}
+
+func exactMSP(allowed string) func(string) bool {
+	return func(mspID string) bool { return mspID == allowed }
+}
+
+// stateCodeFromMSP returns TS for StateOrgTSMSP, or "" for other MSPs
+func stateCodeFromMSP(mspID string) string {
+	match := stateOrgMSPPattern.FindStringSubmatch(mspID)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// Target resolvers
+
+// locationParams reads a state (and optional district, -1 for none) from parameters
+func locationParams(stateIndex int, districtIndex int) targetResolver {
+	return func(ctx contractapi.TransactionContextInterface, params []string) ([]jurisdiction, error) {
+		state, err := policyParam(params, stateIndex)
+		if err != nil {
+			return nil, err
+		}
+		target := jurisdiction{StateCode: normalizeStateCode(state)}
+		if target.StateCode == "" {
+			target.StateCode = strings.ToUpper(strings.TrimSpace(state))
+		}
+		if districtIndex >= 0 {
+			if target.District, err = policyParam(params, districtIndex); err != nil {
+				return nil, err
+			}
+		}
+		return []jurisdiction{target}, nil
+	}
+}
+
+// wholeState targets the entire state of the caller's org, for transactions that touch every record
+func wholeState() targetResolver {
+	return func(ctx contractapi.TransactionContextInterface, params []string) ([]jurisdiction, error) {
+		mspID, err := ctx.GetClientIdentity().GetMSPID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read caller MSP ID: %v", err)
+		}
+		return []jurisdiction{{StateCode: stateCodeFromMSP(mspID)}}, nil
+	}
+}
+
+// propertyParam resolves the land record named by a Property ID parameter
+func propertyParam(index int) targetResolver {
+	return func(ctx contractapi.TransactionContextInterface, params []string) ([]jurisdiction, error) {
+		propertyID, err := policyParam(params, index)
+		if err != nil {
+			return nil, err
+		}
+		return propertyLocations(ctx, propertyID)
+	}
+}
+
+// propertyListParam resolves land records named by a JSON array parameter
+func propertyListParam(index int) targetResolver {
+	return func(ctx contractapi.TransactionContextInterface, params []string) ([]jurisdiction, error) {
+		raw, err := policyParam(params, index)
+		if err != nil {
+			return nil, err
+		}
+		var propertyIDs []string
+		if err := json.Unmarshal([]byte(raw), &propertyIDs); err != nil {
+			return nil, fmt.Errorf("invalid Property ID list: %v", err)
+		}
+		return propertyLocations(ctx, propertyIDs...)
+	}
+}
+
+// transferParam resolves the properties of a transfer request
+func transferParam(index int) targetResolver {
+	return func(ctx contractapi.TransactionContextInterface, params []string) ([]jurisdiction, error) {
+		transferID, err := policyParam(params, index)
+		if err != nil {
+			return nil, err
+		}
+		transfer, err := getTransferRequest(ctx, transferID)
+		if err != nil {
+			return nil, err
+		}
+		return propertyLocations(ctx, transfer.lockedProperties()...)
+	}
+}
+
+// mutationParam resolves the property of a mutation case
+func mutationParam(index int) targetResolver {
+	return func(ctx contractapi.TransactionContextInterface, params []string) ([]jurisdiction, error) {
+		caseID, err := policyParam(params, index)
+		if err != nil {
+			return nil, err
+		}
+		mutation, err := getMutationCase(ctx, caseID)
+		if err != nil {
+			return nil, err
+		}
+		return propertyLocations(ctx, mutation.PropertyID)
+	}
+}
+
+// successionParam resolves the parcels of a succession case
+func successionParam(index int) targetResolver {
+	return func(ctx contractapi.TransactionContextInterface, params []string) ([]jurisdiction, error) {
+		caseID, err := policyParam(params, index)
+		if err != nil {
+			return nil, err
+		}
+		succession, err := getSuccessionCase(ctx, caseID)
+		if err != nil {
+			return nil, err
+		}
+		return propertyLocations(ctx, succession.PropertyIDs...)
+	}
+}
+
+// draftParam resolves the location of a Property ID request draft
+func draftParam(index int) targetResolver {
+	return func(ctx contractapi.TransactionContextInterface, params []string) ([]jurisdiction, error) {
+		requestID, err := policyParam(params, index)
+		if err != nil {
+			return nil, err
+		}
+		draft, err := getDraft(ctx, requestID)
+		if err != nil {
+			return nil, err
+		}
+		return []jurisdiction{{StateCode: draft.StateCode, District: draft.District}}, nil
+	}
+}
+
+func propertyLocations(ctx contractapi.TransactionContextInterface, propertyIDs ...string) ([]jurisdiction, error) {
+	locations := make([]jurisdiction, 0, len(propertyIDs))
+	for _, propertyID := range propertyIDs {
+		landRecordJSON, err := getLandRecordJSON(ctx, propertyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read land record %s: %v", propertyID, err)
+		}
+		if landRecordJSON == nil {
+			return nil, fmt.Errorf("land record %s does not exist", propertyID)
+		}
+		var landRecord LandRecord
+		if err := decodeDocument(DocTypeLandRecord, landRecordJSON, &landRecord); err != nil {
+			return nil, fmt.Errorf("failed to parse land record %s: %v", propertyID, err)
+		}
+		locations = append(locations, jurisdiction{StateCode: landRecord.StateCode, District: landRecord.District})
+	}
+	return locations, nil
+}
+
+func policyParam(params []string, index int) (string, error) {
+	if index >= len(params) {
+		return "", fmt.Errorf("missing parameter %d", index+1)
+	}
+	return params[index], nil
+}
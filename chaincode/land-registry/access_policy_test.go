@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestEveryTransactionHasAccessPolicy(t *testing.T) {
+	inherited := map[string]bool{}
+	base := reflect.TypeOf(new(contractapi.Contract))
+	for i := 0; i < base.NumMethod(); i++ {
+		inherited[base.Method(i).Name] = true
+	}
+
+	contract := reflect.TypeOf(new(LandRegistryContract))
+	for i := 0; i < contract.NumMethod(); i++ {
+		name := contract.Method(i).Name
+		if inherited[name] {
+			continue
+		}
+		if _, ok := accessPolicies[name]; !ok {
+			t.Errorf("%s has no access policy", name)
+		}
+	}
+}
+
+// invokeAs calls a transaction through the chaincode so the BeforeTransaction hook runs
+func invokeAs(stub *shimtest.MockStub, txID string, creator []byte, function string, args ...string) (string, error) {
+	stub.Creator = creator
+	invokeArgs := [][]byte{[]byte(function)}
+	for _, arg := range args {
+		invokeArgs = append(invokeArgs, []byte(arg))
+	}
+
+	response := stub.MockInvoke(txID, invokeArgs)
+	if response.Status != 200 {
+		return "", errors.New(response.Message)
+	}
+	return string(response.Payload), nil
+}
+
+// accessDenied reports whether the BeforeTransaction hook refused an invocation
+func accessDenied(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "access denied")
+}
+
+func outsideJurisdiction(err error) bool {
+	return accessDenied(err) && strings.Contains(err.Error(), "outside the caller's jurisdiction")
+}
+
+func TestAccessPolicyEnforcesJurisdiction(t *testing.T) {
+	contract := new(LandRegistryContract)
+	contract.BeforeTransaction = enforceAccessPolicy
+	chaincode, err := contractapi.NewChaincode(contract)
+	if err != nil {
+		t.Fatalf("failed to create chaincode: %v", err)
+	}
+	stub := shimtest.NewMockStub("land-registry", chaincode)
+
+	tsRegistrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	kaRegistrar := testCreator(t, "StateOrgKAMSP", "registrar")
+	warangalRegistrar := testCreatorWithAttrs(t, "StateOrgTSMSP",
+		map[string]string{"role": "registrar", "district": "Warangal"})
+	citizen := testCreator(t, "StateOrgTSMSP", "citizen")
+
+	request := func(creator []byte, txID string, state string, district string, surveyNo string) (string, error) {
+		return invokeAs(stub, txID, creator, "RequestPropertyID", state, "Ravi Kumar", surveyNo, district,
+			"Shamshabad", "Kothur", "2 acres", "agricultural", "45 L", "")
+	}
+
+	if _, err := request(tsRegistrar, "tx-ka", "Karnataka", "Bengaluru Urban", "12"); !outsideJurisdiction(err) {
+		t.Fatalf("TS registrar requesting in Karnataka: err = %v, want a jurisdiction denial", err)
+	}
+	if _, err := request(citizen, "tx-citizen", "TS", "Rangareddy", "12"); !accessDenied(err) {
+		t.Fatalf("citizen requesting a Property ID: err = %v, want access denied", err)
+	}
+	if _, err := request(warangalRegistrar, "tx-district", "TS", "Rangareddy", "12"); !outsideJurisdiction(err) {
+		t.Fatal("Warangal registrar requested a Property ID in Rangareddy")
+	}
+
+	requestID, err := request(tsRegistrar, "tx-ts", "TS", "Rangareddy", "12")
+	if err != nil {
+		t.Fatalf("TS registrar requesting in Telangana: %v", err)
+	}
+
+	// Records are scoped by where they are, not by what the caller passes in
+	if _, err := invokeAs(stub, "tx-bind-ka", kaRegistrar, "CreateStateRecord",
+		"CCLB-2025-TS-000001", requestID, ""); !outsideJurisdiction(err) {
+		t.Fatal("KA registrar bound a Telangana draft")
+	}
+	if _, err := invokeAs(stub, "tx-bind-ts", tsRegistrar, "CreateStateRecord",
+		"CCLB-2025-TS-000001", requestID, ""); accessDenied(err) {
+		t.Fatalf("TS registrar binding a Telangana draft: %v", err)
+	}
+	if _, err := invokeAs(stub, "tx-link-ka", kaRegistrar, "LinkDocumentHash", "CCLB-2025-TS-000001",
+		"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", "SALE_DEED"); !outsideJurisdiction(err) {
+		t.Fatal("KA registrar modified a Telangana record")
+	}
+	if _, err := invokeAs(stub, "tx-link-cclb", testCreator(t, cclbMSPID, cclbOverrideRole), "LinkDocumentHash",
+		"CCLB-2025-TS-000001", "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", "SALE_DEED"); err != nil {
+		t.Fatalf("CCLB registrar linking a document: %v", err)
+	}
+
+	if _, err := invokeAs(stub, "tx-read", citizen, "ReadLandRecord", "CCLB-2025-TS-000001"); accessDenied(err) {
+		t.Fatalf("citizen reading a land record: %v", err)
+	}
+
+	// Maintenance jobs sweep the whole channel and need a state-wide caller
+	if _, err := invokeAs(stub, "tx-rebuild-district", warangalRegistrar, "RebuildLandIndexes"); !outsideJurisdiction(err) {
+		t.Fatalf("Warangal registrar rebuilding indexes: err = %v, want a jurisdiction denial", err)
+	}
+	for name, creator := range map[string][]byte{"TS": tsRegistrar, "CCLB": testCreator(t, cclbMSPID, cclbOverrideRole)} {
+		if _, err := invokeAs(stub, "tx-rebuild-"+name, creator, "RebuildLandIndexes"); err != nil {
+			t.Fatalf("%s registrar rebuilding indexes: %v", name, err)
+		}
+	}
+
+	// A death cancels the deceased's transfers in every district
+	if _, err := invokeAs(stub, "tx-death-district", warangalRegistrar, "RegisterDeath",
+		"PERSON_1", "2025-02-20", "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"); !outsideJurisdiction(err) {
+		t.Fatalf("Warangal registrar registering a death: err = %v, want a jurisdiction denial", err)
+	}
+	if _, err := invokeAs(stub, "tx-death-ts", tsRegistrar, "RegisterDeath",
+		"PERSON_1", "2025-02-20", "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"); accessDenied(err) {
+		t.Fatalf("TS registrar registering a death: %v", err)
+	}
+}
+
+func TestJurisdictionIsEnforcedOnLandRecordWrites(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peer := newEndorser("peer0")
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	warangalRegistrar := testCreatorWithAttrs(t, "StateOrgTSMSP",
+		map[string]string{"role": "registrar", "district": "Warangal", "sro": "Hanamkonda"})
+	cclbRegistrar := testCreator(t, cclbMSPID, cclbOverrideRole)
+	txTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	requestID := peer.endorse(t, proposal{
+		txID:    "tx-request",
+		creator: registrar,
+		time:    txTime,
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.RequestPropertyID(ctx, "TS", "Ravi Kumar", "101/A", "Rangareddy",
+				"Shamshabad", "Kothur", "2 acres", "agricultural", "45 L", "")
+		},
+	}).result.(string)
+
+	bind := func(txID string, creator []byte) (endorsement, error) {
+		return peer.submit(t, proposal{
+			txID:    txID,
+			creator: creator,
+			time:    txTime.Add(time.Hour),
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.CreateStateRecord(ctx, "CCLB-2025-TS-000001", requestID, "")
+			},
+		})
+	}
+
+	// Called directly, so only the write guard in putLandRecord stands in the way.
+	// A denied proposal commits nothing, so the chaincode log is its audit trail.
+	audit := captureStdout(t, func() {
+		if _, err := bind("tx-bind-warangal", warangalRegistrar); !outsideJurisdiction(err) {
+			t.Fatalf("Warangal registrar binding a Rangareddy draft: err = %v, want a jurisdiction denial", err)
+		}
+	})
+	if !strings.Contains(audit, "denied for CCLB-2025-TS-000001 in TS/Rangareddy") ||
+		!strings.Contains(audit, `TS/Warangal, sro "Hanamkonda"), tx tx-bind-warangal`) {
+		t.Fatalf("audit log = %q, want the record, the caller's district and SRO", audit)
+	}
+
+	if _, err := bind("tx-bind-cclb", cclbRegistrar); err != nil {
+		t.Fatalf("CCLB registrar binding a Telangana draft: %v", err)
+	}
+}
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	writer.Close()
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	return string(output)
+}
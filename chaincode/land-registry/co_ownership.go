@@ -0,0 +1,285 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Tenancy types for LandRecord.Tenancy
+// In a joint tenancy every co-owner holds an equal, undivided share, so one
+// co-owner's share moves only with the consent of all the others. With two
+// joint tenants the transfer severs the joint tenancy, leaving the other tenant
+// and the grantee as tenants in common. With three or more, the rest would stay
+// joint among themselves beside a grantee in common, which one tenancy per
+// record cannot hold, so a tenant must first sever with SeverJointTenancy.
+// Tenants in common hold distinct shares that each may transfer on their own.
+const (
+	TenancySole     = "SOLE"
+	TenancyJoint    = "JOINT_TENANCY"
+	TenancyInCommon = "TENANCY_IN_COMMON"
+)
+
+// CoOwner is one holder of a land record and the fraction of it they hold
+type CoOwner struct {
+	Name     string `json:"name,omitempty"`     // Private; see withoutNames
+	PersonID string `json:"personId,omitempty"` // PERSON_ ID, once linked
+	Share    string `json:"share"`              // Exact fraction, e.g. "1/3"; all shares sum to 1
+}
+
+// normalizeOwners validates co-owners under a tenancy and returns them with canonical shares
+// An empty tenancy means sole ownership for one owner and tenancy in common otherwise.
+func normalizeOwners(owners []CoOwner, tenancy string) ([]CoOwner, string, error) {
+	if len(owners) == 0 {
+		return nil, "", fmt.Errorf("at least one owner is required")
+	}
+
+	tenancy = strings.ToUpper(strings.TrimSpace(tenancy))
+	if tenancy == "" {
+		tenancy = TenancyInCommon
+		if len(owners) == 1 {
+			tenancy = TenancySole
+		}
+	}
+	switch tenancy {
+	case TenancySole:
+		if len(owners) != 1 {
+			return nil, "", fmt.Errorf("%s ownership must have exactly one owner", TenancySole)
+		}
+	case TenancyJoint, TenancyInCommon:
+		if len(owners) < 2 {
+			return nil, "", fmt.Errorf("%s needs at least two owners", tenancy)
+		}
+	default:
+		return nil, "", fmt.Errorf("invalid tenancy %q (must be %s, %s or %s)", tenancy, TenancySole, TenancyJoint, TenancyInCommon)
+	}
+
+	normalized := make([]CoOwner, 0, len(owners))
+	personIDs := map[string]bool{}
+	total := new(big.Rat)
+	for _, owner := range owners {
+		owner.Name = strings.TrimSpace(owner.Name)
+		if owner.Name == "" {
+			return nil, "", fmt.Errorf("every owner needs a name")
+		}
+		if owner.PersonID != "" {
+			if personIDs[owner.PersonID] {
+				return nil, "", fmt.Errorf("person %s is listed as owner more than once", owner.PersonID)
+			}
+			personIDs[owner.PersonID] = true
+		}
+
+		share, err := parseShare(owner.Share)
+		if err != nil {
+			return nil, "", fmt.Errorf("share of %s: %v", owner.Name, err)
+		}
+		if tenancy == TenancyJoint && len(normalized) > 0 && share.Cmp(mustParseShare(normalized[0].Share)) != 0 {
+			return nil, "", fmt.Errorf("joint tenants must hold equal shares")
+		}
+		total.Add(total, share)
+		owner.Share = share.String()
+		normalized = append(normalized, owner)
+	}
+	if total.Cmp(big.NewRat(1, 1)) != 0 {
+		return nil, "", fmt.Errorf("owner shares add up to %s, not 1", total.String())
+	}
+
+	return normalized, tenancy, nil
+}
+
+// parseShare parses a fraction ("1/3") or whole number into a share of a parcel
+func parseShare(share string) (*big.Rat, error) {
+	parsed, ok := new(big.Rat).SetString(strings.TrimSpace(share))
+	if !ok {
+		return nil, fmt.Errorf("invalid share %q (use a fraction such as 1/3)", share)
+	}
+	if parsed.Sign() <= 0 || parsed.Cmp(big.NewRat(1, 1)) > 0 {
+		return nil, fmt.Errorf("share %s must be greater than 0 and at most 1", share)
+	}
+	return parsed, nil
+}
+
+// mustParseShare parses a share already canonicalized by normalizeOwners
+func mustParseShare(share string) *big.Rat {
+	parsed, ok := new(big.Rat).SetString(share)
+	if !ok {
+		return new(big.Rat)
+	}
+	return parsed
+}
+
+// soleOwner is the co-owner list of a record held by one owner
+func soleOwner(name string, personID string) []CoOwner {
+	return []CoOwner{{Name: strings.TrimSpace(name), PersonID: personID, Share: big.NewRat(1, 1).String()}}
+}
+
+// ownerSummary joins co-owner names into the single Owner field older clients read
+func ownerSummary(owners []CoOwner) string {
+	names := make([]string, len(owners))
+	for i, owner := range owners {
+		names[i] = owner.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// findCoOwner returns the index of a person's entry among co-owners, or -1
+// Entries not yet linked to a person fall back to matching the registered name.
+func findCoOwner(owners []CoOwner, person *Person) int {
+	for i, owner := range owners {
+		if owner.PersonID != "" && owner.PersonID == person.PersonID {
+			return i
+		}
+	}
+	for i, owner := range owners {
+		if owner.PersonID == "" && strings.EqualFold(owner.Name, strings.TrimSpace(person.Name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// withoutNames returns co-owners as a public document lists them, by PERSON_ ID and share
+// Their names stay in landPrivateCollection.
+func withoutNames(owners []CoOwner) []CoOwner {
+	public := make([]CoOwner, len(owners))
+	for i, owner := range owners {
+		public[i] = CoOwner{PersonID: owner.PersonID, Share: owner.Share}
+	}
+	return public
+}
+
+// transferShare moves one co-owner's whole share to a buyer
+// A buyer who already co-owns the parcel has the shares added together. A
+// transfer out of a joint tenancy, which requireShareConveyable limits to two
+// tenants, severs it, leaving tenants in common.
+func transferShare(landRecord *LandRecord, seller int, buyerName string, buyerID string) {
+	devolveShare(landRecord, seller, []CoOwner{{Name: buyerName, PersonID: buyerID, Share: "1/1"}})
+}
+
+// requireShareConveyable refuses to convey one share out of a joint tenancy of three or more
+func requireShareConveyable(landRecord *LandRecord) error {
+	if landRecord.Tenancy == TenancyJoint && len(landRecord.Owners) > 2 {
+		return fmt.Errorf("%s is held by %d joint tenants; a tenant must sever the joint tenancy before one share is conveyed",
+			landRecord.PropertyID, len(landRecord.Owners))
+	}
+	return nil
+}
+
+// devolveShare splits one co-owner's whole share among successors
+// Each successor's Share is their fraction of that co-owner's share. Successors
+// who already co-own the parcel have the shares added together.
+func devolveShare(landRecord *LandRecord, from int, successors []CoOwner) {
+	share := mustParseShare(landRecord.Owners[from].Share)
+	owners := append([]CoOwner{}, landRecord.Owners[:from]...)
+	owners = append(owners, landRecord.Owners[from+1:]...)
+
+	for _, successor := range successors {
+		received := new(big.Rat).Mul(share, mustParseShare(successor.Share))
+		merged := false
+		for i := range owners {
+			if owners[i].PersonID != "" && owners[i].PersonID == successor.PersonID {
+				owners[i].Share = new(big.Rat).Add(mustParseShare(owners[i].Share), received).String()
+				merged = true
+			}
+		}
+		if !merged {
+			owners = append(owners, CoOwner{Name: successor.Name, PersonID: successor.PersonID, Share: received.String()})
+		}
+	}
+
+	landRecord.Owners = owners
+	landRecord.Tenancy = TenancyInCommon
+	if len(owners) == 1 {
+		landRecord.Tenancy = TenancySole
+	}
+}
+
+// survivorship passes a deceased joint tenant's share to the surviving joint tenants
+// They keep equal shares and remain joint tenants while two or more survive.
+func survivorship(landRecord *LandRecord, deceased int) {
+	owners := append([]CoOwner{}, landRecord.Owners[:deceased]...)
+	owners = append(owners, landRecord.Owners[deceased+1:]...)
+	for i := range owners {
+		owners[i].Share = big.NewRat(1, int64(len(owners))).String()
+	}
+
+	landRecord.Owners = owners
+	if len(owners) == 1 {
+		landRecord.Tenancy = TenancySole
+	}
+}
+
+// sameOwners reports whether two records are held by the same people in the same shares
+func sameOwners(a []CoOwner, b []CoOwner) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	shares := map[string]string{}
+	for _, owner := range a {
+		shares[normalizeLookup(owner.Name)] = owner.Share
+	}
+	for _, owner := range b {
+		if share, ok := shares[normalizeLookup(owner.Name)]; !ok || share != owner.Share {
+			return false
+		}
+	}
+	return true
+}
+
+// SeverJointTenancy turns a joint tenancy into a tenancy in common at one joint tenant's request
+// Severance needs no one else's consent. The tenants keep their equal shares
+// but lose survivorship, and each may then convey their share alone. Refused
+// while the parcel has an open transfer or is frozen by a court order.
+func (c *LandRegistryContract) SeverJointTenancy(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) error {
+	if err := requireRole(ctx, "citizen"); err != nil {
+		return fmt.Errorf("only citizens can sever a joint tenancy: %v", err)
+	}
+
+	landRecord, err := c.readLandRecordWithDetails(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if landRecord.Status == RecordRetired {
+		return fmt.Errorf("land record %s is retired", propertyID)
+	}
+	if landRecord.Tenancy != TenancyJoint {
+		return fmt.Errorf("%s is not held in joint tenancy", propertyID)
+	}
+	callerID, err := callerPersonID(ctx)
+	if err != nil {
+		return err
+	}
+	if findCoOwner(landRecord.Owners, &Person{PersonID: callerID}) < 0 {
+		return fmt.Errorf("caller is not a joint tenant of %s", propertyID)
+	}
+	active, err := getActiveTransferID(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if active != "" {
+		return fmt.Errorf("property %s has an open transfer %s", propertyID, active)
+	}
+	if err := requireNotFrozen(ctx, propertyID); err != nil {
+		return err
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	landRecord.Tenancy = TenancyInCommon
+	landRecord.LastUpdated = txTime.Format("2006-01-02")
+	if err := putLandRecord(ctx, landRecord); err != nil {
+		return err
+	}
+
+	if err := c.emitPropertyUpdatedEvent(ctx, propertyID, map[string]string{"tenancy": TenancyInCommon}, callerID); err != nil {
+		fmt.Printf("warning: failed to emit PropertyUpdatedEvent: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestNormalizeOwnersRequiresExactShares(t *testing.T) {
+	owners, tenancy, err := normalizeOwners([]CoOwner{
+		{Name: "Ravi Kumar", Share: "1/3"},
+		{Name: "Sita Devi", Share: "2/6"},
+		{Name: "Lakshmi", Share: "1/3"},
+	}, "")
+	if err != nil {
+		t.Fatalf("thirds were rejected: %v", err)
+	}
+	if tenancy != TenancyInCommon || owners[1].Share != "1/3" {
+		t.Fatalf("tenancy = %s, shares = %+v, want tenancy in common in canonical thirds", tenancy, owners)
+	}
+
+	for _, tc := range []struct {
+		owners  []CoOwner
+		tenancy string
+		want    string
+	}{
+		{[]CoOwner{{Name: "A", Share: "1/3"}, {Name: "B", Share: "0.66"}}, "", "add up to"},
+		{[]CoOwner{{Name: "A", Share: "1/4"}, {Name: "B", Share: "3/4"}}, TenancyJoint, "equal shares"},
+		{[]CoOwner{{Name: "A", Share: "1"}}, TenancyJoint, "at least two owners"},
+		{[]CoOwner{{Name: "A", Share: "-1/2"}, {Name: "B", Share: "3/2"}}, "", "greater than 0"},
+	} {
+		if _, _, err := normalizeOwners(tc.owners, tc.tenancy); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("normalizeOwners(%+v, %q) error = %v, want one mentioning %q", tc.owners, tc.tenancy, err, tc.want)
+		}
+	}
+}
+
+func TestJointTenantShareTransferNeedsConsent(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, sitaID, arjunID := s.personIDs["ravi"], s.personIDs["sita"], s.personIDs["arjun"]
+	s.recordWithTenancy("CCLB-2025-TS-000001", "101/A", TenancyJoint,
+		CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1/2"},
+		CoOwner{Name: "Sita Devi", PersonID: sitaID, Share: "1/2"})
+
+	transferID := s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "25 L", testDeedHash)
+	}).(*TransferRequest).TransferID
+	s.must("tx-accept", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.AcceptTransfer(ctx, transferID)
+	})
+	s.must("tx-verify", s.subRegistrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.VerifyTransferDocuments(ctx, transferID, "1.5 L", "SD-2025-0001")
+	})
+
+	approve := func(txID string) error {
+		_, err := s.step(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.ApproveTransfer(ctx, transferID)
+		})
+		return err
+	}
+	if err := approve("tx-approve-early"); err == nil || !strings.Contains(err.Error(), sitaID) {
+		t.Fatalf("approval without consent: err = %v, want one naming the other joint tenant", err)
+	}
+	if _, err := s.step("tx-consent-arjun", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.ConsentToShareTransfer(ctx, transferID)
+	}); err == nil {
+		t.Fatal("the buyer consented as a joint tenant")
+	}
+	s.must("tx-consent", s.citizens["sita"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.ConsentToShareTransfer(ctx, transferID)
+	})
+	if err := approve("tx-approve"); err != nil {
+		t.Fatalf("approval with consent failed: %v", err)
+	}
+
+	landRecord := s.owners("CCLB-2025-TS-000001")
+	if landRecord.Tenancy != TenancyInCommon || len(landRecord.Owners) != 2 ||
+		landRecord.Owners[0].PersonID != sitaID || landRecord.Owners[1].PersonID != arjunID ||
+		landRecord.Owners[1].Share != "1/2" {
+		t.Fatalf("after the sale: tenancy %s, owners %+v; want Sita and Arjun as tenants in common", landRecord.Tenancy, landRecord.Owners)
+	}
+}
+
+func TestJointTenancyOfThreeIsSeveredBeforeAShareIsSold(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, sitaID, arjunID := s.personIDs["ravi"], s.personIDs["sita"], s.personIDs["arjun"]
+	s.citizens["meena"] = testCreatorWithAttrs(t, "StateOrgTSMSP", map[string]string{"role": "citizen", "hf.EnrollmentID": "meena"})
+	meenaID := s.must("tx-register-meena", s.citizens["meena"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RegisterPerson(ctx, "Meena Reddy")
+	}).(*Person).PersonID
+	s.recordWithTenancy("CCLB-2025-TS-000001", "101/A", TenancyJoint,
+		CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1/3"},
+		CoOwner{Name: "Sita Devi", PersonID: sitaID, Share: "1/3"},
+		CoOwner{Name: "Arjun Rao", PersonID: arjunID, Share: "1/3"})
+
+	initiate := func(txID string) (endorsement, error) {
+		return s.step(txID, s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", meenaID, "15 L", testDeedHash)
+		})
+	}
+	sever := func(txID string, citizen string) error {
+		_, err := s.step(txID, s.citizens[citizen], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return nil, s.contract.SeverJointTenancy(ctx, "CCLB-2025-TS-000001")
+		})
+		return err
+	}
+
+	// Sita and Arjun would stay joint tenants beside Meena in common
+	if _, err := initiate("tx-initiate-joint"); err == nil || !strings.Contains(err.Error(), "sever the joint tenancy") {
+		t.Fatalf("selling out of a joint tenancy of three: err = %v, want severance required", err)
+	}
+	if err := sever("tx-sever-outsider", "meena"); err == nil || !strings.Contains(err.Error(), "not a joint tenant") {
+		t.Fatalf("an outsider severing: err = %v, want it refused", err)
+	}
+	if err := sever("tx-sever", "sita"); err != nil {
+		t.Fatalf("severing failed: %v", err)
+	}
+	if landRecord := s.owners("CCLB-2025-TS-000001"); landRecord.Tenancy != TenancyInCommon || landRecord.Owners[0].Share != "1/3" {
+		t.Fatalf("after severance: tenancy %s, owners %+v; want equal shares in common", landRecord.Tenancy, landRecord.Owners)
+	}
+	if err := sever("tx-sever-again", "arjun"); err == nil || !strings.Contains(err.Error(), "not held in joint tenancy") {
+		t.Fatalf("severing twice: err = %v, want it refused", err)
+	}
+
+	// Tenants in common sell alone, so no consent is asked for
+	initiated, err := initiate("tx-initiate")
+	if err != nil {
+		t.Fatalf("selling after severance failed: %v", err)
+	}
+	if transfer := initiated.result.(*TransferRequest); len(transfer.RequiredConsents) != 0 {
+		t.Fatalf("consents required %v, want none", transfer.RequiredConsents)
+	}
+	s.complete(initiated.result.(*TransferRequest), "meena")
+
+	landRecord := s.owners("CCLB-2025-TS-000001")
+	want := []CoOwner{
+		{Name: "Sita Devi", PersonID: sitaID, Share: "1/3"},
+		{Name: "Arjun Rao", PersonID: arjunID, Share: "1/3"},
+		{Name: "Meena Reddy", PersonID: meenaID, Share: "1/3"},
+	}
+	if landRecord.Tenancy != TenancyInCommon || !reflect.DeepEqual(landRecord.Owners, want) {
+		t.Fatalf("after the sale: tenancy %s, owners %+v; want %+v in common", landRecord.Tenancy, landRecord.Owners, want)
+	}
+}
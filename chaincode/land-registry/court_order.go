@@ -0,0 +1,368 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// CourtOrder is an attachment, status-quo order or injunction freezing a property
+// Stored under composite key COURT_ORDER~<propertyID>~<orderID>. While an
+// order is in force the parcel cannot be conveyed, mortgaged, leased,
+// subdivided, amalgamated or devolved by succession; transfers already open
+// cannot be approved.
+// Orders are never deleted: lifting or expiry is recorded on the order.
+type CourtOrder struct {
+	DocType      string `json:"docType"` // COURT_ORDER
+	OrderID      string `json:"orderId"`
+	PropertyID   string `json:"propertyId"`
+	Court        string `json:"court"`               // e.g. "Principal District Court, Rangareddy"
+	CaseNumber   string `json:"caseNumber"`          // e.g. "O.S. 123/2025"
+	OrderType    string `json:"orderType"`           // ATTACHMENT, STATUS_QUO, INJUNCTION
+	IssuedOn     string `json:"issuedOn"`            // YYYY-MM-DD
+	ExpiresOn    string `json:"expiresOn,omitempty"` // YYYY-MM-DD, last day in force; empty until lifted
+	OrderHash    string `json:"orderHash"`           // Hash of the scanned order
+	Status       string `json:"status"`              // ACTIVE, LIFTED, EXPIRED
+	RecordedAt   string `json:"recordedAt"`
+	RecordedBy   string `json:"recordedBy"`
+	RecordedTxID string `json:"recordedTxId"`
+
+	LiftedAt      string `json:"liftedAt,omitempty"`
+	LiftedBy      string `json:"liftedBy,omitempty"`
+	LiftedTxID    string `json:"liftedTxId,omitempty"`
+	LiftOrderHash string `json:"liftOrderHash,omitempty"` // Hash of the order vacating this one
+	LiftReason    string `json:"liftReason,omitempty"`
+}
+
+// Court order types and statuses
+const (
+	CourtOrderAttachment = "ATTACHMENT" // Attachment before judgment or in execution (CPC O.38 R.5, O.21 R.54)
+	CourtOrderStatusQuo  = "STATUS_QUO"
+	CourtOrderInjunction = "INJUNCTION" // Temporary injunction restraining alienation (CPC O.39)
+
+	CourtOrderActive  = "ACTIVE"
+	CourtOrderLifted  = "LIFTED"
+	CourtOrderExpired = "EXPIRED"
+)
+
+// RecordCourtOrder records a court order against a property and freezes it
+// expiresOn may be empty for an order in force until lifted.
+// Requires 'legal_officer' role
+func (c *LandRegistryContract) RecordCourtOrder(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	court string,
+	caseNumber string,
+	orderType string,
+	issuedOn string,
+	expiresOn string,
+	orderHash string,
+) (*CourtOrder, error) {
+	if err := requireRole(ctx, "legal_officer"); err != nil {
+		return nil, fmt.Errorf("only legal officers can record court orders: %v", err)
+	}
+
+	orderType = strings.ToUpper(strings.TrimSpace(orderType))
+	switch orderType {
+	case CourtOrderAttachment, CourtOrderStatusQuo, CourtOrderInjunction:
+	default:
+		return nil, fmt.Errorf("invalid court order type: %s", orderType)
+	}
+	if strings.TrimSpace(court) == "" || strings.TrimSpace(caseNumber) == "" {
+		return nil, fmt.Errorf("court and case number are required")
+	}
+	if len(orderHash) < 32 {
+		return nil, fmt.Errorf("invalid order hash format")
+	}
+	if _, err := time.Parse("2006-01-02", issuedOn); err != nil {
+		return nil, fmt.Errorf("invalid issue date %q (expected YYYY-MM-DD): %v", issuedOn, err)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	today := txTime.Format("2006-01-02")
+	if issuedOn > today {
+		return nil, fmt.Errorf("issue date %s is in the future", issuedOn)
+	}
+	if expiresOn != "" {
+		if _, err := time.Parse("2006-01-02", expiresOn); err != nil {
+			return nil, fmt.Errorf("invalid expiry date %q (expected YYYY-MM-DD): %v", expiresOn, err)
+		}
+		if expiresOn < today || expiresOn < issuedOn {
+			return nil, fmt.Errorf("court order has already expired on %s", expiresOn)
+		}
+	}
+
+	landRecord, err := c.readLandRecordWithDetails(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if landRecord.Status == RecordRetired {
+		return nil, fmt.Errorf("land record %s is retired; record the order against its successor parcels", propertyID)
+	}
+
+	orders, err := listCourtOrders(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range orders {
+		if existing.Status == CourtOrderActive && existing.OrderType == orderType &&
+			normalizeLookup(existing.CaseNumber) == normalizeLookup(caseNumber) &&
+			normalizeLookup(existing.Court) == normalizeLookup(court) {
+			return nil, fmt.Errorf("%s order in %s is already recorded as %s", orderType, caseNumber, existing.OrderID)
+		}
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	txID := ctx.GetStub().GetTxID()
+
+	order := &CourtOrder{
+		OrderID:      courtOrderIDFromTx(txID),
+		PropertyID:   propertyID,
+		Court:        strings.TrimSpace(court),
+		CaseNumber:   strings.TrimSpace(caseNumber),
+		OrderType:    orderType,
+		IssuedOn:     issuedOn,
+		ExpiresOn:    expiresOn,
+		OrderHash:    orderHash,
+		Status:       CourtOrderActive,
+		RecordedAt:   txTime.Format(time.RFC3339),
+		RecordedBy:   clientID,
+		RecordedTxID: txID,
+	}
+	if err := putCourtOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	landRecord.FrozenBy = append(landRecord.FrozenBy, order.OrderID)
+	landRecord.Frozen = true
+	landRecord.LastUpdated = today
+	if err := putLandRecord(ctx, landRecord); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitCourtOrderEvent(ctx, EventCourtOrderRecorded, order); err != nil {
+		fmt.Printf("warning: failed to emit CourtOrderEvent: %v\n", err)
+	}
+
+	return order, nil
+}
+
+// LiftCourtOrder records the order vacating a court order and unfreezes the
+// property once no other order holds it
+// Requires 'legal_officer' role
+func (c *LandRegistryContract) LiftCourtOrder(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	orderID string,
+	liftOrderHash string,
+	reason string,
+) (*CourtOrder, error) {
+	if err := requireRole(ctx, "legal_officer"); err != nil {
+		return nil, fmt.Errorf("only legal officers can lift court orders: %v", err)
+	}
+	if len(liftOrderHash) < 32 {
+		return nil, fmt.Errorf("invalid order hash format")
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a reason for lifting the order is required")
+	}
+
+	order, err := getCourtOrder(ctx, propertyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != CourtOrderActive {
+		return nil, fmt.Errorf("court order %s is %s", orderID, order.Status)
+	}
+	order.LiftOrderHash = liftOrderHash
+	order.LiftReason = reason
+	return c.endCourtOrder(ctx, order, CourtOrderLifted, EventCourtOrderLifted)
+}
+
+// ExpireCourtOrder records that a court order has passed its expiry date
+// Anyone may call it; the order stops freezing the property on expiry either way.
+func (c *LandRegistryContract) ExpireCourtOrder(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	orderID string,
+) (*CourtOrder, error) {
+	order, err := getCourtOrder(ctx, propertyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != CourtOrderActive {
+		return nil, fmt.Errorf("court order %s is %s", orderID, order.Status)
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if order.inForceOn(txTime.Format("2006-01-02")) {
+		if order.ExpiresOn == "" {
+			return nil, fmt.Errorf("court order %s has no expiry date; it must be lifted", orderID)
+		}
+		return nil, fmt.Errorf("court order %s is in force until %s", orderID, order.ExpiresOn)
+	}
+	return c.endCourtOrder(ctx, order, CourtOrderExpired, EventCourtOrderExpired)
+}
+
+// GetCourtOrders returns every court order ever recorded against a property
+func (c *LandRegistryContract) GetCourtOrders(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) ([]*CourtOrder, error) {
+	return listCourtOrders(ctx, propertyID)
+}
+
+// endCourtOrder closes an active order and drops it from the property's freeze
+func (c *LandRegistryContract) endCourtOrder(
+	ctx contractapi.TransactionContextInterface,
+	order *CourtOrder,
+	status string,
+	eventName string,
+) (*CourtOrder, error) {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Status = status
+	order.LiftedAt = txTime.Format(time.RFC3339)
+	order.LiftedBy = clientID
+	order.LiftedTxID = ctx.GetStub().GetTxID()
+	if err := putCourtOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	landRecord, err := c.readLandRecordWithDetails(ctx, order.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	frozenBy := []string{}
+	for _, orderID := range landRecord.FrozenBy {
+		if orderID != order.OrderID {
+			frozenBy = append(frozenBy, orderID)
+		}
+	}
+	landRecord.FrozenBy = frozenBy
+	landRecord.Frozen = len(frozenBy) > 0
+	landRecord.LastUpdated = txTime.Format("2006-01-02")
+	if err := putLandRecord(ctx, landRecord); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitCourtOrderEvent(ctx, eventName, order); err != nil {
+		fmt.Printf("warning: failed to emit CourtOrderEvent: %v\n", err)
+	}
+
+	return order, nil
+}
+
+// inForceOn reports whether an active order freezes its property on a YYYY-MM-DD date
+func (o *CourtOrder) inForceOn(date string) bool {
+	return o.Status == CourtOrderActive && (o.ExpiresOn == "" || date <= o.ExpiresOn)
+}
+
+// requireNotFrozen fails while a court order in force freezes the property
+// Expired orders stop counting on their expiry date, before ExpireCourtOrder
+// clears the record's freeze flag.
+func requireNotFrozen(ctx contractapi.TransactionContextInterface, propertyID string) error {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	orders, err := listCourtOrders(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	for _, order := range orders {
+		if order.inForceOn(txTime.Format("2006-01-02")) {
+			return fmt.Errorf("property %s is frozen by a %s order of %s in %s (%s)",
+				propertyID, strings.ToLower(order.OrderType), order.Court, order.CaseNumber, order.OrderID)
+		}
+	}
+	return nil
+}
+
+// courtOrderIDFromTx derives the court order ID from the recording tx
+// The tx ID is used whole; orders recorded in different txs never share it.
+func courtOrderIDFromTx(txID string) string {
+	return "CRT-" + txID
+}
+
+func getCourtOrder(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	orderID string,
+) (*CourtOrder, error) {
+	key, err := stateKey(ctx, DocTypeCourtOrder, propertyID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read court order: %v", err)
+	}
+	if orderJSON == nil {
+		return nil, fmt.Errorf("court order %s does not exist on %s", orderID, propertyID)
+	}
+
+	var order CourtOrder
+	if err := json.Unmarshal(orderJSON, &order); err != nil {
+		return nil, fmt.Errorf("failed to parse court order: %v", err)
+	}
+	return &order, nil
+}
+
+func putCourtOrder(ctx contractapi.TransactionContextInterface, order *CourtOrder) error {
+	order.DocType = DocTypeCourtOrder
+	key, err := stateKey(ctx, DocTypeCourtOrder, order.PropertyID, order.OrderID)
+	if err != nil {
+		return err
+	}
+	if err := putDocument(ctx, key, "", order); err != nil {
+		return fmt.Errorf("failed to store court order: %v", err)
+	}
+	return nil
+}
+
+func listCourtOrders(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) ([]*CourtOrder, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocTypeCourtOrder, []string{propertyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query court orders: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	orders := []*CourtOrder{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var order CourtOrder
+		if err := json.Unmarshal(queryResponse.Value, &order); err != nil {
+			return nil, fmt.Errorf("failed to parse court order: %v", err)
+		}
+		orders = append(orders, &order)
+	}
+
+	return orders, nil
+}
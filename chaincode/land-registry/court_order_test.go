@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestCourtOrderFreezesPropertyUntilLifted(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, arjunID := s.personIDs["ravi"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+	legalOfficer := testCreator(t, "StateOrgTSMSP", "legal_officer")
+	bankOfficer := testCreator(t, "SBIMSP", "bank_officer")
+
+	transfer := s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+	}).(*TransferRequest)
+
+	record := func(txID string, orderType string, expiresOn string) *CourtOrder {
+		return s.must(txID, legalOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.RecordCourtOrder(ctx, "CCLB-2025-TS-000001", "Principal District Court, Rangareddy",
+				"O.S. 123/2025", orderType, "2025-02-27", expiresOn, testDeedHash)
+		}).(*CourtOrder)
+	}
+	attachment := record("tx-attach", CourtOrderAttachment, "")
+	statusQuo := record("tx-status-quo", "status_quo", "2025-03-15")
+	if frozen := s.owners("CCLB-2025-TS-000001"); !frozen.Frozen || len(frozen.FrozenBy) != 2 {
+		t.Fatalf("record frozen %v by %v, want both orders", frozen.Frozen, frozen.FrozenBy)
+	}
+
+	s.must("tx-accept", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.AcceptTransfer(ctx, transfer.TransferID)
+	})
+	s.must("tx-verify", s.subRegistrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.VerifyTransferDocuments(ctx, transfer.TransferID, "1.5 L", "SD-2025-0001")
+	})
+	approve := func(txID string) error {
+		_, err := s.step(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.ApproveTransfer(ctx, transfer.TransferID)
+		})
+		return err
+	}
+	if err := approve("tx-approve-frozen"); err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Fatalf("approval of a frozen parcel: err = %v, want a freeze error", err)
+	}
+	if _, err := s.step("tx-mortgage", bankOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RecordEncumbrance(ctx, "CCLB-2025-TS-000001", EncumbranceMortgage, "SBI", "20 L", testDeedHash)
+	}); err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Fatalf("mortgage of a frozen parcel: err = %v, want a freeze error", err)
+	}
+
+	s.must("tx-lift", legalOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.LiftCourtOrder(ctx, "CCLB-2025-TS-000001", attachment.OrderID, testDeedHash, "Suit dismissed")
+	})
+	if err := approve("tx-approve-status-quo"); err == nil || !strings.Contains(err.Error(), statusQuo.OrderID) {
+		t.Fatalf("approval under the status-quo order: err = %v, want it frozen by %s", err, statusQuo.OrderID)
+	}
+	if _, err := s.step("tx-expire-early", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.ExpireCourtOrder(ctx, "CCLB-2025-TS-000001", statusQuo.OrderID)
+	}); err == nil || !strings.Contains(err.Error(), "in force") {
+		t.Fatalf("expiry before the expiry date: err = %v, want an in-force error", err)
+	}
+
+	// The order stops binding on expiry, before anyone records it
+	s.txTime = time.Date(2025, 3, 16, 10, 30, 0, 0, time.UTC)
+	if err := approve("tx-approve"); err != nil {
+		t.Fatalf("approval after the orders ended failed: %v", err)
+	}
+	s.must("tx-expire", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.ExpireCourtOrder(ctx, "CCLB-2025-TS-000001", statusQuo.OrderID)
+	})
+	if unfrozen := s.owners("CCLB-2025-TS-000001"); unfrozen.Frozen || len(unfrozen.FrozenBy) != 0 {
+		t.Fatalf("record frozen %v by %v after both orders ended", unfrozen.Frozen, unfrozen.FrozenBy)
+	}
+
+	orders, err := s.contract.GetCourtOrders(s.peer.ctx, "CCLB-2025-TS-000001")
+	if err != nil {
+		t.Fatalf("failed to read court orders: %v", err)
+	}
+	statuses := map[string]string{}
+	for _, order := range orders {
+		statuses[order.OrderID] = order.Status
+	}
+	if statuses[attachment.OrderID] != CourtOrderLifted || statuses[statusQuo.OrderID] != CourtOrderExpired {
+		t.Fatalf("court order history %v, want the attachment lifted and the status-quo order expired", statuses)
+	}
+}
+
+func TestCourtOrderHoldsSuccession(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, arjunID := s.personIDs["ravi"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+	legalOfficer := testCreator(t, "StateOrgTSMSP", "legal_officer")
+
+	caseID := s.must("tx-death", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RegisterDeath(ctx, raviID, "2025-02-20", testDeedHash)
+	}).(*SuccessionCase).CaseID
+	heirs, _ := json.Marshal([]CoOwner{{Name: "Arjun Rao", PersonID: arjunID, Share: "1"}})
+	s.must("tx-heirs", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.DeclareHeirs(ctx, caseID, SuccessionIntestate, "", string(heirs))
+	})
+	order := s.must("tx-attach", legalOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RecordCourtOrder(ctx, "CCLB-2025-TS-000001", "Principal District Court, Rangareddy",
+			"E.P. 45/2025", CourtOrderAttachment, "2025-02-28", "", testDeedHash)
+	}).(*CourtOrder)
+
+	s.txTime = s.txTime.Add(successionNoticePeriod + time.Hour)
+	decide := func(txID string) error {
+		_, err := s.step(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.DecideSuccession(ctx, caseID, "APPROVE", "")
+		})
+		return err
+	}
+	if err := decide("tx-decide-frozen"); err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Fatalf("devolution of an attached parcel: err = %v, want a freeze error", err)
+	}
+	s.must("tx-lift", legalOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.LiftCourtOrder(ctx, "CCLB-2025-TS-000001", order.OrderID, testDeedHash, "Decree satisfied")
+	})
+	if err := decide("tx-decide"); err != nil {
+		t.Fatalf("devolution after the attachment was lifted failed: %v", err)
+	}
+	if landRecord := s.owners("CCLB-2025-TS-000001"); landRecord.Owners[0].PersonID != arjunID {
+		t.Fatalf("owners after succession %+v, want Arjun", landRecord.Owners)
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Deed types for TransferRequest.DeedType
+// Every deed conveys the grantor's (seller's) whole share of a parcel:
+//   - SALE: to any registered person, for a consideration
+//   - GIFT, SETTLEMENT: to any registered person, without consideration
+//   - RELEASE: to another co-owner, whose share absorbs the released one
+//   - EXCHANGE: for the grantee's share of another parcel; both move in one transaction
+//   - PARTITION: among all co-owners; the parcel is subdivided and each co-owner
+//     takes the parcels allotted to them
+const (
+	DeedSale       = "SALE"
+	DeedGift       = "GIFT"
+	DeedSettlement = "SETTLEMENT"
+	DeedRelease    = "RELEASE"
+	DeedExchange   = "EXCHANGE"
+	DeedPartition  = "PARTITION"
+)
+
+// Stamp duty categories, after the Indian Stamp Act article each deed falls under
+const (
+	StampDutyConveyance = "CONVEYANCE" // Art. 23
+	StampDutyExchange   = "EXCHANGE"   // Art. 31
+	StampDutyGift       = "GIFT"       // Art. 33
+	StampDutyPartition  = "PARTITION"  // Art. 45
+	StampDutyRelease    = "RELEASE"    // Art. 55
+	StampDutySettlement = "SETTLEMENT" // Art. 58
+)
+
+// deedStampDutyCategories maps each deed type to its stamp duty category
+var deedStampDutyCategories = map[string]string{
+	DeedSale:       StampDutyConveyance,
+	DeedGift:       StampDutyGift,
+	DeedSettlement: StampDutySettlement,
+	DeedRelease:    StampDutyRelease,
+	DeedExchange:   StampDutyExchange,
+	DeedPartition:  StampDutyPartition,
+}
+
+// DeedTerms carries the terms particular to an exchange or partition deed
+type DeedTerms struct {
+	ExchangePropertyID string             `json:"exchangePropertyId,omitempty"` // EXCHANGE: the parcel the grantee gives in return
+	Allotments         []SubdivisionChild `json:"allotments,omitempty"`         // PARTITION: child parcels, each with an allotteeId
+}
+
+// ConveyanceSummary totals completed conveyances of one deed type
+type ConveyanceSummary struct {
+	DeedType          string `json:"deedType"`
+	StampDutyCategory string `json:"stampDutyCategory"`
+	Count             int    `json:"count"`
+	Consideration     Money  `json:"consideration"`
+	StampDutyPaid     Money  `json:"stampDutyPaid"`
+}
+
+// GetConveyanceSummary breaks down transfers completed between two dates by deed type
+// Dates are YYYY-MM-DD and inclusive; either may be empty for an open range.
+func (c *LandRegistryContract) GetConveyanceSummary(
+	ctx contractapi.TransactionContextInterface,
+	fromDate string,
+	toDate string,
+) ([]*ConveyanceSummary, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocTypeTransfer, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transfer requests: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	summaries := map[string]*ConveyanceSummary{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		var transfer TransferRequest
+		if err := json.Unmarshal(queryResponse.Value, &transfer); err != nil {
+			return nil, fmt.Errorf("failed to parse transfer request: %v", err)
+		}
+		if transfer.Status != TransferCompleted || len(transfer.UpdatedAt) < 10 {
+			continue
+		}
+		completed := transfer.UpdatedAt[:10]
+		if (fromDate != "" && completed < fromDate) || (toDate != "" && completed > toDate) {
+			continue
+		}
+
+		deedType := transfer.DeedType
+		if deedType == "" {
+			deedType = DeedSale
+		}
+		summary, ok := summaries[deedType]
+		if !ok {
+			summary = &ConveyanceSummary{DeedType: deedType, StampDutyCategory: deedStampDutyCategories[deedType]}
+			summaries[deedType] = summary
+		}
+		summary.Count++
+		summary.Consideration.Paise += transfer.SaleConsideration.Paise
+		summary.StampDutyPaid.Paise += transfer.StampDutyPaid.Paise
+	}
+
+	result := make([]*ConveyanceSummary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, summary)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].DeedType < result[j].DeedType })
+	return result, nil
+}
+
+// parseConsideration validates the consideration a deed type calls for
+// A sale needs one; gifts and settlements have none; the rest may carry one
+// (owelty on a partition, equality money on an exchange).
+func parseConsideration(deedType string, text string) (Money, error) {
+	if text = strings.TrimSpace(text); text == "" || text == "0" {
+		if deedType == DeedSale {
+			return Money{}, fmt.Errorf("a sale needs a consideration")
+		}
+		return Money{}, nil
+	}
+	if deedType == DeedGift || deedType == DeedSettlement {
+		return Money{}, fmt.Errorf("a %s deed has no consideration", strings.ToLower(deedType))
+	}
+	return parseRupees(text)
+}
+
+// prepareDeed applies the checks and terms particular to a deed type to a new transfer
+// The grantee must already be a co-owner for a release or partition. An
+// exchange locks the grantee's parcel too and, like a partition, needs the
+// consent of any further co-owners whose holdings change.
+func (c *LandRegistryContract) prepareDeed(
+	ctx contractapi.TransactionContextInterface,
+	landRecord *LandRecord,
+	transfer *TransferRequest,
+	grantee *Person,
+	terms DeedTerms,
+) error {
+	if transfer.DeedType != DeedExchange && terms.ExchangePropertyID != "" {
+		return fmt.Errorf("only an exchange deed names a property in exchange")
+	}
+	if transfer.DeedType != DeedPartition && len(terms.Allotments) > 0 {
+		return fmt.Errorf("only a partition deed allots parcels")
+	}
+
+	switch transfer.DeedType {
+	case DeedRelease:
+		if findCoOwner(landRecord.Owners, grantee) < 0 {
+			return fmt.Errorf("a release must be in favour of another co-owner of %s", landRecord.PropertyID)
+		}
+
+	case DeedPartition:
+		if findCoOwner(landRecord.Owners, grantee) < 0 {
+			return fmt.Errorf("a partition must be with another co-owner of %s", landRecord.PropertyID)
+		}
+		if len(terms.Allotments) < 2 {
+			return fmt.Errorf("a partition must allot at least two parcels")
+		}
+		allotted := map[string]bool{}
+		for _, child := range terms.Allotments {
+			if child.AllotteeID == "" || findCoOwner(landRecord.Owners, &Person{PersonID: child.AllotteeID}) < 0 {
+				return fmt.Errorf("allotment %s: allottee %q is not a co-owner of %s", child.PropertyID, child.AllotteeID, landRecord.PropertyID)
+			}
+			allotted[child.AllotteeID] = true
+		}
+		for _, owner := range landRecord.Owners {
+			if owner.PersonID == "" {
+				return fmt.Errorf("co-owner %s must be linked to a registered person before a partition", owner.Name)
+			}
+			if !allotted[owner.PersonID] {
+				return fmt.Errorf("co-owner %s is allotted no parcel", owner.Name)
+			}
+			if owner.PersonID != transfer.SellerID && !contains(transfer.RequiredConsents, owner.PersonID) {
+				transfer.RequiredConsents = append(transfer.RequiredConsents, owner.PersonID)
+			}
+		}
+		transfer.Allotments = terms.Allotments
+
+	case DeedExchange:
+		if terms.ExchangePropertyID == "" || terms.ExchangePropertyID == landRecord.PropertyID {
+			return fmt.Errorf("an exchange needs the grantee's property to exchange for")
+		}
+		other, err := c.readLandRecordWithDetails(ctx, terms.ExchangePropertyID)
+		if err != nil {
+			return err
+		}
+		if other.Status == RecordRetired {
+			return fmt.Errorf("land record %s is retired; exchange its successor parcels instead", other.PropertyID)
+		}
+		if other.VerificationStatus == VerificationRejected {
+			return fmt.Errorf("land record %s is flagged by CCLB: %s", other.PropertyID, other.VerificationReason)
+		}
+		holding := findCoOwner(other.Owners, grantee)
+		if holding < 0 {
+			return fmt.Errorf("grantee does not own a share of %s", other.PropertyID)
+		}
+		if err := requireShareConveyable(other); err != nil {
+			return err
+		}
+		if other.Tenancy == TenancyJoint {
+			for i, coOwner := range other.Owners {
+				if i == holding {
+					continue
+				}
+				if coOwner.PersonID == "" {
+					return fmt.Errorf("joint tenant %s must be linked to a registered person before a share can be transferred", coOwner.Name)
+				}
+				if coOwner.PersonID != transfer.SellerID && !contains(transfer.RequiredConsents, coOwner.PersonID) {
+					transfer.RequiredConsents = append(transfer.RequiredConsents, coOwner.PersonID)
+				}
+			}
+		}
+		active, err := getActiveTransferID(ctx, other.PropertyID)
+		if err != nil {
+			return err
+		}
+		if active != "" {
+			return fmt.Errorf("property %s already has an open transfer %s", other.PropertyID, active)
+		}
+		transfer.ExchangePropertyID = other.PropertyID
+		transfer.ExchangeShare = other.Owners[holding].Share
+	}
+
+	// The grantee's acceptance stands in for their consent
+	consents := transfer.RequiredConsents[:0]
+	for _, personID := range transfer.RequiredConsents {
+		if personID != transfer.BuyerID {
+			consents = append(consents, personID)
+		}
+	}
+	transfer.RequiredConsents = consents
+	return nil
+}
+
+// lockedProperties lists the parcels an open transfer holds the lock on
+func (t *TransferRequest) lockedProperties() []string {
+	if t.ExchangePropertyID != "" {
+		return []string{t.PropertyID, t.ExchangePropertyID}
+	}
+	return []string{t.PropertyID}
+}
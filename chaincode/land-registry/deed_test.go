@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const testDeedHash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+// deedScenario is a peer with three registered citizens and helpers to record parcels
+type deedScenario struct {
+	t                       *testing.T
+	contract                *LandRegistryContract
+	peer                    *endorser
+	registrar, subRegistrar []byte
+	citizens                map[string][]byte
+	personIDs               map[string]string
+	txTime                  time.Time
+}
+
+func newDeedScenario(t *testing.T) *deedScenario {
+	s := &deedScenario{
+		t:            t,
+		contract:     new(LandRegistryContract),
+		peer:         newEndorser("peer0"),
+		registrar:    testCreator(t, "StateOrgTSMSP", "registrar"),
+		subRegistrar: testCreator(t, "StateOrgTSMSP", "jt_sub_registrar"),
+		citizens:     map[string][]byte{},
+		personIDs:    map[string]string{},
+		txTime:       time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC),
+	}
+	for name, fullName := range map[string]string{"ravi": "Ravi Kumar", "sita": "Sita Devi", "arjun": "Arjun Rao"} {
+		fullName := fullName
+		s.citizens[name] = testCreatorWithAttrs(t, "StateOrgTSMSP", map[string]string{"role": "citizen", "hf.EnrollmentID": name})
+		s.personIDs[name] = s.must("tx-register-"+name, s.citizens[name], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.RegisterPerson(ctx, fullName)
+		}).(*Person).PersonID
+	}
+	return s
+}
+
+func (s *deedScenario) step(txID string, creator []byte, invoke func(ctx contractapi.TransactionContextInterface) (interface{}, error)) (endorsement, error) {
+	return s.peer.submit(s.t, proposal{txID: txID, creator: creator, time: s.txTime, invoke: invoke})
+}
+
+func (s *deedScenario) must(txID string, creator []byte, invoke func(ctx contractapi.TransactionContextInterface) (interface{}, error)) interface{} {
+	s.t.Helper()
+	endorsed, err := s.step(txID, creator, invoke)
+	if err != nil {
+		s.t.Fatalf("%s failed: %v", txID, err)
+	}
+	return endorsed.result
+}
+
+// record binds a two-acre parcel held by the given co-owners
+func (s *deedScenario) record(propertyID string, surveyNo string, owners ...CoOwner) {
+	s.t.Helper()
+	s.recordWithTenancy(propertyID, surveyNo, "", owners...)
+}
+
+// recordWithTenancy binds a two-acre parcel held by the co-owners under the given tenancy
+func (s *deedScenario) recordWithTenancy(propertyID string, surveyNo string, tenancy string, owners ...CoOwner) {
+	s.t.Helper()
+	private, _ := json.Marshal(privateDetailsInput{Owners: owners, Tenancy: tenancy, MarketValue: "45 L", Salt: "3f1c9a7e5b2d8046"})
+	requested, err := s.peer.submit(s.t, proposal{
+		txID:      "tx-request-" + surveyNo,
+		creator:   s.registrar,
+		time:      s.txTime,
+		transient: map[string][]byte{privateDetailsTransientKey: private},
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.RequestPropertyID(ctx, "TS", "", surveyNo, "Rangareddy",
+				"Shamshabad", "Kothur", "2 acres", "agricultural", "", "")
+		},
+	})
+	if err != nil {
+		s.t.Fatalf("request for %s failed: %v", surveyNo, err)
+	}
+	s.must("tx-bind-"+surveyNo, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.CreateStateRecord(ctx, propertyID, requested.result.(string), "")
+	})
+}
+
+// complete takes an initiated transfer through acceptance, verification and approval
+func (s *deedScenario) complete(transfer *TransferRequest, grantee string) *TransferRequest {
+	s.t.Helper()
+	completed, _ := s.completeWithEvent(transfer, grantee)
+	return completed
+}
+
+// completeWithEvent is complete, also returning the approval's PropertyTransferred event
+func (s *deedScenario) completeWithEvent(transfer *TransferRequest, grantee string) (*TransferRequest, *PropertyTransferredEvent) {
+	s.t.Helper()
+	s.must("tx-accept", s.citizens[grantee], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.AcceptTransfer(ctx, transfer.TransferID)
+	})
+	s.must("tx-verify", s.subRegistrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.VerifyTransferDocuments(ctx, transfer.TransferID, "1.5 L", "SD-2025-0001")
+	})
+	approved, err := s.step("tx-approve", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.ApproveTransfer(ctx, transfer.TransferID)
+	})
+	if err != nil {
+		s.t.Fatalf("tx-approve failed: %v", err)
+	}
+	var event PropertyTransferredEvent
+	if err := json.Unmarshal(approved.events[EventPropertyTransferred], &event); err != nil {
+		s.t.Fatalf("approval emitted no %s event: %v", EventPropertyTransferred, err)
+	}
+	return approved.result.(*TransferRequest), &event
+}
+
+func (s *deedScenario) owners(propertyID string) *LandRecord {
+	s.t.Helper()
+	landRecord, err := s.contract.readLandRecordWithDetails(s.peer.ctx, propertyID)
+	if err != nil {
+		s.t.Fatalf("failed to read %s: %v", propertyID, err)
+	}
+	return landRecord
+}
+
+func TestParseConsiderationByDeedType(t *testing.T) {
+	for _, tc := range []struct {
+		deedType string
+		text     string
+		want     string
+	}{
+		{DeedSale, "", "needs a consideration"},
+		{DeedGift, "5 L", "no consideration"},
+		{DeedSettlement, "1 L", "no consideration"},
+	} {
+		if _, err := parseConsideration(tc.deedType, tc.text); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("parseConsideration(%s, %q) error = %v, want one mentioning %q", tc.deedType, tc.text, err, tc.want)
+		}
+	}
+	for _, deedType := range []string{DeedGift, DeedRelease, DeedExchange, DeedPartition} {
+		if amount, err := parseConsideration(deedType, ""); err != nil || amount.Paise != 0 {
+			t.Errorf("parseConsideration(%s, \"\") = %v, %v; want no consideration", deedType, amount, err)
+		}
+	}
+}
+
+func TestExchangeSwapsBothParcelsInOneTransaction(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, arjunID := s.personIDs["ravi"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+	s.record("CCLB-2025-TS-000002", "202/B", CoOwner{Name: "Arjun Rao", PersonID: arjunID, Share: "1"})
+
+	terms, _ := json.Marshal(DeedTerms{ExchangePropertyID: "CCLB-2025-TS-000002"})
+	transfer := s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateConveyance(ctx, "CCLB-2025-TS-000001", "exchange", arjunID, "", testDeedHash, string(terms))
+	}).(*TransferRequest)
+	if transfer.StampDutyCategory != StampDutyExchange || transfer.ExchangeShare != "1/1" {
+		t.Fatalf("exchange opened as %+v", transfer)
+	}
+	if _, err := s.step("tx-sell-locked", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000002", raviID, "30 L", testDeedHash)
+	}); err == nil || !strings.Contains(err.Error(), "open transfer") {
+		t.Fatalf("sale of the parcel given in exchange: err = %v, want it locked", err)
+	}
+
+	transfer, event := s.completeWithEvent(transfer, "arjun")
+	if transfer.MutationCaseID == "" || transfer.ExchangeMutationCaseID == "" {
+		t.Fatalf("mutation cases %q and %q, want one per parcel", transfer.MutationCaseID, transfer.ExchangeMutationCaseID)
+	}
+
+	// The transaction's one event reports both parcels
+	wantConveyances := []PropertyConveyance{
+		{PropertyID: "CCLB-2025-TS-000001", FromPersonID: raviID, ToPersonID: arjunID, Share: "1/1", MutationCaseID: transfer.MutationCaseID},
+		{PropertyID: "CCLB-2025-TS-000002", FromPersonID: arjunID, ToPersonID: raviID, Share: "1/1", MutationCaseID: transfer.ExchangeMutationCaseID},
+	}
+	if event.TransferID != transfer.TransferID || event.DeedType != DeedExchange || event.StampDutyCategory != StampDutyExchange ||
+		event.ApprovalStatus != TransferCompleted || !reflect.DeepEqual(event.Conveyances, wantConveyances) {
+		t.Fatalf("PropertyTransferred event %+v, want an exchange of %+v", event, wantConveyances)
+	}
+	if first := s.owners("CCLB-2025-TS-000001"); first.Owners[0].PersonID != arjunID {
+		t.Errorf("first parcel owned by %+v, want Arjun", first.Owners)
+	}
+	if second := s.owners("CCLB-2025-TS-000002"); second.Owners[0].PersonID != raviID || second.MutationCaseID != transfer.ExchangeMutationCaseID {
+		t.Errorf("second parcel owned by %+v under mutation %s, want Ravi under %s", second.Owners, second.MutationCaseID, transfer.ExchangeMutationCaseID)
+	}
+	for _, propertyID := range transfer.lockedProperties() {
+		if active, _ := getActiveTransferID(s.peer.ctx, propertyID); active != "" {
+			t.Errorf("%s still locked by %s", propertyID, active)
+		}
+	}
+}
+
+func TestPartitionAllotsChildParcelsToEachCoOwner(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, sitaID, arjunID := s.personIDs["ravi"], s.personIDs["sita"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A",
+		CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1/2"},
+		CoOwner{Name: "Sita Devi", PersonID: sitaID, Share: "1/2"})
+
+	initiate := func(txID string, grantee string, allotments []SubdivisionChild) (endorsement, error) {
+		terms, _ := json.Marshal(DeedTerms{Allotments: allotments})
+		return s.step(txID, s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.InitiateConveyance(ctx, "CCLB-2025-TS-000001", DeedPartition, grantee, "", testDeedHash, string(terms))
+		})
+	}
+	if _, err := initiate("tx-partition-stranger", arjunID, nil); err == nil || !strings.Contains(err.Error(), "co-owner") {
+		t.Fatalf("partition with a stranger: err = %v, want a co-owner error", err)
+	}
+	if _, err := initiate("tx-partition-one-sided", sitaID, []SubdivisionChild{
+		{PropertyID: "CCLB-2025-TS-000011", SurveyNo: "101/A/1", Area: "1 acre", MarketValue: "22.5 L", AllotteeID: raviID},
+		{PropertyID: "CCLB-2025-TS-000012", SurveyNo: "101/A/2", Area: "1 acre", MarketValue: "22.5 L", AllotteeID: raviID},
+	}); err == nil || !strings.Contains(err.Error(), "allotted no parcel") {
+		t.Fatalf("partition leaving Sita out: err = %v, want an allotment error", err)
+	}
+	initiated, err := initiate("tx-partition", sitaID, []SubdivisionChild{
+		{PropertyID: "CCLB-2025-TS-000011", SurveyNo: "101/A/1", Area: "1 acre", MarketValue: "22.5 L", AllotteeID: raviID},
+		{PropertyID: "CCLB-2025-TS-000012", SurveyNo: "101/A/2", Area: "1 acre", MarketValue: "22.5 L", AllotteeID: sitaID},
+	})
+	if err != nil {
+		t.Fatalf("partition failed: %v", err)
+	}
+
+	transfer, event := s.completeWithEvent(initiated.result.(*TransferRequest), "sita")
+	if transfer.LineageOperationID == "" || transfer.MutationCaseID != "" {
+		t.Fatalf("partition completed with lineage %q and mutation %q, want a lineage operation only", transfer.LineageOperationID, transfer.MutationCaseID)
+	}
+	wantConveyances := []PropertyConveyance{
+		{PropertyID: "CCLB-2025-TS-000011", ToPersonID: raviID, Share: "1/1"},
+		{PropertyID: "CCLB-2025-TS-000012", ToPersonID: sitaID, Share: "1/1"},
+	}
+	if event.DeedType != DeedPartition || event.StampDutyCategory != StampDutyPartition || !reflect.DeepEqual(event.Conveyances, wantConveyances) {
+		t.Fatalf("PropertyTransferred event %+v, want the child parcels %+v", event, wantConveyances)
+	}
+	if parent := s.owners("CCLB-2025-TS-000001"); parent.Status != RecordRetired {
+		t.Errorf("partitioned parcel is %s, want %s", parent.Status, RecordRetired)
+	}
+	for propertyID, ownerID := range map[string]string{"CCLB-2025-TS-000011": raviID, "CCLB-2025-TS-000012": sitaID} {
+		child := s.owners(propertyID)
+		if child.Tenancy != TenancySole || len(child.Owners) != 1 || child.Owners[0].PersonID != ownerID {
+			t.Errorf("%s held %s by %+v, want solely by %s", propertyID, child.Tenancy, child.Owners, ownerID)
+		}
+	}
+}
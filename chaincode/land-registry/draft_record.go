@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Draft lifecycle statuses (LandRecord.Status on DRAFT documents)
+const (
+	DraftPendingID = "PENDING_ID" // Waiting for CCLB to issue a Property ID
+	DraftBound     = "BOUND"      // CreateStateRecord bound it to a Property ID
+	DraftCancelled = "CANCELLED"
+	DraftExpired   = "EXPIRED"
+)
+
+// draftTimeout is how long a draft may wait for a Property ID before it can be expired
+const draftTimeout = 30 * 24 * time.Hour
+
+// ListDrafts returns Property ID request drafts, optionally filtered by status
+// Pass an empty status to list drafts in every state.
+func (c *LandRegistryContract) ListDrafts(
+	ctx contractapi.TransactionContextInterface,
+	status string,
+) ([]*LandRecord, error) {
+	switch status {
+	case "", DraftPendingID, DraftBound, DraftCancelled, DraftExpired:
+	default:
+		return nil, fmt.Errorf("invalid draft status: %s", status)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocTypeDraft, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	drafts := []*LandRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) == 0 {
+			return nil, fmt.Errorf("invalid draft key: %v", err)
+		}
+
+		var draft LandRecord
+		if err := decodeDocument(DocTypeDraft, queryResponse.Value, &draft); err != nil {
+			return nil, fmt.Errorf("failed to parse draft %s: %v", attributes[0], err)
+		}
+		draft.RequestID = attributes[0]
+		if status == "" || draft.Status == status {
+			drafts = append(drafts, &draft)
+		}
+	}
+
+	return drafts, nil
+}
+
+// CancelDraft withdraws a Property ID request that has not been bound yet
+// Requires 'registrar' role
+func (c *LandRegistryContract) CancelDraft(
+	ctx contractapi.TransactionContextInterface,
+	requestID string,
+	reason string,
+) (*LandRecord, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can cancel drafts: %v", err)
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("a cancellation reason is required")
+	}
+
+	draft, err := getDraft(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != DraftPendingID {
+		return nil, fmt.Errorf("draft %s is already %s", requestID, draft.Status)
+	}
+
+	return c.closeDraft(ctx, draft, DraftCancelled, reason, EventDraftCancelled)
+}
+
+// ExpireDraft closes a draft that waited longer than draftTimeout for a Property ID
+// Anyone may call this; it only succeeds once ExpiresAt is in the past
+func (c *LandRegistryContract) ExpireDraft(
+	ctx contractapi.TransactionContextInterface,
+	requestID string,
+) (*LandRecord, error) {
+	draft, err := getDraft(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if draft.Status != DraftPendingID {
+		return nil, fmt.Errorf("draft %s is already %s", requestID, draft.Status)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expired, err := draftExpired(draft, txTime)
+	if err != nil {
+		return nil, err
+	}
+	if !expired {
+		return nil, fmt.Errorf("draft %s does not expire until %s", requestID, draft.ExpiresAt)
+	}
+
+	return c.closeDraft(ctx, draft, DraftExpired, "no Property ID bound by "+draft.ExpiresAt, EventDraftExpired)
+}
+
+// closeDraft moves a pending draft to a terminal status, frees its survey tuple and emits its event
+func (c *LandRegistryContract) closeDraft(
+	ctx contractapi.TransactionContextInterface,
+	draft *LandRecord,
+	status string,
+	reason string,
+	eventName string,
+) (*LandRecord, error) {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	draft.Status = status
+	draft.LastUpdated = txTime.Format("2006-01-02")
+	if err := putDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+	if err := releaseSurveyClaim(ctx, newLandLookup(draft), "", draft.RequestID); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitDraftEvent(ctx, eventName, draft, reason); err != nil {
+		fmt.Printf("warning: failed to emit %s: %v\n", eventName, err)
+	}
+
+	return draft, nil
+}
+
+// draftIDFromTx derives the request ID from the requesting tx
+// Each transaction stores at most one draft and the whole tx ID is kept, so two
+// registrars requesting in the same second still get distinct IDs.
+func draftIDFromTx(stateCode string, txID string) string {
+	return fmt.Sprintf("REQ-%s-%s", stateCode, txID)
+}
+
+// draftExpired reports whether a draft's deadline to bind a Property ID has passed
+// Drafts without a deadline never expire.
+func draftExpired(draft *LandRecord, now time.Time) (bool, error) {
+	if draft.ExpiresAt == "" {
+		return false, nil
+	}
+	expiresAt, err := time.Parse(time.RFC3339, draft.ExpiresAt)
+	if err != nil {
+		return false, fmt.Errorf("invalid expiry on draft %s: %v", draft.RequestID, err)
+	}
+	return now.After(expiresAt), nil
+}
+
+func getDraft(ctx contractapi.TransactionContextInterface, requestID string) (*LandRecord, error) {
+	draftKey, err := stateKey(ctx, DocTypeDraft, requestID)
+	if err != nil {
+		return nil, err
+	}
+	draftJSON, err := getDocument(ctx, draftKey, requestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read draft record: %v", err)
+	}
+	if draftJSON == nil {
+		return nil, fmt.Errorf("draft record %s not found (did you call RequestPropertyID first?)", requestID)
+	}
+
+	var draft LandRecord
+	if err := decodeDocument(DocTypeDraft, draftJSON, &draft); err != nil {
+		return nil, fmt.Errorf("failed to parse draft record: %v", err)
+	}
+	draft.RequestID = requestID // Drafts written before request IDs were stored
+	return &draft, nil
+}
+
+// putDraftAt stores a draft read from a composite key, whose JSON may predate request IDs
+func putDraftAt(ctx contractapi.TransactionContextInterface, key string, draft *LandRecord) error {
+	_, attributes, err := ctx.GetStub().SplitCompositeKey(key)
+	if err != nil || len(attributes) == 0 {
+		return fmt.Errorf("invalid draft key: %v", err)
+	}
+	draft.RequestID = attributes[0]
+	return putDraft(ctx, draft)
+}
+
+// putDraft stores a draft, moving its private fields to the private collection
+func putDraft(ctx contractapi.TransactionContextInterface, draft *LandRecord) error {
+	draftKey, err := stateKey(ctx, DocTypeDraft, draft.RequestID)
+	if err != nil {
+		return err
+	}
+	if err := storePrivateDetails(ctx, DocTypeDraft, draft.RequestID, draft); err != nil {
+		return err
+	}
+	if err := putDocument(ctx, draftKey, draft.RequestID, draft); err != nil {
+		return fmt.Errorf("failed to store draft record: %v", err)
+	}
+	return nil
+}
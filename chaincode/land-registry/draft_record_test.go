@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestDraftLifecycle(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peer := newEndorser("peer0")
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	citizen := testCreator(t, "StateOrgTSMSP", "citizen")
+	txTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	request := func(txID string, surveyNo string) string {
+		return peer.endorse(t, proposal{
+			txID:    txID,
+			creator: registrar,
+			time:    txTime, // Same second for every request
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.RequestPropertyID(ctx, "TS", "Ravi Kumar", surveyNo, "Rangareddy",
+					"Shamshabad", "Kothur", "2 acres", "agricultural", "45 L", "")
+			},
+		}).result.(string)
+	}
+	first, second, third := request("tx-request-1", "101/A"), request("tx-request-2", "101/B"), request("tx-request-3", "101/C")
+	if first == second || second == third {
+		t.Fatalf("requests in the same second share an ID: %s, %s, %s", first, second, third)
+	}
+
+	peer.endorse(t, proposal{
+		txID:    "tx-bind",
+		creator: registrar,
+		time:    txTime.Add(time.Hour),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateStateRecord(ctx, "CCLB-2025-TS-000001", first, "")
+		},
+	})
+	peer.endorse(t, proposal{
+		txID:    "tx-cancel",
+		creator: registrar,
+		time:    txTime.Add(time.Hour),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CancelDraft(ctx, second, "duplicate submission")
+		},
+	})
+
+	pending, err := contract.ListDrafts(peer.ctx, DraftPendingID)
+	if err != nil || len(pending) != 1 || pending[0].RequestID != third {
+		t.Fatalf("pending drafts = %v (%v), want only %s", pending, err, third)
+	}
+
+	// Bound and cancelled drafts cannot be bound again
+	for _, requestID := range []string{first, second} {
+		_, err := peer.submit(t, proposal{
+			txID:    "tx-rebind",
+			creator: registrar,
+			time:    txTime.Add(2 * time.Hour),
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.CreateStateRecord(ctx, "CCLB-2025-TS-000002", requestID, "")
+			},
+		})
+		if err == nil {
+			t.Fatalf("draft %s was bound twice", requestID)
+		}
+	}
+
+	expire := proposal{
+		txID:    "tx-expire",
+		creator: citizen,
+		time:    txTime.Add(draftTimeout),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.ExpireDraft(ctx, third)
+		},
+	}
+	if _, err := peer.submit(t, expire); err == nil {
+		t.Fatalf("draft %s expired before its deadline", third)
+	}
+
+	expire.time = txTime.Add(draftTimeout + time.Second)
+	expired := peer.endorse(t, expire)
+	if draft := expired.result.(*LandRecord); draft.Status != DraftExpired {
+		t.Fatalf("draft status = %s, want %s", draft.Status, DraftExpired)
+	}
+	if _, ok := expired.events[EventDraftExpired]; !ok {
+		t.Fatalf("no %s event emitted", EventDraftExpired)
+	}
+}
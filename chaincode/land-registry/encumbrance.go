@@ -0,0 +1,428 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Encumbrance is a mortgage, charge or lien registered by a bank against a property
+// Stored under composite key ENCUMBRANCE~<propertyID>~<encumbranceID>.
+// An ACTIVE encumbrance blocks ApproveTransfer unless the lender has consented
+// to that specific transfer.
+type Encumbrance struct {
+	DocType           string `json:"docType"` // ENCUMBRANCE
+	EncumbranceID     string `json:"encumbranceId"`
+	PropertyID        string `json:"propertyId"`
+	Type              string `json:"type"` // MORTGAGE, CHARGE, LIEN
+	LenderName        string `json:"lenderName"`
+	LenderMSP         string `json:"lenderMSP"`
+	Amount            Money  `json:"amount"`
+	DocumentHash      string `json:"documentHash"`
+	Status            string `json:"status"` // ACTIVE, RELEASED
+	RegisteredAt      string `json:"registeredAt"`
+	RegisteredBy      string `json:"registeredBy"`
+	RegisteredTxID    string `json:"registeredTxId"`
+	ConsentTransferID string `json:"consentTransferId,omitempty"` // Transfer the lender allowed to proceed
+	ReleasedAt        string `json:"releasedAt,omitempty"`
+	ReleaseReference  string `json:"releaseReference,omitempty"` // Repayment / discharge reference
+	ReleasedTxID      string `json:"releasedTxId,omitempty"`
+	ReleasedBy        string `json:"releasedBy,omitempty"`
+}
+
+// EncumbranceCertificate lists charges over a property for a date range
+type EncumbranceCertificate struct {
+	PropertyID  string         `json:"propertyId"`
+	FromDate    string         `json:"fromDate"`
+	ToDate      string         `json:"toDate"`
+	GeneratedAt string         `json:"generatedAt"`
+	NilEntries  bool           `json:"nilEntries"` // true when no charge subsisted in the range
+	Charges     []*Encumbrance `json:"charges"`
+}
+
+// Encumbrance types and statuses
+const (
+	EncumbranceMortgage = "MORTGAGE"
+	EncumbranceCharge   = "CHARGE"
+	EncumbranceLien     = "LIEN"
+
+	EncumbranceActive   = "ACTIVE"
+	EncumbranceReleased = "RELEASED"
+)
+
+// RecordEncumbrance registers a mortgage/charge/lien against a property
+// Not while a transfer is open: the buyer agreed to take the parcel with the
+// charges it had, and the lender must wait for the new owner.
+// Requires 'bank_officer' role
+func (c *LandRegistryContract) RecordEncumbrance(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	encumbranceType string,
+	lenderName string,
+	amount string,
+	documentHash string,
+) (*Encumbrance, error) {
+	if err := requireRole(ctx, "bank_officer"); err != nil {
+		return nil, fmt.Errorf("only bank officers can record encumbrances: %v", err)
+	}
+
+	encumbranceType = strings.ToUpper(strings.TrimSpace(encumbranceType))
+	switch encumbranceType {
+	case EncumbranceMortgage, EncumbranceCharge, EncumbranceLien:
+	default:
+		return nil, fmt.Errorf("invalid encumbrance type: %s", encumbranceType)
+	}
+	if strings.TrimSpace(lenderName) == "" {
+		return nil, fmt.Errorf("lender name is required")
+	}
+	securedAmount, err := parseRupees(amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encumbrance amount: %v", err)
+	}
+	if documentHash == "" || len(documentHash) < 32 {
+		return nil, fmt.Errorf("invalid document hash format")
+	}
+
+	landRecord, err := getLandRecord(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if landRecord.Status == RecordRetired {
+		return nil, fmt.Errorf("land record %s is retired", propertyID)
+	}
+	if err := requireNotFrozen(ctx, propertyID); err != nil {
+		return nil, err
+	}
+	active, err := getActiveTransferID(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if active != "" {
+		return nil, fmt.Errorf("property %s has an open transfer %s", propertyID, active)
+	}
+
+	lenderMSP, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lender MSP ID: %v", err)
+	}
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txID := ctx.GetStub().GetTxID()
+
+	encumbrance := &Encumbrance{
+		EncumbranceID:  encumbranceIDFromTx(txID),
+		PropertyID:     propertyID,
+		Type:           encumbranceType,
+		LenderName:     strings.TrimSpace(lenderName),
+		LenderMSP:      lenderMSP,
+		Amount:         securedAmount,
+		DocumentHash:   documentHash,
+		Status:         EncumbranceActive,
+		RegisteredAt:   txTime.Format(time.RFC3339),
+		RegisteredBy:   clientID,
+		RegisteredTxID: txID,
+	}
+
+	if err := putEncumbrance(ctx, encumbrance); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitEncumbranceEvent(ctx, EventEncumbranceRecorded, encumbrance, ""); err != nil {
+		fmt.Printf("warning: failed to emit EncumbranceEvent: %v\n", err)
+	}
+
+	return encumbrance, nil
+}
+
+// ReleaseEncumbrance discharges an active encumbrance on repayment
+// Requires 'bank_officer' role from the lender's MSP
+func (c *LandRegistryContract) ReleaseEncumbrance(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	encumbranceID string,
+	releaseReference string,
+) (*Encumbrance, error) {
+	if err := requireRole(ctx, "bank_officer"); err != nil {
+		return nil, fmt.Errorf("only bank officers can release encumbrances: %v", err)
+	}
+	if strings.TrimSpace(releaseReference) == "" {
+		return nil, fmt.Errorf("a release reference is required")
+	}
+
+	encumbrance, err := getLenderEncumbrance(ctx, propertyID, encumbranceID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	encumbrance.Status = EncumbranceReleased
+	encumbrance.ReleasedAt = txTime.Format(time.RFC3339)
+	encumbrance.ReleaseReference = releaseReference
+	encumbrance.ReleasedTxID = ctx.GetStub().GetTxID()
+	encumbrance.ReleasedBy = clientID
+
+	if err := putEncumbrance(ctx, encumbrance); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitEncumbranceEvent(ctx, EventEncumbranceReleased, encumbrance, ""); err != nil {
+		fmt.Printf("warning: failed to emit EncumbranceEvent: %v\n", err)
+	}
+
+	return encumbrance, nil
+}
+
+// ConsentToTransfer records the lender's consent for one transfer to proceed
+// while the encumbrance is still active (e.g. buyer's bank takes over the loan)
+// Requires 'bank_officer' role from the lender's MSP
+func (c *LandRegistryContract) ConsentToTransfer(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	encumbranceID string,
+	transferID string,
+) (*Encumbrance, error) {
+	if err := requireRole(ctx, "bank_officer"); err != nil {
+		return nil, fmt.Errorf("only bank officers can consent to transfers: %v", err)
+	}
+
+	encumbrance, err := getLenderEncumbrance(ctx, propertyID, encumbranceID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer, err := getTransferRequest(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.PropertyID != propertyID {
+		return nil, fmt.Errorf("transfer %s is not for property %s", transferID, propertyID)
+	}
+	if !isOpenTransfer(transfer.Status) {
+		return nil, fmt.Errorf("transfer %s is already %s", transferID, transfer.Status)
+	}
+
+	encumbrance.ConsentTransferID = transferID
+	if err := putEncumbrance(ctx, encumbrance); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitEncumbranceEvent(ctx, EventEncumbranceConsent, encumbrance, transferID); err != nil {
+		fmt.Printf("warning: failed to emit EncumbranceEvent: %v\n", err)
+	}
+
+	return encumbrance, nil
+}
+
+// GetEncumbrances returns every encumbrance ever recorded against a property
+func (c *LandRegistryContract) GetEncumbrances(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) ([]*Encumbrance, error) {
+	return listEncumbrances(ctx, propertyID)
+}
+
+// GetEncumbranceCertificate returns the charges that subsisted on a property
+// at any point between fromDate and toDate (inclusive, YYYY-MM-DD)
+func (c *LandRegistryContract) GetEncumbranceCertificate(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	fromDate string,
+	toDate string,
+) (*EncumbranceCertificate, error) {
+	from, err := time.Parse("2006-01-02", fromDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fromDate %q: %v", fromDate, err)
+	}
+	to, err := time.Parse("2006-01-02", toDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid toDate %q: %v", toDate, err)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("toDate %s is before fromDate %s", toDate, fromDate)
+	}
+	// Make the range inclusive of the whole final day
+	rangeEnd := to.Add(24 * time.Hour)
+
+	if _, err := getLandRecord(ctx, propertyID); err != nil {
+		return nil, err
+	}
+
+	encumbrances, err := listEncumbrances(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	charges := []*Encumbrance{}
+	for _, encumbrance := range encumbrances {
+		registeredAt, err := time.Parse(time.RFC3339, encumbrance.RegisteredAt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registeredAt on %s: %v", encumbrance.EncumbranceID, err)
+		}
+		if !registeredAt.Before(rangeEnd) {
+			continue
+		}
+		if encumbrance.Status == EncumbranceReleased {
+			releasedAt, err := time.Parse(time.RFC3339, encumbrance.ReleasedAt)
+			if err != nil {
+				return nil, fmt.Errorf("invalid releasedAt on %s: %v", encumbrance.EncumbranceID, err)
+			}
+			if releasedAt.Before(from) {
+				continue
+			}
+		}
+		charges = append(charges, encumbrance)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncumbranceCertificate{
+		PropertyID:  propertyID,
+		FromDate:    fromDate,
+		ToDate:      toDate,
+		GeneratedAt: txTime.Format(time.RFC3339),
+		NilEntries:  len(charges) == 0,
+		Charges:     charges,
+	}, nil
+}
+
+// checkEncumbrancesCleared blocks a transfer while any active charge lacks lender consent
+func checkEncumbrancesCleared(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	transferID string,
+) error {
+	encumbrances, err := listEncumbrances(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+
+	for _, encumbrance := range encumbrances {
+		if encumbrance.Status != EncumbranceActive {
+			continue
+		}
+		if encumbrance.ConsentTransferID != transferID {
+			return fmt.Errorf(
+				"property %s has an active %s (%s) held by %s without consent for transfer %s",
+				propertyID, encumbrance.Type, encumbrance.EncumbranceID, encumbrance.LenderName, transferID,
+			)
+		}
+	}
+	return nil
+}
+
+// getLenderEncumbrance loads an active encumbrance and checks the caller belongs to the lender's MSP
+func getLenderEncumbrance(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	encumbranceID string,
+) (*Encumbrance, error) {
+	encumbrance, err := getEncumbrance(ctx, propertyID, encumbranceID)
+	if err != nil {
+		return nil, err
+	}
+	if encumbrance.Status != EncumbranceActive {
+		return nil, fmt.Errorf("encumbrance %s is already %s", encumbranceID, encumbrance.Status)
+	}
+	if err := requireMSP(ctx, encumbrance.LenderMSP); err != nil {
+		return nil, fmt.Errorf("only the lender can act on encumbrance %s: %v", encumbranceID, err)
+	}
+	return encumbrance, nil
+}
+
+// encumbranceIDFromTx derives the encumbrance ID from the registering tx
+// A tx registers one encumbrance, so the untruncated tx ID keeps IDs unique.
+func encumbranceIDFromTx(txID string) string {
+	return "ENC-" + txID
+}
+
+func getEncumbrance(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	encumbranceID string,
+) (*Encumbrance, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(DocTypeEncumbrance, []string{propertyID, encumbranceID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create encumbrance key: %v", err)
+	}
+
+	encumbranceJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encumbrance: %v", err)
+	}
+	if encumbranceJSON == nil {
+		return nil, fmt.Errorf("encumbrance %s does not exist on %s", encumbranceID, propertyID)
+	}
+
+	var encumbrance Encumbrance
+	if err := json.Unmarshal(encumbranceJSON, &encumbrance); err != nil {
+		return nil, fmt.Errorf("failed to parse encumbrance: %v", err)
+	}
+	return &encumbrance, nil
+}
+
+func putEncumbrance(ctx contractapi.TransactionContextInterface, encumbrance *Encumbrance) error {
+	encumbrance.DocType = DocTypeEncumbrance
+	key, err := ctx.GetStub().CreateCompositeKey(
+		DocTypeEncumbrance,
+		[]string{encumbrance.PropertyID, encumbrance.EncumbranceID},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create encumbrance key: %v", err)
+	}
+
+	encumbranceJSON, err := json.Marshal(encumbrance)
+	if err != nil {
+		return fmt.Errorf("failed to marshal encumbrance: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, encumbranceJSON); err != nil {
+		return fmt.Errorf("failed to store encumbrance: %v", err)
+	}
+	return nil
+}
+
+func listEncumbrances(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) ([]*Encumbrance, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocTypeEncumbrance, []string{propertyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query encumbrances: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	encumbrances := []*Encumbrance{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var encumbrance Encumbrance
+		if err := json.Unmarshal(queryResponse.Value, &encumbrance); err != nil {
+			return nil, fmt.Errorf("failed to parse encumbrance: %v", err)
+		}
+		encumbrances = append(encumbrances, &encumbrance)
+	}
+
+	return encumbrances, nil
+}
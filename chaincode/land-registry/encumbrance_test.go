@@ -0,0 +1,118 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestEncumbranceBlocksTransferUntilLenderConsents(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, arjunID := s.personIDs["ravi"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+	sbiOfficer := testCreator(t, "SBIMSP", "bank_officer")
+	hdfcOfficer := testCreator(t, "HDFCMSP", "bank_officer")
+
+	recordCharge := func(txID string, creator []byte, encumbranceType string) (endorsement, error) {
+		return s.step(txID, creator, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.RecordEncumbrance(ctx, "CCLB-2025-TS-000001", encumbranceType, "State Bank of India", "20 L", testDeedHash)
+		})
+	}
+	if _, err := recordCharge("tx-pledge", sbiOfficer, "PLEDGE"); err == nil || !strings.Contains(err.Error(), "invalid encumbrance type") {
+		t.Fatalf("unknown encumbrance type: err = %v, want it refused", err)
+	}
+	recorded, err := recordCharge("tx-mortgage", sbiOfficer, "mortgage")
+	if err != nil {
+		t.Fatalf("mortgage registration failed: %v", err)
+	}
+	mortgage := recorded.result.(*Encumbrance)
+	if mortgage.Type != EncumbranceMortgage || mortgage.LenderMSP != "SBIMSP" || mortgage.Amount.Paise != 2000000*100 {
+		t.Fatalf("mortgage recorded as %+v", mortgage)
+	}
+
+	transfer := s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+	}).(*TransferRequest)
+	if _, err := recordCharge("tx-lien", hdfcOfficer, EncumbranceLien); err == nil || !strings.Contains(err.Error(), "open transfer") {
+		t.Fatalf("lien during an open transfer: err = %v, want it refused", err)
+	}
+
+	s.must("tx-accept", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.AcceptTransfer(ctx, transfer.TransferID)
+	})
+	s.must("tx-verify", s.subRegistrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.VerifyTransferDocuments(ctx, transfer.TransferID, "1.5 L", "SD-2025-0001")
+	})
+	approve := func(txID string) error {
+		_, err := s.step(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.ApproveTransfer(ctx, transfer.TransferID)
+		})
+		return err
+	}
+	if err := approve("tx-approve-charged"); err == nil || !strings.Contains(err.Error(), "without consent") {
+		t.Fatalf("approval over an active mortgage: err = %v, want a consent error", err)
+	}
+
+	consent := func(txID string, creator []byte) error {
+		_, err := s.step(txID, creator, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.ConsentToTransfer(ctx, "CCLB-2025-TS-000001", mortgage.EncumbranceID, transfer.TransferID)
+		})
+		return err
+	}
+	if err := consent("tx-consent-stranger", hdfcOfficer); err == nil || !strings.Contains(err.Error(), "only the lender") {
+		t.Fatalf("consent by another bank: err = %v, want it refused", err)
+	}
+	if err := consent("tx-consent", sbiOfficer); err != nil {
+		t.Fatalf("lender consent failed: %v", err)
+	}
+	if err := approve("tx-approve"); err != nil {
+		t.Fatalf("approval with the lender's consent failed: %v", err)
+	}
+
+	release := func(txID string, reference string) (endorsement, error) {
+		return s.step(txID, sbiOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.ReleaseEncumbrance(ctx, "CCLB-2025-TS-000001", mortgage.EncumbranceID, reference)
+		})
+	}
+	s.txTime = time.Date(2025, 6, 10, 10, 30, 0, 0, time.UTC)
+	if _, err := release("tx-release-blank", " "); err == nil || !strings.Contains(err.Error(), "release reference") {
+		t.Fatalf("release without a reference: err = %v, want it refused", err)
+	}
+	released, err := release("tx-release", "LOAN-CLOSED-7781")
+	if err != nil {
+		t.Fatalf("release failed: %v", err)
+	}
+	if discharged := released.result.(*Encumbrance); discharged.Status != EncumbranceReleased || discharged.ReleaseReference != "LOAN-CLOSED-7781" {
+		t.Fatalf("released encumbrance %+v", discharged)
+	}
+	if _, err := release("tx-release-again", "LOAN-CLOSED-7781"); err == nil || !strings.Contains(err.Error(), "already") {
+		t.Fatalf("second release: err = %v, want it refused", err)
+	}
+
+	certificates := []struct {
+		from    string
+		to      string
+		charges int
+	}{
+		{"2025-01-01", "2025-02-28", 0}, // Before the mortgage
+		{"2025-03-01", "2025-03-01", 1}, // The day it was registered
+		{"2025-01-01", "2025-12-31", 1},
+		{"2025-06-10", "2025-06-30", 1}, // The day it was released
+		{"2025-06-11", "2025-12-31", 0},
+	}
+	for _, tc := range certificates {
+		certificate, err := s.contract.GetEncumbranceCertificate(s.peer.ctx, "CCLB-2025-TS-000001", tc.from, tc.to)
+		if err != nil {
+			t.Fatalf("certificate for %s to %s failed: %v", tc.from, tc.to, err)
+		}
+		if len(certificate.Charges) != tc.charges || certificate.NilEntries != (tc.charges == 0) {
+			t.Fatalf("certificate for %s to %s lists %d charges (nil entries %v), want %d",
+				tc.from, tc.to, len(certificate.Charges), certificate.NilEntries, tc.charges)
+		}
+	}
+	if _, err := s.contract.GetEncumbranceCertificate(s.peer.ctx, "CCLB-2025-TS-000001", "2025-12-31", "2025-01-01"); err == nil {
+		t.Fatalf("certificate with the dates reversed was issued")
+	}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// endorser simulates one peer executing proposals against its own world state
+type endorser struct {
+	stub      *shimtest.MockStub
+	ctx       *contractapi.TransactionContext
+	published [][]byte // Payload of every event from an endorsed proposal, in order
+}
+
+func newEndorser(name string) *endorser {
+	stub := shimtest.NewMockStub(name, nil)
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(&privateDataStub{stub})
+	return &endorser{stub: stub, ctx: ctx}
+}
+
+// privateDataStub fills in the private data calls MockStub leaves unimplemented
+type privateDataStub struct {
+	*shimtest.MockStub
+}
+
+func (s *privateDataStub) DelPrivateData(collection string, key string) error {
+	delete(s.PvtState[collection], key)
+	return nil
+}
+
+func (s *privateDataStub) GetPrivateDataByPartialCompositeKey(collection string, objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := s.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	iterator := &kvIterator{}
+	for key, value := range s.PvtState[collection] {
+		if strings.HasPrefix(key, prefix) {
+			iterator.kvs = append(iterator.kvs, &queryresult.KV{Key: key, Value: value})
+		}
+	}
+	sort.Slice(iterator.kvs, func(i, j int) bool { return iterator.kvs[i].Key < iterator.kvs[j].Key })
+	return iterator, nil
+}
+
+// kvIterator iterates over a fixed, sorted set of key/value pairs
+type kvIterator struct {
+	kvs []*queryresult.KV
+}
+
+func (it *kvIterator) HasNext() bool {
+	return len(it.kvs) > 0
+}
+
+func (it *kvIterator) Next() (*queryresult.KV, error) {
+	kv := it.kvs[0]
+	it.kvs = it.kvs[1:]
+	return kv, nil
+}
+
+func (it *kvIterator) Close() error {
+	return nil
+}
+
+// proposal is one transaction as submitted by a client to every endorser
+type proposal struct {
+	txID      string
+	creator   []byte
+	time      time.Time
+	transient map[string][]byte
+	invoke    func(ctx contractapi.TransactionContextInterface) (interface{}, error)
+}
+
+// endorsement is what a peer returns: the result, resulting state and events
+type endorsement struct {
+	result interface{}
+	state  map[string][]byte
+	events map[string][]byte
+}
+
+func (e *endorser) endorse(t *testing.T, p proposal) endorsement {
+	t.Helper()
+
+	endorsed, err := e.submit(t, p)
+	if err != nil {
+		t.Fatalf("%s on %s failed: %v", p.txID, e.stub.Name, err)
+	}
+	return endorsed
+}
+
+// submit executes a proposal, returning the chaincode error instead of failing
+func (e *endorser) submit(t *testing.T, p proposal) (endorsement, error) {
+	t.Helper()
+
+	e.stub.MockTransactionStart(p.txID)
+	e.stub.TxTimestamp = timestamppb.New(p.time)
+	e.stub.Creator = p.creator
+	if err := e.stub.SetTransient(p.transient); err != nil {
+		t.Fatalf("failed to set transient data: %v", err)
+	}
+	clientIdentity, err := cid.New(e.stub)
+	if err != nil {
+		t.Fatalf("failed to load client identity: %v", err)
+	}
+	e.ctx.SetClientIdentity(clientIdentity)
+
+	result, err := p.invoke(e.ctx)
+	e.stub.MockTransactionEnd(p.txID)
+	if err != nil {
+		return endorsement{}, err
+	}
+
+	state := map[string][]byte{}
+	for key, value := range e.stub.State {
+		state[key] = append([]byte(nil), value...)
+	}
+	events := map[string][]byte{}
+	for len(e.stub.ChaincodeEventsChannel) > 0 {
+		event := <-e.stub.ChaincodeEventsChannel
+		events[event.EventName] = event.Payload
+		e.published = append(e.published, event.Payload)
+	}
+	return endorsement{result: result, state: state, events: events}, nil
+}
+
+// endorseOnBoth runs a proposal on two peers and fails unless they agree
+// The second peer runs later in wall-clock time, as it would on a real network.
+func endorseOnBoth(t *testing.T, peers [2]*endorser, p proposal) endorsement {
+	t.Helper()
+
+	first := peers[0].endorse(t, p)
+	time.Sleep(10 * time.Millisecond)
+	second := peers[1].endorse(t, p)
+
+	if !reflect.DeepEqual(first.result, second.result) {
+		t.Fatalf("%s: endorsers returned different results: %v vs %v", p.txID, first.result, second.result)
+	}
+	if !reflect.DeepEqual(first.state, second.state) {
+		t.Fatalf("%s: endorsers produced different write sets", p.txID)
+	}
+	if !reflect.DeepEqual(first.events, second.events) {
+		t.Fatalf("%s: endorsers emitted different events: %s vs %s", p.txID, first.events, second.events)
+	}
+	return first
+}
+
+// testCreator builds a serialized identity carrying a Fabric CA role attribute
+func testCreator(t *testing.T, mspID string, role string) []byte {
+	t.Helper()
+	return testCreatorWithAttrs(t, mspID, map[string]string{"role": role})
+}
+
+// testCreatorWithAttrs builds a serialized identity carrying Fabric CA attributes
+// The hf.EnrollmentID attribute, if set, names the identity instead of its role.
+func testCreatorWithAttrs(t *testing.T, mspID string, attributes map[string]string) []byte {
+	t.Helper()
+
+	name := attributes["role"]
+	if enrollmentID, ok := attributes["hf.EnrollmentID"]; ok {
+		name = enrollmentID
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	attrs, err := json.Marshal(map[string]map[string]string{"attrs": attributes})
+	if err != nil {
+		t.Fatalf("failed to marshal attributes: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name + "@" + mspID},
+		NotBefore:    time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:     time.Date(2040, 1, 1, 0, 0, 0, 0, time.UTC),
+		ExtraExtensions: []pkix.Extension{{
+			Id:    asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}, // Fabric CA attributes
+			Value: attrs,
+		}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	creator, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal identity: %v", err)
+	}
+	return creator
+}
+
+func TestRequestPropertyIDIsDeterministicAcrossEndorsers(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peers := [2]*endorser{newEndorser("peer0"), newEndorser("peer1")}
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	txTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	endorsed := endorseOnBoth(t, peers, proposal{
+		txID:    "tx-request",
+		creator: registrar,
+		time:    txTime,
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.RequestPropertyID(ctx, "TS", "Ravi Kumar", "101/A", "Rangareddy",
+				"Shamshabad", "Kothur", "2 acres", "agricultural", "45 L", "")
+		},
+	})
+
+	requestID := endorsed.result.(string)
+	if want := "REQ-TS-tx-request"; requestID != want {
+		t.Fatalf("request ID = %s, want %s", requestID, want)
+	}
+
+	var event PropertyIDRequestedEvent
+	if err := json.Unmarshal(endorsed.events["PropertyIDRequested"], &event); err != nil {
+		t.Fatalf("failed to parse event: %v", err)
+	}
+	if event.Timestamp != "2025-03-01T10:30:00Z" {
+		t.Fatalf("event timestamp = %s, want the transaction time", event.Timestamp)
+	}
+
+	draftKey, _ := peers[0].stub.CreateCompositeKey(DocTypeDraft, []string{requestID})
+	var draft LandRecord
+	if err := json.Unmarshal(endorsed.state[draftKey], &draft); err != nil {
+		t.Fatalf("failed to parse draft: %v", err)
+	}
+	if draft.LastUpdated != "2025-03-01" {
+		t.Fatalf("draft lastUpdated = %s, want 2025-03-01", draft.LastUpdated)
+	}
+}
+
+func TestStateRecordLifecycleIsDeterministicAcrossEndorsers(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peers := [2]*endorser{newEndorser("peer0"), newEndorser("peer1")}
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	txTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	requested := endorseOnBoth(t, peers, proposal{
+		txID:    "tx-request",
+		creator: registrar,
+		time:    txTime,
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.RequestPropertyID(ctx, "TS", "Ravi Kumar", "101/A", "Rangareddy",
+				"Shamshabad", "Kothur", "2 acres", "agricultural", "45 L", "")
+		},
+	})
+	requestID := requested.result.(string)
+
+	created := endorseOnBoth(t, peers, proposal{
+		txID:    "tx-create",
+		creator: registrar,
+		time:    txTime.Add(48 * time.Hour),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateStateRecord(ctx, "CCLB-2025-TS-000001", requestID, "")
+		},
+	})
+	if record := created.result.(*LandRecord); record.LastUpdated != "2025-03-03" {
+		t.Fatalf("record lastUpdated = %s, want 2025-03-03", record.LastUpdated)
+	}
+
+	var event StateRecordCreatedEvent
+	if err := json.Unmarshal(created.events["StateRecordCreated"], &event); err != nil {
+		t.Fatalf("failed to parse event: %v", err)
+	}
+	if event.Timestamp != "2025-03-03T10:30:00Z" {
+		t.Fatalf("event timestamp = %s, want the transaction time", event.Timestamp)
+	}
+
+	linked := endorseOnBoth(t, peers, proposal{
+		txID:    "tx-link",
+		creator: registrar,
+		time:    txTime.Add(72 * time.Hour),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return nil, contract.LinkDocumentHash(ctx, "CCLB-2025-TS-000001",
+				"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", "SALE_DEED")
+		},
+	})
+
+	var linkedEvent DocumentLinkedEvent
+	if err := json.Unmarshal(linked.events[EventDocumentLinked], &linkedEvent); err != nil {
+		t.Fatalf("failed to parse event: %v", err)
+	}
+	if linkedEvent.Timestamp != txTime.Add(72*time.Hour).Unix() {
+		t.Fatalf("event timestamp = %d, want the transaction time", linkedEvent.Timestamp)
+	}
+}
+
+func TestGeneratePropertyIDUsesTransactionYear(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peers := [2]*endorser{newEndorser("peer0"), newEndorser("peer1")}
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+
+	// The last second of a year must not roll over on a peer whose clock is ahead
+	yearEnd := time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC)
+	generated := endorseOnBoth(t, peers, proposal{
+		txID:    "tx-generate",
+		creator: registrar,
+		time:    yearEnd,
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.GeneratePropertyID(ctx, "Telangana")
+		},
+	})
+	if propertyID := generated.result.(string); propertyID != "LRI-IND-TS-2025-000001" {
+		t.Fatalf("property ID = %s, want LRI-IND-TS-2025-000001", propertyID)
+	}
+
+	nextYear := endorseOnBoth(t, peers, proposal{
+		txID:    "tx-generate-next",
+		creator: registrar,
+		time:    yearEnd.Add(time.Second),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.GeneratePropertyID(ctx, "TS")
+		},
+	})
+	if propertyID := nextYear.result.(string); propertyID != "LRI-IND-TS-2026-000001" {
+		t.Fatalf("property ID = %s, want LRI-IND-TS-2026-000001", propertyID)
+	}
+}
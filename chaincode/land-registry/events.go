@@ -3,7 +3,6 @@ package main
 import (
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -15,12 +14,50 @@ const (
 	EventPropertyApproved    = "PropertyApproved"
 	EventPropertyUpdated     = "PropertyUpdated"
 	EventDocumentLinked      = "DocumentLinked"
+
+	EventTransferInitiated = "TransferInitiated"
+	EventTransferAccepted  = "TransferAccepted"
+	EventTransferVerified  = "TransferVerified"
+	EventTransferRejected  = "TransferRejected"
+	EventTransferCancelled = "TransferCancelled"
+	EventTransferExpired   = "TransferExpired"
+	EventTransferConsent   = "TransferCoOwnerConsent"
+
+	EventEncumbranceRecorded = "EncumbranceRecorded"
+	EventEncumbranceReleased = "EncumbranceReleased"
+	EventEncumbranceConsent  = "EncumbranceConsentGiven"
+
+	EventLeaseRegistered = "LeaseRegistered"
+	EventLeaseRenewed    = "LeaseRenewed"
+	EventLeaseTerminated = "LeaseTerminated"
+
+	EventCourtOrderRecorded = "CourtOrderRecorded"
+	EventCourtOrderLifted   = "CourtOrderLifted"
+	EventCourtOrderExpired  = "CourtOrderExpired"
+
+	EventTaxAssessed = "TaxAssessed"
+	EventTaxPaid     = "TaxPaid"
+
+	EventMutationObjectionFiled = "MutationObjectionFiled"
+	EventMutationApproved       = "MutationApproved"
+	EventMutationRejected       = "MutationRejected"
+
+	EventDeathRegistered          = "DeathRegistered"
+	EventHeirsDeclared            = "HeirsDeclared"
+	EventSuccessionObjectionFiled = "SuccessionObjectionFiled"
+	EventSuccessionDevolved       = "SuccessionDevolved"
+	EventSuccessionRejected       = "SuccessionRejected"
+
+	EventPropertySubdivided    = "PropertySubdivided"
+	EventPropertiesAmalgamated = "PropertiesAmalgamated"
+
+	EventDraftCancelled = "DraftCancelled"
+	EventDraftExpired   = "DraftExpired"
 )
 
 // PropertyCreatedEvent emitted when a new property is registered
 type PropertyCreatedEvent struct {
 	PropertyID    string `json:"propertyId"`
-	Owner         string `json:"owner"`
 	District      string `json:"district"`
 	Mandal        string `json:"mandal"`
 	Village       string `json:"village"`
@@ -30,14 +67,27 @@ type PropertyCreatedEvent struct {
 	TransactionID string `json:"transactionId"`
 }
 
-// PropertyTransferredEvent emitted when ownership changes
+// PropertyTransferredEvent emitted when a conveyance completes
+// Fabric keeps only the last SetEvent of a transaction, so one event lists
+// every parcel that changed hands: both parcels of an exchange, and the child
+// parcels of a partition.
 type PropertyTransferredEvent struct {
+	TransferID        string               `json:"transferId"`
+	DeedType          string               `json:"deedType"`          // See Deed* types
+	StampDutyCategory string               `json:"stampDutyCategory"` // See StampDuty* categories
+	Conveyances       []PropertyConveyance `json:"conveyances"`
+	ApprovalStatus    string               `json:"approvalStatus"`
+	Timestamp         int64                `json:"timestamp"`
+	TransactionID     string               `json:"transactionId"`
+}
+
+// PropertyConveyance is one parcel changing hands in a PropertyTransferredEvent
+type PropertyConveyance struct {
 	PropertyID     string `json:"propertyId"`
-	FromOwner      string `json:"fromOwner"`
-	ToOwner        string `json:"toOwner"`
-	ApprovalStatus string `json:"approvalStatus"`
-	Timestamp      int64  `json:"timestamp"`
-	TransactionID  string `json:"transactionId"`
+	FromPersonID   string `json:"fromPersonId,omitempty"` // Empty for a partition's new child parcels
+	ToPersonID     string `json:"toPersonId"`
+	Share          string `json:"share"`
+	MutationCaseID string `json:"mutationCaseId,omitempty"` // Revenue mutation notice for the parcel
 }
 
 // PropertyApprovedEvent emitted when registrar approves a transaction
@@ -67,11 +117,124 @@ type DocumentLinkedEvent struct {
 	TransactionID string `json:"transactionId"`
 }
 
+// TransferStatusChangedEvent emitted at every stage of a TransferRequest
+type TransferStatusChangedEvent struct {
+	TransferID         string `json:"transferId"`
+	PropertyID         string `json:"propertyId"`
+	SellerID           string `json:"sellerId"` // PERSON_ IDs; names stay private
+	BuyerID            string `json:"buyerId"`
+	Share              string `json:"share,omitempty"` // Fraction of the parcel changing hands
+	DeedType           string `json:"deedType"`
+	StampDutyCategory  string `json:"stampDutyCategory"`
+	ExchangePropertyID string `json:"exchangePropertyId,omitempty"` // EXCHANGE: the buyer's parcel
+	PreviousStatus     string `json:"previousStatus,omitempty"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Timestamp          int64  `json:"timestamp"`
+	TransactionID      string `json:"transactionId"`
+}
+
+// EncumbranceEvent emitted when a charge is recorded, released or consented
+type EncumbranceEvent struct {
+	EncumbranceID string `json:"encumbranceId"`
+	PropertyID    string `json:"propertyId"`
+	Type          string `json:"type"`
+	LenderName    string `json:"lenderName"`
+	Status        string `json:"status"`
+	TransferID    string `json:"transferId,omitempty"`
+	Timestamp     int64  `json:"timestamp"`
+	TransactionID string `json:"transactionId"`
+}
+
+// LeaseEvent emitted when a lease is registered, renewed or terminated
+type LeaseEvent struct {
+	LeaseID       string `json:"leaseId"`
+	PropertyID    string `json:"propertyId"`
+	Portion       string `json:"portion,omitempty"`
+	LesseeID      string `json:"lesseeId"`
+	StartDate     string `json:"startDate"`
+	EndDate       string `json:"endDate"` // Last day, after any early termination
+	Status        string `json:"status"`
+	Note          string `json:"note,omitempty"`
+	Timestamp     int64  `json:"timestamp"`
+	TransactionID string `json:"transactionId"`
+}
+
+// CourtOrderEvent emitted when a court order freezing a property is recorded, lifted or expires
+type CourtOrderEvent struct {
+	OrderID       string `json:"orderId"`
+	PropertyID    string `json:"propertyId"`
+	Court         string `json:"court"`
+	CaseNumber    string `json:"caseNumber"`
+	OrderType     string `json:"orderType"`
+	Status        string `json:"status"`
+	Timestamp     int64  `json:"timestamp"`
+	TransactionID string `json:"transactionId"`
+}
+
+// TaxEvent emitted when tax is assessed or a payment is recorded
+type TaxEvent struct {
+	PropertyID       string `json:"propertyId"`
+	FiscalYear       string `json:"fiscalYear"`
+	TaxDuePaise      int64  `json:"taxDuePaise"`
+	PaidPaise        int64  `json:"paidPaise"`
+	PaymentPaise     int64  `json:"paymentPaise,omitempty"`
+	ReceiptRef       string `json:"receiptRef,omitempty"`
+	AssessmentStatus string `json:"assessmentStatus"`
+	Timestamp        int64  `json:"timestamp"`
+	TransactionID    string `json:"transactionId"`
+}
+
+// MutationEvent emitted at every stage of a MutationCase
+type MutationEvent struct {
+	CaseID        string `json:"caseId"`
+	PropertyID    string `json:"propertyId"`
+	TransferID    string `json:"transferId"`
+	FromPersonID  string `json:"fromPersonId"`
+	ToPersonID    string `json:"toPersonId"`
+	Status        string `json:"status"`
+	NoticeEndsAt  string `json:"noticeEndsAt"`
+	Note          string `json:"note,omitempty"`
+	Timestamp     int64  `json:"timestamp"`
+	TransactionID string `json:"transactionId"`
+}
+
+// SuccessionEvent emitted at every stage of a SuccessionCase
+type SuccessionEvent struct {
+	CaseID        string   `json:"caseId"`
+	PersonID      string   `json:"personId"`
+	PropertyIDs   []string `json:"propertyIds"`
+	Status        string   `json:"status"`
+	NoticeEndsAt  string   `json:"noticeEndsAt,omitempty"`
+	Note          string   `json:"note,omitempty"`
+	Timestamp     int64    `json:"timestamp"`
+	TransactionID string   `json:"transactionId"`
+}
+
+// LineageEvent emitted when parcels are subdivided or amalgamated
+type LineageEvent struct {
+	OperationID   string   `json:"operationId"`
+	Type          string   `json:"type"`
+	ParentIDs     []string `json:"parentIds"`
+	ChildIDs      []string `json:"childIds"`
+	Timestamp     int64    `json:"timestamp"`
+	TransactionID string   `json:"transactionId"`
+}
+
+// DraftEvent emitted when a Property ID request draft is cancelled or expires
+type DraftEvent struct {
+	RequestID     string `json:"requestId"`
+	StateCode     string `json:"stateCode"`
+	Status        string `json:"status"`
+	Reason        string `json:"reason,omitempty"`
+	Timestamp     int64  `json:"timestamp"`
+	TransactionID string `json:"transactionId"`
+}
+
 // EmitPropertyCreatedEvent publishes property creation event
 func (c *LandRegistryContract) emitPropertyCreatedEvent(
 	ctx contractapi.TransactionContextInterface,
 	propertyID string,
-	owner string,
 	district string,
 	mandal string,
 	village string,
@@ -79,16 +242,20 @@ func (c *LandRegistryContract) emitPropertyCreatedEvent(
 	area string,
 ) error {
 
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	txID := ctx.GetStub().GetTxID()
 	event := PropertyCreatedEvent{
 		PropertyID:    propertyID,
-		Owner:         owner,
 		District:      district,
 		Mandal:        mandal,
 		Village:       village,
 		SurveyNo:      surveyNo,
 		Area:          area,
-		Timestamp:     time.Now().Unix(),
+		Timestamp:     txTime.Unix(),
 		TransactionID: txID,
 	}
 
@@ -103,20 +270,50 @@ func (c *LandRegistryContract) emitPropertyCreatedEvent(
 // EmitPropertyTransferredEvent publishes ownership transfer event
 func (c *LandRegistryContract) emitPropertyTransferredEvent(
 	ctx contractapi.TransactionContextInterface,
-	propertyID string,
-	fromOwner string,
-	toOwner string,
-	approvalStatus string,
+	transfer *TransferRequest,
 ) error {
 
-	txID := ctx.GetStub().GetTxID()
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	conveyances := []PropertyConveyance{}
+	if transfer.DeedType == DeedPartition {
+		for _, allotment := range transfer.Allotments {
+			conveyances = append(conveyances, PropertyConveyance{
+				PropertyID: allotment.PropertyID,
+				ToPersonID: allotment.AllotteeID,
+				Share:      "1/1",
+			})
+		}
+	} else {
+		conveyances = append(conveyances, PropertyConveyance{
+			PropertyID:     transfer.PropertyID,
+			FromPersonID:   transfer.SellerID,
+			ToPersonID:     transfer.BuyerID,
+			Share:          transfer.Share,
+			MutationCaseID: transfer.MutationCaseID,
+		})
+	}
+	if transfer.DeedType == DeedExchange {
+		conveyances = append(conveyances, PropertyConveyance{
+			PropertyID:     transfer.ExchangePropertyID,
+			FromPersonID:   transfer.BuyerID,
+			ToPersonID:     transfer.SellerID,
+			Share:          transfer.ExchangeShare,
+			MutationCaseID: transfer.ExchangeMutationCaseID,
+		})
+	}
+
 	event := PropertyTransferredEvent{
-		PropertyID:     propertyID,
-		FromOwner:      fromOwner,
-		ToOwner:        toOwner,
-		ApprovalStatus: approvalStatus,
-		Timestamp:      time.Now().Unix(),
-		TransactionID:  txID,
+		TransferID:        transfer.TransferID,
+		DeedType:          transfer.DeedType,
+		StampDutyCategory: transfer.StampDutyCategory,
+		Conveyances:       conveyances,
+		ApprovalStatus:    transfer.Status,
+		Timestamp:         txTime.Unix(),
+		TransactionID:     ctx.GetStub().GetTxID(),
 	}
 
 	eventJSON, err := json.Marshal(event)
@@ -135,12 +332,17 @@ func (c *LandRegistryContract) emitPropertyApprovedEvent(
 	status string,
 ) error {
 
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	txID := ctx.GetStub().GetTxID()
 	event := PropertyApprovedEvent{
 		PropertyID:    propertyID,
 		ApprovedBy:    approvedBy,
 		Status:        status,
-		Timestamp:     time.Now().Unix(),
+		Timestamp:     txTime.Unix(),
 		TransactionID: txID,
 	}
 
@@ -160,12 +362,17 @@ func (c *LandRegistryContract) emitPropertyUpdatedEvent(
 	updatedBy string,
 ) error {
 
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	txID := ctx.GetStub().GetTxID()
 	event := PropertyUpdatedEvent{
 		PropertyID:    propertyID,
 		UpdatedFields: updatedFields,
 		UpdatedBy:     updatedBy,
-		Timestamp:     time.Now().Unix(),
+		Timestamp:     txTime.Unix(),
 		TransactionID: txID,
 	}
 
@@ -185,12 +392,17 @@ func (c *LandRegistryContract) emitDocumentLinkedEvent(
 	documentType string,
 ) error {
 
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	txID := ctx.GetStub().GetTxID()
 	event := DocumentLinkedEvent{
 		PropertyID:    propertyID,
 		DocumentHash:  documentHash,
 		DocumentType:  documentType,
-		Timestamp:     time.Now().Unix(),
+		Timestamp:     txTime.Unix(),
 		TransactionID: txID,
 	}
 
@@ -201,3 +413,296 @@ func (c *LandRegistryContract) emitDocumentLinkedEvent(
 
 	return ctx.GetStub().SetEvent(EventDocumentLinked, eventJSON)
 }
+
+// emitTransferEvent publishes a transfer workflow stage change
+func (c *LandRegistryContract) emitTransferEvent(
+	ctx contractapi.TransactionContextInterface,
+	eventName string,
+	transfer *TransferRequest,
+	previousStatus string,
+) error {
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := TransferStatusChangedEvent{
+		TransferID:         transfer.TransferID,
+		PropertyID:         transfer.PropertyID,
+		SellerID:           transfer.SellerID,
+		BuyerID:            transfer.BuyerID,
+		Share:              transfer.Share,
+		DeedType:           transfer.DeedType,
+		StampDutyCategory:  transfer.StampDutyCategory,
+		ExchangePropertyID: transfer.ExchangePropertyID,
+		PreviousStatus:     previousStatus,
+		Status:             transfer.Status,
+		Reason:             transfer.StatusReason,
+		Timestamp:          txTime.Unix(),
+		TransactionID:      ctx.GetStub().GetTxID(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal TransferStatusChangedEvent: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// emitEncumbranceEvent publishes an encumbrance lifecycle change
+func (c *LandRegistryContract) emitEncumbranceEvent(
+	ctx contractapi.TransactionContextInterface,
+	eventName string,
+	encumbrance *Encumbrance,
+	transferID string,
+) error {
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := EncumbranceEvent{
+		EncumbranceID: encumbrance.EncumbranceID,
+		PropertyID:    encumbrance.PropertyID,
+		Type:          encumbrance.Type,
+		LenderName:    encumbrance.LenderName,
+		Status:        encumbrance.Status,
+		TransferID:    transferID,
+		Timestamp:     txTime.Unix(),
+		TransactionID: ctx.GetStub().GetTxID(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal EncumbranceEvent: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// emitCourtOrderEvent publishes a court order lifecycle change
+func (c *LandRegistryContract) emitCourtOrderEvent(
+	ctx contractapi.TransactionContextInterface,
+	eventName string,
+	order *CourtOrder,
+) error {
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := CourtOrderEvent{
+		OrderID:       order.OrderID,
+		PropertyID:    order.PropertyID,
+		Court:         order.Court,
+		CaseNumber:    order.CaseNumber,
+		OrderType:     order.OrderType,
+		Status:        order.Status,
+		Timestamp:     txTime.Unix(),
+		TransactionID: ctx.GetStub().GetTxID(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal CourtOrderEvent: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// emitLeaseEvent publishes a lease lifecycle change
+func (c *LandRegistryContract) emitLeaseEvent(
+	ctx contractapi.TransactionContextInterface,
+	eventName string,
+	lease *Lease,
+	note string,
+) error {
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := LeaseEvent{
+		LeaseID:       lease.LeaseID,
+		PropertyID:    lease.PropertyID,
+		Portion:       lease.Portion,
+		LesseeID:      lease.LesseeID,
+		StartDate:     lease.StartDate,
+		EndDate:       lease.lastDay(),
+		Status:        lease.Status,
+		Note:          note,
+		Timestamp:     txTime.Unix(),
+		TransactionID: ctx.GetStub().GetTxID(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal LeaseEvent: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// emitTaxEvent publishes a tax assessment or payment
+func (c *LandRegistryContract) emitTaxEvent(
+	ctx contractapi.TransactionContextInterface,
+	eventName string,
+	assessment *TaxAssessment,
+	paymentPaise int64,
+	receiptRef string,
+) error {
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := TaxEvent{
+		PropertyID:       assessment.PropertyID,
+		FiscalYear:       assessment.FiscalYear,
+		TaxDuePaise:      assessment.TaxDuePaise,
+		PaidPaise:        assessment.PaidPaise,
+		PaymentPaise:     paymentPaise,
+		ReceiptRef:       receiptRef,
+		AssessmentStatus: assessment.Status,
+		Timestamp:        txTime.Unix(),
+		TransactionID:    ctx.GetStub().GetTxID(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal TaxEvent: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// emitMutationEvent publishes a mutation case stage change
+func (c *LandRegistryContract) emitMutationEvent(
+	ctx contractapi.TransactionContextInterface,
+	eventName string,
+	mutation *MutationCase,
+	note string,
+) error {
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := MutationEvent{
+		CaseID:        mutation.CaseID,
+		PropertyID:    mutation.PropertyID,
+		TransferID:    mutation.TransferID,
+		FromPersonID:  mutation.FromPersonID,
+		ToPersonID:    mutation.ToPersonID,
+		Status:        mutation.Status,
+		NoticeEndsAt:  mutation.NoticeEndsAt,
+		Note:          note,
+		Timestamp:     txTime.Unix(),
+		TransactionID: ctx.GetStub().GetTxID(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal MutationEvent: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// emitSuccessionEvent publishes a succession case stage change
+func (c *LandRegistryContract) emitSuccessionEvent(
+	ctx contractapi.TransactionContextInterface,
+	eventName string,
+	succession *SuccessionCase,
+	note string,
+) error {
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := SuccessionEvent{
+		CaseID:        succession.CaseID,
+		PersonID:      succession.PersonID,
+		PropertyIDs:   succession.PropertyIDs,
+		Status:        succession.Status,
+		NoticeEndsAt:  succession.NoticeEndsAt,
+		Note:          note,
+		Timestamp:     txTime.Unix(),
+		TransactionID: ctx.GetStub().GetTxID(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SuccessionEvent: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// emitLineageEvent publishes a subdivision or amalgamation
+func (c *LandRegistryContract) emitLineageEvent(
+	ctx contractapi.TransactionContextInterface,
+	eventName string,
+	operation *LineageOperation,
+) error {
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := LineageEvent{
+		OperationID:   operation.OperationID,
+		Type:          operation.Type,
+		ParentIDs:     operation.ParentIDs,
+		ChildIDs:      operation.ChildIDs,
+		Timestamp:     txTime.Unix(),
+		TransactionID: ctx.GetStub().GetTxID(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal LineageEvent: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
+
+// emitDraftEvent publishes a draft lifecycle change
+func (c *LandRegistryContract) emitDraftEvent(
+	ctx contractapi.TransactionContextInterface,
+	eventName string,
+	draft *LandRecord,
+	reason string,
+) error {
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := DraftEvent{
+		RequestID:     draft.RequestID,
+		StateCode:     draft.StateCode,
+		Status:        draft.Status,
+		Reason:        reason,
+		Timestamp:     txTime.Unix(),
+		TransactionID: ctx.GetStub().GetTxID(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal DraftEvent: %v", err)
+	}
+
+	return ctx.GetStub().SetEvent(eventName, eventJSON)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -26,6 +28,16 @@ type StateRecordCreatedEvent struct {
 	Mandal     string `json:"mandal"`
 	Village    string `json:"village"`
 	Timestamp  string `json:"timestamp"`
+	TxID       string `json:"txId"` // Passed to CCLB VerifyStateRecord as stateTxID
+}
+
+// CCLBVerificationRecordedEvent emitted when a CCLB verdict is applied to a state record
+type CCLBVerificationRecordedEvent struct {
+	PropertyID string `json:"propertyId"`
+	Status     string `json:"status"` // VERIFIED, REJECTED
+	Reason     string `json:"reason,omitempty"`
+	CCLBTxID   string `json:"cclbTxId"`
+	Timestamp  string `json:"timestamp"`
 }
 
 // emitPropertyIDRequestedEvent emits an event when state requests Property ID from CCLB
@@ -38,6 +50,11 @@ func (c *LandRegistryContract) emitPropertyIDRequestedEvent(
 	mandal string,
 	village string,
 ) error {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	event := PropertyIDRequestedEvent{
 		RequestID: requestID,
 		StateCode: stateCode,
@@ -45,7 +62,7 @@ func (c *LandRegistryContract) emitPropertyIDRequestedEvent(
 		District:  district,
 		Mandal:    mandal,
 		Village:   village,
-		Timestamp: "2026-01-26T00:00:00Z", // TODO: real timestamp
+		Timestamp: txTime.Format(time.RFC3339),
 	}
 
 	eventJSON, _ := json.Marshal(event)
@@ -62,6 +79,11 @@ func (c *LandRegistryContract) emitStateRecordCreatedEvent(
 	mandal string,
 	village string,
 ) error {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	event := StateRecordCreatedEvent{
 		PropertyID: propertyID,
 		StateCode:  stateCode,
@@ -69,9 +91,48 @@ func (c *LandRegistryContract) emitStateRecordCreatedEvent(
 		District:   district,
 		Mandal:     mandal,
 		Village:    village,
-		Timestamp:  "2026-01-26T00:00:00Z", // TODO: real timestamp
+		Timestamp:  txTime.Format(time.RFC3339),
+		TxID:       ctx.GetStub().GetTxID(),
 	}
 
 	eventJSON, _ := json.Marshal(event)
 	return ctx.GetStub().SetEvent("StateRecordCreated", eventJSON)
 }
+
+// emitCCLBVerificationRecordedEvent emits an event when a CCLB verdict is applied
+func (c *LandRegistryContract) emitCCLBVerificationRecordedEvent(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	status string,
+	reason string,
+	cclbTxID string,
+) error {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
+	event := CCLBVerificationRecordedEvent{
+		PropertyID: propertyID,
+		Status:     status,
+		Reason:     reason,
+		CCLBTxID:   cclbTxID,
+		Timestamp:  txTime.Format(time.RFC3339),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal CCLBVerificationRecordedEvent: %v", err)
+	}
+	return ctx.GetStub().SetEvent("CCLBVerificationRecorded", eventJSON)
+}
+
+// txTimestamp returns the transaction header timestamp
+// Identical on every endorsing peer, unlike time.Now()
+func txTimestamp(ctx contractapi.TransactionContextInterface) (time.Time, error) {
+	ts, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read transaction timestamp: %v", err)
+	}
+	return ts.AsTime().UTC(), nil
+}
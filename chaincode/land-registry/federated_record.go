@@ -1,8 +1,8 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
@@ -15,9 +15,16 @@ import (
 //  3. CreateStateRecord() — state binds full details to the Property ID
 //
 // This function:
-//   - Stores draft record locally (no Property ID yet)
+//   - Stores draft record locally (no Property ID yet, status PENDING_ID)
+//   - Draft can be cancelled, and expires if not bound within draftTimeout
 //   - Triggers CCLB to generate ID via cross-chain event/invoke
 //   - Returns temporary request ID for polling
+//
+// Owner, owner ID number, market value and a salt should be passed in the
+// "privateDetails" transient entry, leaving the owner and marketValue
+// arguments empty; the arguments remain for older clients but are recorded
+// in the block. The entry may also link the owner's PERSON_ ID, or list
+// co-owners with their shares and tenancy instead of a single owner.
 func (c *LandRegistryContract) RequestPropertyID(
 	ctx contractapi.TransactionContextInterface,
 	stateCode string,
@@ -36,34 +43,104 @@ func (c *LandRegistryContract) RequestPropertyID(
 		return "", fmt.Errorf("only registrars can request Property IDs: %v", err)
 	}
 
+	private, err := transientPrivateDetails(ctx)
+	if err != nil {
+		return "", err
+	}
+	if private != nil {
+		if owner != "" || marketValue != "" {
+			return "", fmt.Errorf("owner and market value must only be passed as private details")
+		}
+		owner, marketValue = private.Owner, private.MarketValue
+	} else {
+		private = &privateDetailsInput{}
+	}
+
+	owners := private.Owners
+	if len(owners) == 0 {
+		owners = soleOwner(owner, private.OwnerPersonID)
+	} else if owner != "" {
+		return "", fmt.Errorf("give either an owner or a list of co-owners, not both")
+	}
+	owners, tenancy, err := normalizeOwners(owners, private.Tenancy)
+	if err != nil {
+		return "", err
+	}
+	for _, coOwner := range owners {
+		if coOwner.PersonID == "" {
+			continue
+		}
+		if err := requireOwnerPerson(ctx, coOwner.PersonID, coOwner.Name); err != nil {
+			return "", err
+		}
+	}
+
+	parsedArea, err := parseArea(area)
+	if err != nil {
+		return "", err
+	}
+	parsedValue, err := parseRupees(marketValue)
+	if err != nil {
+		return "", fmt.Errorf("invalid market value: %v", err)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Request ID is derived from the tx ID, so concurrent requests never share a key
+	requestID := draftIDFromTx(stateCode, ctx.GetStub().GetTxID())
+	draftKey, err := stateKey(ctx, DocTypeDraft, requestID)
+	if err != nil {
+		return "", err
+	}
+	existing, err := getDocument(ctx, draftKey, requestID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read draft record: %v", err)
+	}
+	if existing != nil {
+		return "", fmt.Errorf("draft %s already exists", requestID)
+	}
+
 	// Create draft record (no Property ID yet)
 	draftRecord := LandRecord{
+		DocType:        DocTypeDraft,
+		SchemaVersion:  currentSchemaVersion(DocTypeDraft),
 		PropertyID:     "", // Pending CCLB assignment
 		StateCode:      stateCode,
-		Owner:          owner,
+		Owner:          ownerSummary(owners),
 		SurveyNo:       surveyNo,
 		District:       district,
 		Mandal:         mandal,
 		Village:        village,
-		Area:           area,
+		Area:           parsedArea,
 		LandType:       landType,
-		MarketValue:    marketValue,
-		LastUpdated:    time.Now().Format("2006-01-02"),
+		MarketValue:    parsedValue,
+		LastUpdated:    txTime.Format("2006-01-02"),
 		IPFSCID:        ipfsCID,
 		VerifiedByCCLB: false,
+		Status:         DraftPendingID,
+		RequestID:      requestID,
+		ExpiresAt:      txTime.Add(draftTimeout).Format(time.RFC3339),
+		Owners:         owners,
+		Tenancy:        tenancy,
+		OwnerIDNumber:  private.OwnerIDNumber,
+		privateSalt:    private.Salt,
 	}
 
-	// Generate temporary request ID
-	requestID := fmt.Sprintf("REQ-%s-%d", stateCode, time.Now().Unix())
-
-	// Store draft under request ID
-	draftJSON, err := json.Marshal(draftRecord)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal draft record: %v", err)
+	// Reserve the survey tuple so no second draft or record can take it
+	lookup := newLandLookup(&draftRecord)
+	if err := requireSurveyAvailable(ctx, lookup, "", nil); err != nil {
+		return "", err
+	}
+	if err := putSurveyClaim(ctx, lookup, "", requestID); err != nil {
+		return "", err
 	}
 
-	if err := ctx.GetStub().PutState(requestID, draftJSON); err != nil {
-		return "", fmt.Errorf("failed to store draft record: %v", err)
+	// Store draft under request ID
+	if err := putDraft(ctx, &draftRecord); err != nil {
+		return "", err
 	}
 
 	// Emit PropertyIDRequestedEvent (consumed by CCLB or polling backend)
@@ -106,34 +183,63 @@ func (c *LandRegistryContract) CreateStateRecord(
 	}
 
 	// Retrieve draft record from RequestPropertyID
-	draftJSON, err := ctx.GetStub().GetState(requestID)
+	draft, err := getDraft(ctx, requestID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read draft record: %v", err)
+		return nil, err
 	}
-	if draftJSON == nil {
-		return nil, fmt.Errorf("draft record %s not found (did you call RequestPropertyID first?)", requestID)
+	if draft.Status != DraftPendingID {
+		return nil, fmt.Errorf("draft %s is %s, only %s drafts can be bound", requestID, draft.Status, DraftPendingID)
+	}
+	if err := loadPrivateDetails(ctx, DocTypeDraft, requestID, draft); err != nil {
+		return nil, err
+	}
+	if err := requireUnusedPropertyID(ctx, propertyID); err != nil {
+		return nil, err
+	}
+	if err := requireSurveyAvailable(ctx, newLandLookup(draft), requestID, nil); err != nil {
+		return nil, err
+	}
+	if err := draft.Area.requireTyped("area"); err != nil {
+		return nil, fmt.Errorf("draft %s: %v", requestID, err)
+	}
+	if err := draft.MarketValue.requireTyped("market value"); err != nil {
+		return nil, fmt.Errorf("draft %s: %v", requestID, err)
 	}
 
-	var landRecord LandRecord
-	if err := json.Unmarshal(draftJSON, &landRecord); err != nil {
-		return nil, fmt.Errorf("failed to parse draft record: %v", err)
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expired, err := draftExpired(draft, txTime)
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, fmt.Errorf("draft %s expired at %s", requestID, draft.ExpiresAt)
 	}
 
 	// Bind Property ID from CCLB
+	landRecord := *draft
 	landRecord.PropertyID = propertyID
-	landRecord.LastUpdated = time.Now().Format("2006-01-02")
+	landRecord.Status = RecordActive
+	landRecord.ExpiresAt = ""
+	landRecord.LastUpdated = txTime.Format("2006-01-02")
+	landRecord.VerificationStatus = VerificationPending
 	if ipfsCID != "" {
 		landRecord.IPFSCID = ipfsCID
 	}
 
-	// Store as authoritative record keyed by Property ID
-	landRecordJSON, err := json.Marshal(landRecord)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal land record: %v", err)
+	// Store as authoritative record keyed by Property ID (indexed for survey/owner queries)
+	if err := putLandRecord(ctx, &landRecord); err != nil {
+		return nil, fmt.Errorf("failed to store state record: %v", err)
 	}
 
-	if err := ctx.GetStub().PutState(propertyID, landRecordJSON); err != nil {
-		return nil, fmt.Errorf("failed to store state record: %v", err)
+	// Keep the draft as a BOUND audit entry so it cannot be bound a second time
+	draft.Status = DraftBound
+	draft.PropertyID = propertyID
+	draft.LastUpdated = landRecord.LastUpdated
+	if err := putDraft(ctx, draft); err != nil {
+		return nil, err
 	}
 
 	// Emit StateRecordCreatedEvent (CCLB will verify and update VerifiedByCCLB flag)
@@ -152,14 +258,93 @@ func (c *LandRegistryContract) CreateStateRecord(
 	return &landRecord, nil
 }
 
-// ReadLandRecord retrieves a land record by property ID
+// AcceptCCLBVerification records CCLB's verdict on a state record
+// FEDERATED FLOW (post step 3):
+//   - CCLB runs VerifyStateRecord on cclb-global and emits VerificationCompleted
+//   - The CCLB relay submits the verdict here with the CCLB tx ID as proof reference
+//
+// VERIFIED sets VerifiedByCCLB; REJECTED keeps the record flagged with the reason.
+// Only CCLB identities (a member of every state channel) may call this.
+func (c *LandRegistryContract) AcceptCCLBVerification(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	cclbTxID string,
+	status string,
+	reason string,
+) (*LandRecord, error) {
+	if err := requireMSP(ctx, cclbMSPID); err != nil {
+		return nil, fmt.Errorf("only CCLB can record verification results: %v", err)
+	}
+
+	if cclbTxID == "" {
+		return nil, fmt.Errorf("CCLB verification tx ID is required")
+	}
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if status != VerificationVerified && status != VerificationRejected {
+		return nil, fmt.Errorf("invalid verification status: %s", status)
+	}
+	if status == VerificationRejected && strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a rejection reason is required")
+	}
+
+	landRecord, err := getLandRecord(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if landRecord.VerifiedByCCLB {
+		return nil, fmt.Errorf("land record %s is already verified by CCLB (tx %s)", propertyID, landRecord.CCLBVerifyTx)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	landRecord.VerifiedByCCLB = status == VerificationVerified
+	landRecord.CCLBVerifyTx = cclbTxID
+	landRecord.VerificationStatus = status
+	landRecord.VerificationReason = ""
+	if status == VerificationRejected {
+		landRecord.VerificationReason = reason
+	}
+	landRecord.LastUpdated = txTime.Format("2006-01-02")
+
+	if err := putLandRecord(ctx, landRecord); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitCCLBVerificationRecordedEvent(
+		ctx,
+		propertyID,
+		status,
+		landRecord.VerificationReason,
+		cclbTxID,
+	); err != nil {
+		fmt.Printf("warning: failed to emit CCLBVerificationRecordedEvent: %v\n", err)
+	}
+
+	return landRecord, nil
+}
+
+// ReadLandRecord retrieves a land record by property ID, with the leases it is subject to
 // Works on both cclb-global (partial data) and state-<code> (full data)
 func (c *LandRegistryContract) ReadLandRecord(
 	ctx contractapi.TransactionContextInterface,
 	propertyID string,
 ) (*LandRecord, error) {
+	landRecord, err := getLandRecord(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if landRecord.ActiveLeases, err = listActiveLeases(ctx, propertyID); err != nil {
+		return nil, err
+	}
+	return landRecord, nil
+}
 
-	landRecordJSON, err := ctx.GetStub().GetState(propertyID)
+// getLandRecord reads the stored public view of a land record
+func getLandRecord(ctx contractapi.TransactionContextInterface, propertyID string) (*LandRecord, error) {
+	landRecordJSON, err := getLandRecordJSON(ctx, propertyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from world state: %v", err)
 	}
@@ -168,7 +353,7 @@ func (c *LandRegistryContract) ReadLandRecord(
 	}
 
 	var landRecord LandRecord
-	err = json.Unmarshal(landRecordJSON, &landRecord)
+	err = decodeDocument(DocTypeLandRecord, landRecordJSON, &landRecord)
 	if err != nil {
 		return nil, err
 	}
@@ -2,15 +2,18 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
 type LandApplication struct {
-	AppID   string `json:"appId"`
-	OwnerID string `json:"ownerId"`
-	DocHash string `json:"docHash"`
-	Status  string `json:"status"`
+	DocType       string `json:"docType"` // APPLICATION
+	SchemaVersion int    `json:"schemaVersion"`
+	AppID         string `json:"appId"`
+	OwnerID       string `json:"ownerId"`
+	DocHash       string `json:"docHash"`
+	Status        string `json:"status"`
 }
 
 func (c *LandRegistryContract) SubmitLandApplication(
@@ -19,18 +22,27 @@ func (c *LandRegistryContract) SubmitLandApplication(
 	docHash string,
 ) error {
 
-	requireRole(ctx, "citizen")
+	if err := requireRole(ctx, "citizen"); err != nil {
+		return fmt.Errorf("only citizens can submit land applications: %v", err)
+	}
 
 	clientID, _ := ctx.GetClientIdentity().GetID()
 
 	app := LandApplication{
-		AppID:   appID,
-		OwnerID: clientID,
-		DocHash: docHash,
-		Status:  "PENDING_VERIFICATION",
+		DocType:       DocTypeApplication,
+		SchemaVersion: currentSchemaVersion(DocTypeApplication),
+		AppID:         appID,
+		OwnerID:       clientID,
+		DocHash:       docHash,
+		Status:        "PENDING_VERIFICATION",
+	}
+
+	appKey, err := stateKey(ctx, DocTypeApplication, appID)
+	if err != nil {
+		return err
 	}
 
 	data, _ := json.Marshal(app)
-	return ctx.GetStub().PutState("APP_"+appID, data)
+	return putRawDocument(ctx, appKey, "APP_"+appID, data)
 }
 
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Area is a land extent with its unit
+// Values are held in thousandths of Unit so sums stay exact across endorsers.
+// Records written before typed quantities carry the original text in Legacy
+// until MigrateLandQuantities normalizes them.
+type Area struct {
+	Milli        int64  `json:"milli"`            // Thousandths of Unit (2.5 acre → 2500)
+	Unit         string `json:"unit"`             // acre, hectare, guntha, cent, sq_yard, sq_ft, sq_metre
+	SqMetreMilli int64  `json:"sqMetreMilli"`     // Same extent in thousandths of a square metre
+	Legacy       string `json:"legacy,omitempty"` // Unparsed pre-migration text
+}
+
+// Money is an amount in Indian rupees held as integer paise
+type Money struct {
+	Paise  int64  `json:"paise"`
+	Legacy string `json:"legacy,omitempty"` // Unparsed pre-migration text
+}
+
+// QuantityMigrationReport summarizes a MigrateLandQuantities run
+type QuantityMigrationReport struct {
+	Scanned  int                        `json:"scanned"`
+	Migrated int                        `json:"migrated"`
+	Failed   []QuantityMigrationFailure `json:"failed"`
+}
+
+// QuantityMigrationFailure is a legacy value that could not be normalized
+type QuantityMigrationFailure struct {
+	Key    string `json:"key"` // Property ID or draft request ID
+	Field  string `json:"field"`
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// Area units
+const (
+	UnitAcre    = "acre"
+	UnitHectare = "hectare"
+	UnitGuntha  = "guntha"
+	UnitCent    = "cent"
+	UnitSqYard  = "sq_yard"
+	UnitSqFt    = "sq_ft"
+	UnitSqMetre = "sq_metre"
+)
+
+// areaUnitSqMetres is the exact size of each unit in square metres
+var areaUnitSqMetres = map[string]string{
+	UnitAcre:    "4046.8564224",
+	UnitHectare: "10000",
+	UnitGuntha:  "101.17141056", // 1/40 acre
+	UnitCent:    "40.468564224", // 1/100 acre
+	UnitSqYard:  "0.83612736",
+	UnitSqFt:    "0.09290304",
+	UnitSqMetre: "1",
+}
+
+// areaUnitAliases maps spellings found in registry data to a unit
+// Keys are lower-case with spaces, dots and underscores removed.
+var areaUnitAliases = map[string]string{
+	"acre": UnitAcre, "acres": UnitAcre, "ac": UnitAcre,
+	"hectare": UnitHectare, "hectares": UnitHectare, "ha": UnitHectare,
+	"guntha": UnitGuntha, "gunthas": UnitGuntha, "gunta": UnitGuntha, "guntas": UnitGuntha,
+	"cent": UnitCent, "cents": UnitCent,
+	"sqyard": UnitSqYard, "sqyards": UnitSqYard, "sqyd": UnitSqYard, "squareyard": UnitSqYard, "squareyards": UnitSqYard,
+	"sqft": UnitSqFt, "sqfeet": UnitSqFt, "squarefeet": UnitSqFt, "squarefoot": UnitSqFt,
+	"sqmetre": UnitSqMetre, "sqmetres": UnitSqMetre, "sqmeter": UnitSqMetre, "sqmeters": UnitSqMetre, "sqm": UnitSqMetre, "m2": UnitSqMetre,
+}
+
+// MigrateLandQuantities parses legacy Area/MarketValue text on land records and drafts
+// Values that cannot be normalized are left untouched and listed in the report.
+// Covers documents already moved to composite keys, so run it after MigrateKeyLayout.
+// Requires 'registrar' role
+func (c *LandRegistryContract) MigrateLandQuantities(
+	ctx contractapi.TransactionContextInterface,
+) (*QuantityMigrationReport, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can migrate land records: %v", err)
+	}
+
+	report := &QuantityMigrationReport{Failed: []QuantityMigrationFailure{}}
+	for _, docType := range []string{DocTypeLandRecord, DocTypeDraft} {
+		if err := migrateQuantities(ctx, docType, report); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// migrateQuantities normalizes legacy quantities on every document of one type
+func migrateQuantities(
+	ctx contractapi.TransactionContextInterface,
+	docType string,
+	report *QuantityMigrationReport,
+) error {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(docType, []string{})
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) == 0 {
+			return fmt.Errorf("invalid %s key: %v", docType, err)
+		}
+		id := attributes[0] // Property ID or draft request ID
+
+		var landRecord LandRecord
+		if err := decodeDocument(docType, queryResponse.Value, &landRecord); err != nil {
+			return fmt.Errorf("failed to parse %s: %v", id, err)
+		}
+		if err := loadPrivateDetails(ctx, docType, id, &landRecord); err != nil {
+			return err
+		}
+		report.Scanned++
+
+		changed := false
+		if landRecord.Area.Legacy != "" {
+			if area, err := parseArea(landRecord.Area.Legacy); err != nil {
+				report.Failed = append(report.Failed, QuantityMigrationFailure{
+					Key: id, Field: "area", Value: landRecord.Area.Legacy, Reason: err.Error(),
+				})
+			} else {
+				landRecord.Area = area
+				changed = true
+			}
+		}
+		if landRecord.MarketValue.Legacy != "" {
+			if value, err := parseRupees(landRecord.MarketValue.Legacy); err != nil {
+				report.Failed = append(report.Failed, QuantityMigrationFailure{
+					Key: id, Field: "marketValue", Value: landRecord.MarketValue.Legacy, Reason: err.Error(),
+				})
+			} else {
+				landRecord.MarketValue = value
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		if docType == DocTypeLandRecord {
+			err = putLandRecord(ctx, &landRecord)
+		} else {
+			err = putDraftAt(ctx, queryResponse.Key, &landRecord)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %v", id, err)
+		}
+		report.Migrated++
+	}
+
+	return nil
+}
+
+// UnmarshalJSON accepts both the typed object and a legacy free-text string
+func (a *Area) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		var legacy string
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return err
+		}
+		*a = Area{Legacy: legacy}
+		return nil
+	}
+	type plain Area
+	return json.Unmarshal(data, (*plain)(a))
+}
+
+// UnmarshalJSON accepts both the typed object and a legacy free-text string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		var legacy string
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return err
+		}
+		*m = Money{Legacy: legacy}
+		return nil
+	}
+	type plain Money
+	return json.Unmarshal(data, (*plain)(m))
+}
+
+// String renders the area as "<value> <unit>" (legacy text if not migrated)
+func (a Area) String() string {
+	if a.Legacy != "" {
+		return a.Legacy
+	}
+	return formatMilli(a.Milli) + " " + a.Unit
+}
+
+// String renders the amount as "₹<rupees>.<paise>" (legacy text if not migrated)
+func (m Money) String() string {
+	if m.Legacy != "" {
+		return m.Legacy
+	}
+	return fmt.Sprintf("₹%d.%02d", m.Paise/100, m.Paise%100)
+}
+
+// requireTyped rejects areas still holding un-migrated legacy text
+func (a Area) requireTyped(field string) error {
+	if a.Legacy != "" {
+		return fmt.Errorf("%s %q has not been migrated (run MigrateLandQuantities)", field, a.Legacy)
+	}
+	if a.Milli <= 0 || a.Unit == "" {
+		return fmt.Errorf("%s is missing", field)
+	}
+	return nil
+}
+
+// requireTyped rejects amounts still holding un-migrated legacy text
+func (m Money) requireTyped(field string) error {
+	if m.Legacy != "" {
+		return fmt.Errorf("%s %q has not been migrated (run MigrateLandQuantities)", field, m.Legacy)
+	}
+	if m.Paise <= 0 {
+		return fmt.Errorf("%s is missing", field)
+	}
+	return nil
+}
+
+// squareMetres returns the exact extent in square metres (zero for untyped areas)
+func (a Area) squareMetres() *big.Rat {
+	factor, ok := new(big.Rat).SetString(areaUnitSqMetres[a.Unit])
+	if !ok {
+		return new(big.Rat)
+	}
+	value := new(big.Rat).SetFrac64(a.Milli, 1000)
+	return value.Mul(value, factor)
+}
+
+// newArea builds a typed Area and fills in its square-metre equivalent
+func newArea(milli int64, unit string) Area {
+	area := Area{Milli: milli, Unit: unit}
+	sqMetreMilli := area.squareMetres()
+	area.SqMetreMilli = roundRat(sqMetreMilli.Mul(sqMetreMilli, big.NewRat(1000, 1)))
+	return area
+}
+
+// areaFromSquareMetres expresses an exact square-metre extent in unit, rounded to thousandths
+func areaFromSquareMetres(sqMetres *big.Rat, unit string) Area {
+	factor, _ := new(big.Rat).SetString(areaUnitSqMetres[unit])
+	value := new(big.Rat).Quo(sqMetres, factor)
+	return newArea(roundRat(value.Mul(value, big.NewRat(1000, 1))), unit)
+}
+
+// parseArea parses "<number> <unit>" text such as "2.5 acres", "0.8 ha" or "1,200 sq yd"
+func parseArea(text string) (Area, error) {
+	s := strings.ToLower(strings.TrimSpace(text))
+	split := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == ',')
+	})
+	if split <= 0 {
+		return Area{}, fmt.Errorf("area %q must be \"<number> <unit>\"", text)
+	}
+
+	replacer := strings.NewReplacer(" ", "", ".", "", "_", "", "-", "")
+	unit, ok := areaUnitAliases[replacer.Replace(s[split:])]
+	if !ok {
+		return Area{}, fmt.Errorf("area %q has an unknown unit", text)
+	}
+
+	value, ok := new(big.Rat).SetString(strings.ReplaceAll(s[:split], ",", ""))
+	if !ok || value.Sign() <= 0 {
+		return Area{}, fmt.Errorf("area %q must be a positive number", text)
+	}
+	milli := value.Mul(value, big.NewRat(1000, 1))
+	if !milli.IsInt() {
+		return Area{}, fmt.Errorf("area %q has more than 3 decimal places", text)
+	}
+	if !milli.Num().IsInt64() {
+		return Area{}, fmt.Errorf("area %q is too large", text)
+	}
+	// The square-metre equivalent is held in thousandths too and must also fit
+	area := Area{Milli: milli.Num().Int64(), Unit: unit}
+	if area.squareMetres().Cmp(big.NewRat(math.MaxInt64, 1000)) > 0 {
+		return Area{}, fmt.Errorf("area %q is too large", text)
+	}
+
+	return newArea(area.Milli, unit), nil
+}
+
+// parseRupees converts rupee text ("4500000", "₹45L", "1.2 Cr", "45,00,000") to paise
+func parseRupees(text string) (Money, error) {
+	s := strings.ToLower(strings.TrimSpace(text))
+	for _, prefix := range []string{"₹", "rs.", "rs", "inr"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+
+	multiplier := int64(1)
+	for _, suffix := range []struct {
+		unit   string
+		factor int64
+	}{
+		{"crores", 1e7}, {"crore", 1e7}, {"cr", 1e7},
+		{"lakhs", 1e5}, {"lakh", 1e5}, {"lac", 1e5}, {"l", 1e5},
+	} {
+		if strings.HasSuffix(s, suffix.unit) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, suffix.unit))
+			multiplier = suffix.factor
+			break
+		}
+	}
+
+	rupees, ok := new(big.Rat).SetString(s)
+	if !ok || rupees.Sign() <= 0 || strings.ContainsAny(s, "/eE") {
+		return Money{}, fmt.Errorf("amount %q must be a positive rupee value", text)
+	}
+	paise := rupees.Mul(rupees, big.NewRat(multiplier*100, 1))
+	if !paise.IsInt() {
+		return Money{}, fmt.Errorf("amount %q is not a whole number of paise", text)
+	}
+	if !paise.Num().IsInt64() {
+		return Money{}, fmt.Errorf("amount %q is too large", text)
+	}
+
+	return Money{Paise: paise.Num().Int64()}, nil
+}
+
+// roundRat rounds a non-negative rational to the nearest integer (halves up)
+func roundRat(r *big.Rat) int64 {
+	doubled := new(big.Int).Mul(r.Num(), big.NewInt(2))
+	doubled.Add(doubled, r.Denom())
+	return doubled.Quo(doubled, new(big.Int).Mul(r.Denom(), big.NewInt(2))).Int64()
+}
+
+// formatMilli renders thousandths as a trimmed decimal (2500 → "2.5")
+func formatMilli(value int64) string {
+	s := fmt.Sprintf("%d.%03d", value/1000, value%1000)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestParseArea(t *testing.T) {
+	tests := []struct {
+		text  string
+		milli int64
+		unit  string
+		err   string
+	}{
+		{text: "2.5 acres", milli: 2500, unit: UnitAcre},
+		{text: "0.8 ha", milli: 800, unit: UnitHectare},
+		{text: "1,200 sq yd", milli: 1200000, unit: UnitSqYard},
+		{text: "10 Guntas", milli: 10000, unit: UnitGuntha},
+		{text: "150sqft", milli: 150000, unit: UnitSqFt},
+		{text: " 1.25 Sq. Metres ", milli: 1250, unit: UnitSqMetre},
+		{text: "acres", err: "must be"},
+		{text: "2.5", err: "must be"},
+		{text: "2 bighas", err: "unknown unit"},
+		{text: "0 acres", err: "positive number"},
+		{text: "1.2345 acres", err: "3 decimal places"},
+		{text: "9223372036854776 acres", err: "too large"}, // Thousandths overflow int64
+		{text: "9000000000000 acres", err: "too large"},    // Square metres overflow int64
+	}
+	for _, tc := range tests {
+		area, err := parseArea(tc.text)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("parseArea(%q) = %+v, %v; want an error containing %q", tc.text, area, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || area.Milli != tc.milli || area.Unit != tc.unit {
+			t.Errorf("parseArea(%q) = %+v, %v; want %d thousandths of %s", tc.text, area, err, tc.milli, tc.unit)
+		}
+	}
+}
+
+func TestParseRupees(t *testing.T) {
+	tests := []struct {
+		text  string
+		paise int64
+		err   string
+	}{
+		{text: "4500000", paise: 450000000},
+		{text: "₹45L", paise: 450000000},
+		{text: "1.2 Cr", paise: 1200000000},
+		{text: "45,00,000", paise: 450000000},
+		{text: "Rs. 2.5 lakhs", paise: 25000000},
+		{text: "INR 99.99", paise: 9999},
+		{text: "", err: "positive rupee value"},
+		{text: "-5 L", err: "positive rupee value"},
+		{text: "0", err: "positive rupee value"},
+		{text: "1e5", err: "positive rupee value"},
+		{text: "1/2", err: "positive rupee value"},
+		{text: "forty lakh", err: "positive rupee value"},
+		{text: "12.345", err: "whole number of paise"},
+		{text: "10000000000000000 Cr", err: "too large"},
+	}
+	for _, tc := range tests {
+		money, err := parseRupees(tc.text)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("parseRupees(%q) = %+v, %v; want an error containing %q", tc.text, money, err, tc.err)
+			}
+			continue
+		}
+		if err != nil || money.Paise != tc.paise {
+			t.Errorf("parseRupees(%q) = %+v, %v; want %d paise", tc.text, money, err, tc.paise)
+		}
+	}
+}
+
+func TestAreaUnitConversions(t *testing.T) {
+	acre := newArea(1000, UnitAcre)
+	hectare := newArea(1000, UnitHectare)
+	tests := []struct {
+		name string
+		got  Area
+		want Area
+	}{
+		{"acre in square metres", acre, Area{Milli: 1000, Unit: UnitAcre, SqMetreMilli: 4046856}},
+		{"guntha in square metres", newArea(1000, UnitGuntha), Area{Milli: 1000, Unit: UnitGuntha, SqMetreMilli: 101171}},
+		{"acre in gunthas", areaFromSquareMetres(acre.squareMetres(), UnitGuntha), Area{Milli: 40000, Unit: UnitGuntha, SqMetreMilli: 4046856}},
+		{"acre in cents", areaFromSquareMetres(acre.squareMetres(), UnitCent), Area{Milli: 100000, Unit: UnitCent, SqMetreMilli: 4046856}},
+		{"hectare in acres", areaFromSquareMetres(hectare.squareMetres(), UnitAcre), Area{Milli: 2471, Unit: UnitAcre, SqMetreMilli: 9999782}},
+		{"square yards in square feet", areaFromSquareMetres(newArea(1000, UnitSqYard).squareMetres(), UnitSqFt), Area{Milli: 9000, Unit: UnitSqFt, SqMetreMilli: 836}},
+	}
+	for _, tc := range tests {
+		if tc.got != tc.want {
+			t.Errorf("%s = %#v, want %#v", tc.name, tc.got, tc.want)
+		}
+	}
+
+	renderings := map[string]string{
+		newArea(2500, UnitAcre).String():   "2.5 acre",
+		newArea(2000, UnitAcre).String():   "2 acre",
+		newArea(1, UnitSqFt).String():      "0.001 sq_ft",
+		Area{Legacy: "2 acres"}.String():   "2 acres",
+		Money{Paise: 450000005}.String():   "₹4500000.05",
+		Money{Legacy: "45 lakhs"}.String(): "45 lakhs",
+	}
+	for got, want := range renderings {
+		if got != want {
+			t.Errorf("rendered %q, want %q", got, want)
+		}
+	}
+
+	var legacy, typed Area
+	if err := json.Unmarshal([]byte(`"2 acres"`), &legacy); err != nil || legacy != (Area{Legacy: "2 acres"}) {
+		t.Errorf("legacy area decoded as %+v, %v", legacy, err)
+	}
+	if err := json.Unmarshal([]byte(`{"milli":2500,"unit":"acre","sqMetreMilli":10117141}`), &typed); err != nil || typed != newArea(2500, UnitAcre) {
+		t.Errorf("typed area decoded as %+v, %v", typed, err)
+	}
+}
+
+func TestMigrateLandQuantitiesReport(t *testing.T) {
+	s := newDeedScenario(t)
+	s.record("CCLB-2025-TS-000003", "103", CoOwner{Name: "Arjun Rao", PersonID: s.personIDs["arjun"], Share: "1"})
+
+	// Records from before typed quantities, stored as they were then
+	legacy := map[[2]string]string{
+		{DocTypeLandRecord, "CCLB-2025-TS-000001"}: `{"propertyId":"CCLB-2025-TS-000001","stateCode":"TS","owner":"Ravi Kumar",
+			"surveyNo":"101/A","district":"Rangareddy","mandal":"Shamshabad","village":"Kothur",
+			"area":"2.5 acres","landType":"agricultural","marketValue":"45 L","lastUpdated":"2024-01-10"}`,
+		{DocTypeLandRecord, "CCLB-2025-TS-000002"}: `{"propertyId":"CCLB-2025-TS-000002","stateCode":"TS","owner":"Sita Devi",
+			"surveyNo":"102","district":"Rangareddy","mandal":"Shamshabad","village":"Kothur",
+			"area":"two acres","landType":"agricultural","marketValue":"₹12,00,000","lastUpdated":"2024-01-10"}`,
+		{DocTypeDraft, "REQ-TS-legacy"}: `{"requestId":"REQ-TS-legacy","stateCode":"TS","owner":"Sita Devi",
+			"surveyNo":"104","district":"Rangareddy","mandal":"Shamshabad","village":"Kothur",
+			"area":"10 guntas","landType":"agricultural","marketValue":"about 5 lakh","lastUpdated":"2024-01-10"}`,
+	}
+	s.must("tx-seed", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		for id, doc := range legacy {
+			key, err := ctx.GetStub().CreateCompositeKey(id[0], []string{id[1]})
+			if err != nil {
+				return nil, err
+			}
+			if err := ctx.GetStub().PutState(key, []byte(doc)); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+
+	migrate := func(txID string) *QuantityMigrationReport {
+		return s.must(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.MigrateLandQuantities(ctx)
+		}).(*QuantityMigrationReport)
+	}
+	failures := func(report *QuantityMigrationReport) map[string]string {
+		failed := map[string]string{}
+		for _, failure := range report.Failed {
+			failed[failure.Key+"/"+failure.Field] = failure.Value
+		}
+		return failed
+	}
+	wantFailed := map[string]string{"CCLB-2025-TS-000002/area": "two acres", "REQ-TS-legacy/marketValue": "about 5 lakh"}
+
+	// Three land records and two drafts, counting the one the scenario bound
+	report := migrate("tx-migrate")
+	if report.Scanned != 5 || report.Migrated != 3 {
+		t.Fatalf("migration scanned %d and migrated %d, want 5 and 3", report.Scanned, report.Migrated)
+	}
+	if failed := failures(report); len(failed) != len(wantFailed) || failed["CCLB-2025-TS-000002/area"] != "two acres" ||
+		failed["REQ-TS-legacy/marketValue"] != "about 5 lakh" {
+		t.Fatalf("migration failures %v, want %v", failed, wantFailed)
+	}
+
+	migrated := s.owners("CCLB-2025-TS-000001")
+	if migrated.Area != newArea(2500, UnitAcre) || migrated.MarketValue.Paise != 450000000 || migrated.Owners[0].Name != "Ravi Kumar" {
+		t.Fatalf("migrated record holds %+v worth %+v for %+v", migrated.Area, migrated.MarketValue, migrated.Owners)
+	}
+	partial := s.owners("CCLB-2025-TS-000002")
+	if partial.Area.Legacy != "two acres" || partial.MarketValue.Paise != 120000000 {
+		t.Fatalf("partly migrated record holds %+v worth %+v, want the area left as text", partial.Area, partial.MarketValue)
+	}
+
+	// Values left behind are reported again, and nothing is rewritten
+	rerun := migrate("tx-migrate-again")
+	if rerun.Scanned != 5 || rerun.Migrated != 0 || len(failures(rerun)) != len(wantFailed) {
+		t.Fatalf("second migration scanned %d, migrated %d and failed %v", rerun.Scanned, rerun.Migrated, failures(rerun))
+	}
+}
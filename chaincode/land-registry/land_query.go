@@ -0,0 +1,534 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// LandLookup holds normalized copies of the searchable LandRecord fields
+// Maintained by putLandRecord so the CouchDB indexes (META-INF/statedb/couchdb/indexes)
+// and the LevelDB composite-key indexes both match case- and space-insensitively.
+// Owners are private and indexed inside landPrivateCollection instead.
+type LandLookup struct {
+	District string `json:"district"`
+	Mandal   string `json:"mandal"`
+	Village  string `json:"village"`
+	SurveyNo string `json:"surveyNo"`
+	LandType string `json:"landType"`
+}
+
+// LandRecordPage is one page of a paginated land record listing
+// Pass Bookmark back unchanged to fetch the next page; it is empty after the last page.
+type LandRecordPage struct {
+	Records             []*LandRecord `json:"records"`
+	FetchedRecordsCount int32         `json:"fetchedRecordsCount"`
+	Bookmark            string        `json:"bookmark"`
+}
+
+// Composite-key indexes maintained on every land record write
+// Used when the peer runs LevelDB and GetQueryResult is unavailable.
+const (
+	landLocationIndex = "LAND~location" // district~mandal~village~surveyNo~propertyId
+	landOwnerIndex    = "LAND~owner"    // owner~propertyId, in landPrivateCollection
+	landPersonIndex   = "LAND~person"   // ownerPersonId~propertyId, in landPrivateCollection
+	landTypeIndex     = "LAND~landType" // landType~propertyId
+)
+
+// maxPageSize caps paginated listings so a single response stays bounded
+const maxPageSize = 1000
+
+// CouchDB design documents shipped under META-INF/statedb/couchdb/indexes
+var (
+	locationIndexDoc = []string{"_design/indexLocationDoc", "indexLocation"}
+	landTypeIndexDoc = []string{"_design/indexLandTypeDoc", "indexLandType"}
+)
+
+// QueryLandByOwner returns all land records held by an owner
+// Reads the owner index in the private collection, so only member orgs can query it.
+func (c *LandRegistryContract) QueryLandByOwner(
+	ctx contractapi.TransactionContextInterface,
+	owner string,
+) ([]*LandRecord, error) {
+	owner = normalizeLookup(owner)
+	if owner == "" {
+		return nil, fmt.Errorf("owner is required")
+	}
+
+	indexIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(landPrivateCollection, landOwnerIndex, []string{owner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s index: %v", landOwnerIndex, err)
+	}
+	defer indexIterator.Close()
+	return readIndexedLandRecords(ctx, indexIterator, map[string]interface{}{"docType": DocTypeLandRecord})
+}
+
+// QueryLandByLocation returns land records in a district, mandal or village
+// mandal and village may be empty to widen the search; village requires mandal.
+func (c *LandRegistryContract) QueryLandByLocation(
+	ctx contractapi.TransactionContextInterface,
+	district string,
+	mandal string,
+	village string,
+) ([]*LandRecord, error) {
+	if strings.TrimSpace(district) == "" {
+		return nil, fmt.Errorf("district is required")
+	}
+	if strings.TrimSpace(village) != "" && strings.TrimSpace(mandal) == "" {
+		return nil, fmt.Errorf("mandal is required when searching by village")
+	}
+
+	return queryLandByLocation(ctx, district, mandal, village, "")
+}
+
+// GetAllLandRecordsPaginated lists land records a page at a time
+// Paginated queries are only valid for read-only (evaluate) transactions.
+func (c *LandRegistryContract) GetAllLandRecordsPaginated(
+	ctx contractapi.TransactionContextInterface,
+	pageSize int,
+	bookmark string,
+) (*LandRecordPage, error) {
+	return queryLandByLocationPage(ctx, "", pageSize, bookmark)
+}
+
+// QueryLandByDistrictPaginated lists land records in a district a page at a time
+func (c *LandRegistryContract) QueryLandByDistrictPaginated(
+	ctx contractapi.TransactionContextInterface,
+	district string,
+	pageSize int,
+	bookmark string,
+) (*LandRecordPage, error) {
+	if normalizeLookup(district) == "" {
+		return nil, fmt.Errorf("district is required")
+	}
+	return queryLandByLocationPage(ctx, district, pageSize, bookmark)
+}
+
+// QueryLandByOwnerPaginated lists an owner's land records a page at a time
+// Private data has no paginated queries, so the bookmark is the last owner
+// index key returned.
+func (c *LandRegistryContract) QueryLandByOwnerPaginated(
+	ctx contractapi.TransactionContextInterface,
+	owner string,
+	pageSize int,
+	bookmark string,
+) (*LandRecordPage, error) {
+	owner = normalizeLookup(owner)
+	if owner == "" {
+		return nil, fmt.Errorf("owner is required")
+	}
+	if pageSize < 1 || pageSize > maxPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d", maxPageSize)
+	}
+
+	indexIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(landPrivateCollection, landOwnerIndex, []string{owner})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s index: %v", landOwnerIndex, err)
+	}
+	defer indexIterator.Close()
+
+	page := &LandRecordPage{Records: []*LandRecord{}}
+	for indexIterator.HasNext() {
+		queryResponse, err := indexIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		if queryResponse.Key <= bookmark {
+			continue // Returned by an earlier page
+		}
+		if len(page.Records) == pageSize {
+			return page, nil // More remain; page.Bookmark is the last key returned
+		}
+
+		landRecord, err := readIndexedLandRecord(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		page.Bookmark = queryResponse.Key
+		if landRecord != nil {
+			page.Records = append(page.Records, landRecord)
+			page.FetchedRecordsCount++
+		}
+	}
+
+	page.Bookmark = ""
+	return page, nil
+}
+
+// QueryLandByLandTypePaginated lists land records of one land type a page at a time
+func (c *LandRegistryContract) QueryLandByLandTypePaginated(
+	ctx contractapi.TransactionContextInterface,
+	landType string,
+	pageSize int,
+	bookmark string,
+) (*LandRecordPage, error) {
+	landType = normalizeLookup(landType)
+	if landType == "" {
+		return nil, fmt.Errorf("land type is required")
+	}
+
+	selector := map[string]interface{}{
+		"docType":         DocTypeLandRecord,
+		"lookup.landType": landType,
+	}
+	return queryLandRecordsPage(ctx, selector, landTypeIndexDoc, landTypeIndex, []string{landType}, pageSize, bookmark)
+}
+
+// RebuildLandIndexes refreshes lookup fields and composite-key indexes of every land record
+// MigrateKeyLayout indexes records as it moves them; this is for later index changes.
+// Requires 'registrar' role
+func (c *LandRegistryContract) RebuildLandIndexes(
+	ctx contractapi.TransactionContextInterface,
+) (int, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return 0, fmt.Errorf("only registrars can rebuild indexes: %v", err)
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocTypeLandRecord, []string{})
+	if err != nil {
+		return 0, err
+	}
+	defer resultsIterator.Close()
+
+	indexed := 0
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return 0, err
+		}
+
+		var landRecord LandRecord
+		if err := decodeDocument(DocTypeLandRecord, queryResponse.Value, &landRecord); err != nil {
+			return 0, fmt.Errorf("failed to parse land record %s: %v", queryResponse.Key, err)
+		}
+		// Loaded so the owner index in the private collection is rebuilt too
+		if err := loadPrivateDetails(ctx, DocTypeLandRecord, landRecord.PropertyID, &landRecord); err != nil {
+			return 0, err
+		}
+
+		if err := putLandRecord(ctx, &landRecord); err != nil {
+			return 0, err
+		}
+		indexed++
+	}
+
+	return indexed, nil
+}
+
+// queryLandByLocation runs the location index with the given prefix of fields
+// Empty trailing fields match any value.
+func queryLandByLocation(
+	ctx contractapi.TransactionContextInterface,
+	district string,
+	mandal string,
+	village string,
+	surveyNo string,
+) ([]*LandRecord, error) {
+	selector, prefix := locationSelector(district, mandal, village, surveyNo)
+	return queryLandRecords(ctx, selector, locationIndexDoc, landLocationIndex, prefix)
+}
+
+// queryLandByLocationPage is the paginated form of queryLandByLocation for a district
+func queryLandByLocationPage(
+	ctx contractapi.TransactionContextInterface,
+	district string,
+	pageSize int,
+	bookmark string,
+) (*LandRecordPage, error) {
+	selector, prefix := locationSelector(district, "", "", "")
+	return queryLandRecordsPage(ctx, selector, locationIndexDoc, landLocationIndex, prefix, pageSize, bookmark)
+}
+
+// locationSelector builds the location-index selector and composite-key prefix
+// Every indexed field appears in the selector so CouchDB can use the index;
+// unspecified fields match anything non-null.
+func locationSelector(district, mandal, village, surveyNo string) (map[string]interface{}, []string) {
+	fields := []string{"lookup.district", "lookup.mandal", "lookup.village", "lookup.surveyNo"}
+	values := []string{normalizeLookup(district), normalizeLookup(mandal), normalizeLookup(village), normalizeLookup(surveyNo)}
+
+	selector := map[string]interface{}{"docType": DocTypeLandRecord}
+	var prefix []string
+	for i, field := range fields {
+		if values[i] == "" {
+			selector[field] = map[string]interface{}{"$gt": nil}
+			continue
+		}
+		selector[field] = values[i]
+		if len(prefix) == i {
+			prefix = append(prefix, values[i])
+		}
+	}
+	return selector, prefix
+}
+
+// queryLandRecords runs a CouchDB selector, falling back to a composite-key index on LevelDB
+func queryLandRecords(
+	ctx contractapi.TransactionContextInterface,
+	selector map[string]interface{},
+	indexDoc []string,
+	compositeIndex string,
+	prefix []string,
+) ([]*LandRecord, error) {
+	query, err := landQuery(selector, indexDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	resultsIterator, err := ctx.GetStub().GetQueryResult(query)
+	if err == nil {
+		defer resultsIterator.Close()
+		return readLandRecords(resultsIterator)
+	}
+	if !richQueryUnsupported(err) {
+		return nil, err
+	}
+
+	indexIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(compositeIndex, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s index: %v", compositeIndex, err)
+	}
+	defer indexIterator.Close()
+	return readIndexedLandRecords(ctx, indexIterator, selector)
+}
+
+// queryLandRecordsPage is the paginated form of queryLandRecords
+func queryLandRecordsPage(
+	ctx contractapi.TransactionContextInterface,
+	selector map[string]interface{},
+	indexDoc []string,
+	compositeIndex string,
+	prefix []string,
+	pageSize int,
+	bookmark string,
+) (*LandRecordPage, error) {
+	if pageSize < 1 || pageSize > maxPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d", maxPageSize)
+	}
+
+	query, err := landQuery(selector, indexDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	var landRecords []*LandRecord
+	resultsIterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(query, int32(pageSize), bookmark)
+	if err == nil {
+		defer resultsIterator.Close()
+		landRecords, err = readLandRecords(resultsIterator)
+	} else if richQueryUnsupported(err) {
+		var indexIterator shim.StateQueryIteratorInterface
+		indexIterator, metadata, err = ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(
+			compositeIndex, prefix, int32(pageSize), bookmark)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s index: %v", compositeIndex, err)
+		}
+		defer indexIterator.Close()
+		landRecords, err = readIndexedLandRecords(ctx, indexIterator, selector)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &LandRecordPage{
+		Records:             landRecords,
+		FetchedRecordsCount: int32(len(landRecords)),
+		Bookmark:            metadata.Bookmark,
+	}, nil
+}
+
+// landQuery renders a CouchDB query for a selector pinned to an index
+func landQuery(selector map[string]interface{}, indexDoc []string) (string, error) {
+	queryJSON, err := json.Marshal(map[string]interface{}{
+		"selector":  selector,
+		"use_index": indexDoc,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %v", err)
+	}
+	return string(queryJSON), nil
+}
+
+// richQueryUnsupported reports whether the peer's state database lacks rich queries (LevelDB)
+func richQueryUnsupported(err error) bool {
+	return strings.Contains(err.Error(), "not supported")
+}
+
+// readLandRecords collects land records from a rich query iterator
+func readLandRecords(resultsIterator shim.StateQueryIteratorInterface) ([]*LandRecord, error) {
+	landRecords := []*LandRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var landRecord LandRecord
+		if err := decodeDocument(DocTypeLandRecord, queryResponse.Value, &landRecord); err != nil {
+			return nil, fmt.Errorf("failed to parse land record %s: %v", queryResponse.Key, err)
+		}
+		landRecords = append(landRecords, &landRecord)
+	}
+
+	return landRecords, nil
+}
+
+// readIndexedLandRecords resolves composite-key index entries to land records (LevelDB fallback)
+// Records are re-checked against the selector's exact-match fields.
+func readIndexedLandRecords(
+	ctx contractapi.TransactionContextInterface,
+	resultsIterator shim.StateQueryIteratorInterface,
+	selector map[string]interface{},
+) ([]*LandRecord, error) {
+	landRecords := []*LandRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		landRecord, err := readIndexedLandRecord(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		if landRecord == nil || !lookupMatches(landRecord, selector) {
+			continue
+		}
+		landRecords = append(landRecords, landRecord)
+	}
+
+	return landRecords, nil
+}
+
+// readIndexedLandRecord reads the land record an index key points to (nil if stale)
+func readIndexedLandRecord(ctx contractapi.TransactionContextInterface, indexKey string) (*LandRecord, error) {
+	_, attributes, err := ctx.GetStub().SplitCompositeKey(indexKey)
+	if err != nil || len(attributes) == 0 {
+		return nil, fmt.Errorf("failed to split index key: %v", err)
+	}
+	propertyID := attributes[len(attributes)-1]
+
+	landRecordJSON, err := getLandRecordJSON(ctx, propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read land record %s: %v", propertyID, err)
+	}
+	if landRecordJSON == nil {
+		return nil, nil
+	}
+
+	var landRecord LandRecord
+	if err := decodeDocument(DocTypeLandRecord, landRecordJSON, &landRecord); err != nil {
+		return nil, fmt.Errorf("failed to parse land record %s: %v", propertyID, err)
+	}
+	return &landRecord, nil
+}
+
+// lookupMatches applies a selector's exact-match fields to a record
+func lookupMatches(landRecord *LandRecord, selector map[string]interface{}) bool {
+	lookup := landRecord.Lookup
+	fields := map[string]string{
+		"docType":         landRecord.DocType,
+		"lookup.district": lookup.District,
+		"lookup.mandal":   lookup.Mandal,
+		"lookup.village":  lookup.Village,
+		"lookup.surveyNo": lookup.SurveyNo,
+		"lookup.landType": lookup.LandType,
+	}
+	for field, want := range selector {
+		if value, ok := want.(string); ok && fields[field] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// indexLandRecord refreshes the composite-key index entries for a land record
+// Entries derived from the committed version of the record are removed first,
+// and the record's survey claim follows it (see claimLandSurvey).
+func indexLandRecord(ctx contractapi.TransactionContextInterface, landRecord *LandRecord) error {
+	current, err := landIndexKeys(ctx, landRecord.Lookup, landRecord.PropertyID)
+	if err != nil {
+		return err
+	}
+
+	previousJSON, err := getLandRecordJSON(ctx, landRecord.PropertyID)
+	if err != nil {
+		return fmt.Errorf("failed to read land record: %v", err)
+	}
+	var previous *LandRecord
+	if previousJSON != nil {
+		var committed LandRecord
+		if err := decodeDocument(DocTypeLandRecord, previousJSON, &committed); err == nil {
+			previous = &committed
+			stale, err := landIndexKeys(ctx, newLandLookup(previous), previous.PropertyID)
+			if err != nil {
+				return err
+			}
+			if previous.Owner != "" {
+				// Public owner index entry of a record from before the private collection
+				ownerKey, err := ctx.GetStub().CreateCompositeKey(landOwnerIndex,
+					[]string{normalizeLookup(previous.Owner), previous.PropertyID})
+				if err != nil {
+					return fmt.Errorf("failed to create owner index key: %v", err)
+				}
+				stale = append(stale, ownerKey)
+			}
+			for _, key := range stale {
+				if contains(current, key) {
+					continue
+				}
+				if err := ctx.GetStub().DelState(key); err != nil {
+					return fmt.Errorf("failed to delete index entry: %v", err)
+				}
+			}
+		}
+	}
+
+	for _, key := range current {
+		if err := ctx.GetStub().PutState(key, []byte{0x00}); err != nil {
+			return fmt.Errorf("failed to store index entry: %v", err)
+		}
+	}
+	return claimLandSurvey(ctx, previous, landRecord)
+}
+
+// landIndexKeys returns the composite keys indexing one land record
+func landIndexKeys(ctx contractapi.TransactionContextInterface, lookup LandLookup, propertyID string) ([]string, error) {
+	locationKey, err := ctx.GetStub().CreateCompositeKey(landLocationIndex,
+		[]string{lookup.District, lookup.Mandal, lookup.Village, lookup.SurveyNo, propertyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create location index key: %v", err)
+	}
+	landTypeKey, err := ctx.GetStub().CreateCompositeKey(landTypeIndex, []string{lookup.LandType, propertyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create land type index key: %v", err)
+	}
+	return []string{locationKey, landTypeKey}, nil
+}
+
+// newLandLookup derives the normalized lookup fields of a land record
+func newLandLookup(landRecord *LandRecord) LandLookup {
+	return LandLookup{
+		District: normalizeLookup(landRecord.District),
+		Mandal:   normalizeLookup(landRecord.Mandal),
+		Village:  normalizeLookup(landRecord.Village),
+		SurveyNo: normalizeLookup(landRecord.SurveyNo),
+		LandType: normalizeLookup(landRecord.LandType),
+	}
+}
+
+// normalizeLookup lower-cases and collapses whitespace for index matching
+func normalizeLookup(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,452 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// levelDBStub pages composite-key queries the way a LevelDB peer does
+// Rich queries are unsupported, and a page's bookmark is the key the next page
+// starts at, empty once the results are exhausted.
+type levelDBStub struct {
+	*privateDataStub
+}
+
+func (s *levelDBStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	return nil, fmt.Errorf("ExecuteQuery not supported for leveldb")
+}
+
+func (s *levelDBStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	return nil, nil, fmt.Errorf("ExecuteQueryWithPagination not supported for leveldb")
+}
+
+func (s *levelDBStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	resultsIterator, err := s.GetStateByPartialCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resultsIterator.Close()
+
+	page := &kvIterator{}
+	metadata := &pb.QueryResponseMetadata{}
+	for resultsIterator.HasNext() {
+		kv, err := resultsIterator.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if kv.Key < bookmark {
+			continue
+		}
+		if len(page.kvs) == int(pageSize) {
+			metadata.Bookmark = kv.Key
+			break
+		}
+		page.kvs = append(page.kvs, &queryresult.KV{Key: kv.Key, Value: kv.Value})
+	}
+	metadata.FetchedRecordsCount = int32(len(page.kvs))
+	return page, metadata, nil
+}
+
+// couchDBStub answers rich queries the way a CouchDB peer does, for the
+// selectors the land queries build: exact matches and {"$gt": null} on
+// dotted fields. Every query run is kept for inspection.
+type couchDBStub struct {
+	*privateDataStub
+	queries []string
+}
+
+func (s *couchDBStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	s.queries = append(s.queries, query)
+	var parsed struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	if err := json.Unmarshal([]byte(query), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid query: %v", err)
+	}
+
+	var keys []string
+	for key := range s.State {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	results := &kvIterator{}
+	for _, key := range keys {
+		var document map[string]interface{}
+		if json.Unmarshal(s.State[key], &document) != nil {
+			continue // Index entries and other non-JSON values are not documents
+		}
+		if selectorMatches(document, parsed.Selector) {
+			results.kvs = append(results.kvs, &queryresult.KV{Key: key, Value: s.State[key]})
+		}
+	}
+	return results, nil
+}
+
+func selectorMatches(document map[string]interface{}, selector map[string]interface{}) bool {
+	for field, want := range selector {
+		var value interface{} = document
+		for _, name := range strings.Split(field, ".") {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = object[name]
+		}
+		if operators, ok := want.(map[string]interface{}); ok {
+			if _, gt := operators["$gt"]; gt && value == nil {
+				return false
+			}
+			continue
+		}
+		if value != want {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLocationQueriesReturnOnlyMatchingLandRecords(t *testing.T) {
+	s := newDeedScenario(t)
+	ravi := CoOwner{Name: "Ravi Kumar", PersonID: s.personIDs["ravi"], Share: "1"}
+	s.record("CCLB-2025-TS-000001", "101", ravi) // Rangareddy / Shamshabad / Kothur
+	s.record("CCLB-2025-TS-000002", "102", ravi)
+	s.record("CCLB-2025-TS-000003", "101/A", ravi)
+
+	// A draft for survey 103, and another document type carrying survey 101's lookup fields
+	s.must("tx-draft", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RequestPropertyID(ctx, "TS", "Sita Devi", "103", "Rangareddy",
+			"Shamshabad", "Kothur", "1 acre", "agricultural", "20 L", "")
+	})
+	s.must("tx-seed", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		impostor := `{"docType":"SURVEY_NOTE","propertyId":"CCLB-2025-TS-000009",
+			"lookup":{"district":"rangareddy","mandal":"shamshabad","village":"kothur","surveyNo":"101"}}`
+		if err := ctx.GetStub().PutState("SURVEY_NOTE_1", []byte(impostor)); err != nil {
+			return nil, err
+		}
+		// A location index entry whose land record is gone is skipped
+		key, err := ctx.GetStub().CreateCompositeKey(landLocationIndex,
+			[]string{"rangareddy", "shamshabad", "kothur", "101", "CCLB-2025-TS-000009"})
+		if err != nil {
+			return nil, err
+		}
+		return nil, ctx.GetStub().PutState(key, []byte{0x00})
+	})
+
+	levelDB := new(contractapi.TransactionContext)
+	levelDB.SetStub(&levelDBStub{&privateDataStub{s.peer.stub}})
+	couchStub := &couchDBStub{privateDataStub: &privateDataStub{s.peer.stub}}
+	couchDB := new(contractapi.TransactionContext)
+	couchDB.SetStub(couchStub)
+
+	ids := func(landRecords []*LandRecord) []string {
+		found := []string{}
+		for _, landRecord := range landRecords {
+			found = append(found, landRecord.PropertyID)
+		}
+		return found
+	}
+	for name, ctx := range map[string]*contractapi.TransactionContext{"LevelDB": levelDB, "CouchDB": couchDB} {
+		landRecord, err := s.contract.QueryLandBySurvey(ctx, " RANGAREDDY", "shamshabad ", "Kothur", "101")
+		if err != nil || landRecord.PropertyID != "CCLB-2025-TS-000001" {
+			t.Errorf("%s survey lookup = %v (%v), want CCLB-2025-TS-000001", name, landRecord, err)
+		}
+		if _, err := s.contract.QueryLandBySurvey(ctx, "Rangareddy", "Shamshabad", "Kothur", "103"); err == nil ||
+			!strings.Contains(err.Error(), "not found") {
+			t.Errorf("%s lookup of an unrecorded survey: err = %v, want not found", name, err)
+		}
+
+		for _, tc := range []struct {
+			district, mandal, village string
+			want                      []string
+		}{
+			{"Rangareddy", "", "", []string{"CCLB-2025-TS-000001", "CCLB-2025-TS-000003", "CCLB-2025-TS-000002"}},
+			{"rangareddy", "Shamshabad", "KOTHUR", []string{"CCLB-2025-TS-000001", "CCLB-2025-TS-000003", "CCLB-2025-TS-000002"}},
+			{"Rangareddy", "Shamshabad", "Tondupally", []string{}},
+			{"Medchal", "", "", []string{}},
+		} {
+			found, err := s.contract.QueryLandByLocation(ctx, tc.district, tc.mandal, tc.village)
+			if name == "CouchDB" {
+				// CouchDB returns documents in key order rather than index order
+				sort.Strings(tc.want)
+			}
+			if err != nil || !reflect.DeepEqual(ids(found), tc.want) {
+				t.Errorf("%s location %s/%s/%s = %v (%v), want %v", name, tc.district, tc.mandal, tc.village, ids(found), err, tc.want)
+			}
+		}
+	}
+	if _, err := s.contract.QueryLandByLocation(levelDB, "Rangareddy", "", "Kothur"); err == nil || !strings.Contains(err.Error(), "mandal is required") {
+		t.Errorf("village without a mandal: err = %v, want it refused", err)
+	}
+
+	// Every query names the shipped index and covers all of its fields, or CouchDB would not use it
+	var index struct {
+		Index struct {
+			Fields []string `json:"fields"`
+		} `json:"index"`
+		DDoc string `json:"ddoc"`
+		Name string `json:"name"`
+	}
+	indexJSON, err := os.ReadFile("META-INF/statedb/couchdb/indexes/indexLocation.json")
+	if err != nil || json.Unmarshal(indexJSON, &index) != nil {
+		t.Fatalf("failed to read the location index definition: %v", err)
+	}
+	if len(couchStub.queries) == 0 {
+		t.Fatal("no rich queries reached the CouchDB stub")
+	}
+	for _, query := range couchStub.queries {
+		var parsed struct {
+			Selector map[string]interface{} `json:"selector"`
+			UseIndex []string               `json:"use_index"`
+		}
+		if err := json.Unmarshal([]byte(query), &parsed); err != nil {
+			t.Fatalf("query %s is not JSON: %v", query, err)
+		}
+		var fields []string
+		for field := range parsed.Selector {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		wantFields := append([]string(nil), index.Index.Fields...)
+		sort.Strings(wantFields)
+		if !reflect.DeepEqual(fields, wantFields) || !reflect.DeepEqual(parsed.UseIndex, []string{"_design/" + index.DDoc, index.Name}) {
+			t.Errorf("query %s does not match index %s on %v", query, index.Name, index.Index.Fields)
+		}
+		if parsed.Selector["docType"] != DocTypeLandRecord {
+			t.Errorf("query %s is not limited to land records", query)
+		}
+	}
+
+	selector, prefix := locationSelector("Rangareddy", "", "Kothur", "")
+	if !reflect.DeepEqual(prefix, []string{"rangareddy"}) || selector["lookup.village"] != "kothur" {
+		t.Errorf("selector %v with index prefix %v, want the village matched exactly but only the district as prefix", selector, prefix)
+	}
+}
+
+func TestLandIndexesFollowRecordWrites(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, arjunID := s.personIDs["ravi"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(&levelDBStub{&privateDataStub{s.peer.stub}})
+	locate := func(village string) []*LandRecord {
+		found, err := s.contract.QueryLandByLocation(ctx, "Rangareddy", "Shamshabad", village)
+		if err != nil {
+			t.Fatalf("location query failed: %v", err)
+		}
+		return found
+	}
+	ownedBy := func(owner string) []*LandRecord {
+		found, err := s.contract.QueryLandByOwner(s.peer.ctx, owner)
+		if err != nil {
+			t.Fatalf("owner query failed: %v", err)
+		}
+		return found
+	}
+	locationKey := func(village string) string {
+		key, _ := s.peer.stub.CreateCompositeKey(landLocationIndex,
+			[]string{"rangareddy", "shamshabad", village, "101", "CCLB-2025-TS-000001"})
+		return key
+	}
+
+	// The revenue survey moves the parcel into the neighbouring village
+	s.must("tx-resurvey", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		landRecord, err := s.contract.readLandRecordWithDetails(ctx, "CCLB-2025-TS-000001")
+		if err != nil {
+			return nil, err
+		}
+		landRecord.Village = "Tondupally"
+		return nil, putLandRecord(ctx, landRecord)
+	})
+	if found := locate("Kothur"); len(found) != 0 {
+		t.Errorf("Kothur still lists %d records after the move", len(found))
+	}
+	if found := locate("Tondupally"); len(found) != 1 || found[0].PropertyID != "CCLB-2025-TS-000001" {
+		t.Errorf("Tondupally lists %v, want the moved parcel", found)
+	}
+	if _, stale := s.peer.stub.State[locationKey("kothur")]; stale {
+		t.Error("location index entry for Kothur was left behind")
+	}
+
+	// A completed sale moves the parcel between owners
+	s.complete(s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+	}).(*TransferRequest), "arjun")
+	if found := ownedBy("Ravi Kumar"); len(found) != 0 {
+		t.Errorf("Ravi still owns %d records after the sale", len(found))
+	}
+	if found := ownedBy("arjun rao"); len(found) != 1 || found[0].PropertyID != "CCLB-2025-TS-000001" {
+		t.Errorf("Arjun owns %v, want the parcel he bought", found)
+	}
+
+	// A lost index entry is restored by a rebuild
+	s.must("tx-lose-index", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return nil, ctx.GetStub().DelState(locationKey("tondupally"))
+	})
+	if found := locate("Tondupally"); len(found) != 0 {
+		t.Fatalf("Tondupally lists %v without an index entry", found)
+	}
+	rebuilt := s.must("tx-rebuild", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RebuildLandIndexes(ctx)
+	}).(int)
+	if found := locate("Tondupally"); rebuilt != 1 || len(found) != 1 {
+		t.Errorf("rebuilt %d records and Tondupally lists %v, want the parcel back", rebuilt, found)
+	}
+	if found := ownedBy("Arjun Rao"); len(found) != 1 {
+		t.Errorf("Arjun owns %v after the rebuild, want one parcel", found)
+	}
+}
+
+func TestPaginatedQueriesWalkEveryPage(t *testing.T) {
+	s := newDeedScenario(t)
+	ravi := CoOwner{Name: "Ravi Kumar", PersonID: s.personIDs["ravi"], Share: "1"}
+	s.record("CCLB-2025-TS-000001", "101", ravi)
+	s.record("CCLB-2025-TS-000002", "102", CoOwner{Name: "Sita Devi", PersonID: s.personIDs["sita"], Share: "1"})
+	s.record("CCLB-2025-TS-000003", "103", ravi)
+	s.record("CCLB-2025-TS-000004", "104", ravi)
+	s.record("CCLB-2025-TS-000005", "105", ravi)
+
+	// A house plot in another district, also Ravi's
+	private, _ := json.Marshal(privateDetailsInput{Owners: []CoOwner{ravi}, MarketValue: "80 L", Salt: "3f1c9a7e5b2d8046"})
+	requested, err := s.peer.submit(t, proposal{
+		txID:      "tx-request-plot",
+		creator:   s.registrar,
+		time:      s.txTime,
+		transient: map[string][]byte{privateDetailsTransientKey: private},
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.RequestPropertyID(ctx, "TS", "", "12-B", "Medchal",
+				"Kompally", "Dulapally", "240 sq yards", "residential", "", "")
+		},
+	})
+	if err != nil {
+		t.Fatalf("request for the house plot failed: %v", err)
+	}
+	s.must("tx-bind-plot", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.CreateStateRecord(ctx, "CCLB-2025-TS-000006", requested.result.(string), "")
+	})
+
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(&levelDBStub{&privateDataStub{s.peer.stub}})
+
+	// walk follows bookmarks from the first page until one comes back empty
+	walk := func(name string, query func(bookmark string) (*LandRecordPage, error)) ([][]string, []string) {
+		var pages [][]string
+		var bookmarks []string
+		bookmark := ""
+		for {
+			page, err := query(bookmark)
+			if err != nil {
+				t.Fatalf("%s page %d failed: %v", name, len(pages)+1, err)
+			}
+			if int(page.FetchedRecordsCount) != len(page.Records) {
+				t.Fatalf("%s page %d counts %d records but holds %d", name, len(pages)+1, page.FetchedRecordsCount, len(page.Records))
+			}
+			ids := []string{}
+			for _, landRecord := range page.Records {
+				ids = append(ids, landRecord.PropertyID)
+			}
+			pages = append(pages, ids)
+			bookmarks = append(bookmarks, page.Bookmark)
+			if page.Bookmark == "" {
+				return pages, bookmarks
+			}
+			if len(pages) > 10 {
+				t.Fatalf("%s never returned an empty bookmark: %v", name, bookmarks)
+			}
+			bookmark = page.Bookmark
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query func(bookmark string) (*LandRecordPage, error)
+		pages [][]string
+	}{
+		{
+			name: "all records",
+			query: func(bookmark string) (*LandRecordPage, error) {
+				return s.contract.GetAllLandRecordsPaginated(ctx, 2, bookmark)
+			},
+			pages: [][]string{
+				{"CCLB-2025-TS-000006", "CCLB-2025-TS-000001"}, // Location index order: Medchal sorts before Rangareddy
+				{"CCLB-2025-TS-000002", "CCLB-2025-TS-000003"},
+				{"CCLB-2025-TS-000004", "CCLB-2025-TS-000005"},
+			},
+		},
+		{
+			name: "district",
+			query: func(bookmark string) (*LandRecordPage, error) {
+				return s.contract.QueryLandByDistrictPaginated(ctx, "RANGAREDDY", 2, bookmark)
+			},
+			pages: [][]string{
+				{"CCLB-2025-TS-000001", "CCLB-2025-TS-000002"},
+				{"CCLB-2025-TS-000003", "CCLB-2025-TS-000004"},
+				{"CCLB-2025-TS-000005"},
+			},
+		},
+		{
+			name: "land type",
+			query: func(bookmark string) (*LandRecordPage, error) {
+				return s.contract.QueryLandByLandTypePaginated(ctx, "Agricultural", 3, bookmark)
+			},
+			pages: [][]string{
+				{"CCLB-2025-TS-000001", "CCLB-2025-TS-000002", "CCLB-2025-TS-000003"},
+				{"CCLB-2025-TS-000004", "CCLB-2025-TS-000005"},
+			},
+		},
+		{
+			name: "owner",
+			query: func(bookmark string) (*LandRecordPage, error) {
+				return s.contract.QueryLandByOwnerPaginated(ctx, "ravi  kumar", 2, bookmark)
+			},
+			pages: [][]string{
+				{"CCLB-2025-TS-000001", "CCLB-2025-TS-000003"},
+				{"CCLB-2025-TS-000004", "CCLB-2025-TS-000005"},
+				{"CCLB-2025-TS-000006"},
+			},
+		},
+	}
+	for _, tc := range tests {
+		pages, bookmarks := walk(tc.name, tc.query)
+		if !reflect.DeepEqual(pages, tc.pages) {
+			t.Errorf("%s pages = %v, want %v", tc.name, pages, tc.pages)
+		}
+		if tc.name != "owner" {
+			continue
+		}
+
+		// The owner bookmark is the owner index key of the last record on the page
+		for i, bookmark := range bookmarks[:len(bookmarks)-1] {
+			_, attributes, err := s.peer.stub.SplitCompositeKey(bookmark)
+			if err != nil || attributes[len(attributes)-1] != pages[i][len(pages[i])-1] {
+				t.Errorf("owner page %d bookmark %q (%v), want the index key of %s", i+1, bookmark, err, pages[i][len(pages[i])-1])
+			}
+		}
+		// Resuming from a bookmark skips exactly the records already returned
+		resumed, err := tc.query(bookmarks[0])
+		if err != nil || len(resumed.Records) != 2 || resumed.Records[0].PropertyID != "CCLB-2025-TS-000004" {
+			t.Errorf("owner query resumed from %q returned %v (%v), want the second page", bookmarks[0], resumed, err)
+		}
+	}
+
+	for _, pageSize := range []int{0, maxPageSize + 1} {
+		if _, err := s.contract.QueryLandByOwnerPaginated(ctx, "Ravi Kumar", pageSize, ""); err == nil {
+			t.Errorf("owner query with page size %d was accepted", pageSize)
+		}
+		if _, err := s.contract.GetAllLandRecordsPaginated(ctx, pageSize, ""); err == nil {
+			t.Errorf("listing with page size %d was accepted", pageSize)
+		}
+	}
+}
@@ -15,22 +15,62 @@ import (
 //   - StateRecord holds full details and local state transactions
 //   - Each PropertyID appears on exactly one state channel (state-<code>)
 type LandRecord struct {
+	DocType        string `json:"docType"`    // LAND_RECORD
+	SchemaVersion  int    `json:"schemaVersion"`
 	PropertyID     string `json:"propertyId"` // CCLB-2026-TS-000001 (from cclb-global)
 	StateCode      string `json:"stateCode"`  // TS, KA, AP (for routing)
-	Owner          string `json:"owner"`
+	Owner          string `json:"owner"`      // Private: co-owner names (see Owners); empty on the public record
 	SurveyNo       string `json:"surveyNo"`
 	District       string `json:"district"`
 	Mandal         string `json:"mandal"`
 	Village        string `json:"village"`
-	Area           string `json:"area"`
+	Area           Area   `json:"area"`
 	LandType       string `json:"landType"`
-	MarketValue    string `json:"marketValue"`
+	MarketValue    Money  `json:"marketValue"` // Private, like Owner
 	LastUpdated    string `json:"lastUpdated"`
 	IPFSCID        string `json:"ipfsCID,omitempty"`
 	VerifiedByCCLB bool   `json:"verifiedByCCLB"` // Cross-chain verification status
 	CCLBVerifyTx   string `json:"ccLbVerifyTx"`   // Reference to CCLB verification tx
+
+	VerificationStatus string `json:"verificationStatus,omitempty"` // PENDING, VERIFIED, REJECTED
+	VerificationReason string `json:"verificationReason,omitempty"` // CCLB rejection reason
+
+	RevenueOwnerID string `json:"revenueOwnerId,omitempty"` // PERSON_ ID of the khata/patta holder per revenue records
+	KhataNo        string `json:"khataNo,omitempty"`        // Khata/patta number
+	MutationStatus string `json:"mutationStatus,omitempty"` // See MutationCase statuses
+	MutationCaseID string `json:"mutationCaseId,omitempty"` // Latest mutation case
+
+	SuccessionCaseID string `json:"successionCaseId,omitempty"` // Latest succession case that devolved a share
+
+	Frozen   bool     `json:"frozen,omitempty"`   // Set while any court order in FrozenBy is active
+	FrozenBy []string `json:"frozenBy,omitempty"` // Active court orders against the parcel; see CourtOrder
+
+	ActiveLeases []*Lease `json:"activeLeases,omitempty"` // Filled in by ReadLandRecord; never stored
+
+	Status             string   `json:"status,omitempty"`             // ACTIVE, RETIRED (after subdivision/amalgamation); drafts: PENDING_ID, BOUND, CANCELLED, EXPIRED
+	ParentIDs          []string `json:"parentIds,omitempty"`          // Parcels this one was carved from
+	ChildIDs           []string `json:"childIds,omitempty"`           // Parcels this one became
+	LineageOperationID string   `json:"lineageOperationId,omitempty"` // Latest LineageOperation touching this parcel
+
+	RequestID string `json:"requestId,omitempty"` // Draft request this record was bound from
+	ExpiresAt string `json:"expiresAt,omitempty"` // Drafts only: deadline to bind a Property ID
+
+	OwnerIDNumber string            `json:"ownerIdNumber,omitempty"` // Private: Aadhaar-like identifier of the owner
+	Owners        []CoOwner         `json:"owners,omitempty"`        // Private: who holds the parcel and in what shares
+	Tenancy       string            `json:"tenancy,omitempty"`       // SOLE, JOINT_TENANCY or TENANCY_IN_COMMON
+	PrivateHashes map[string]string `json:"privateHashes,omitempty"` // Salted hashes of the private fields (landPrivateCollection)
+	privateSalt   string            // Salt of the private details, while they are loaded
+
+	Lookup LandLookup `json:"lookup"` // Normalized search fields, maintained by putLandRecord
 }
 
+// CCLB verification statuses for LandRecord.VerificationStatus
+const (
+	VerificationPending  = "PENDING"
+	VerificationVerified = "VERIFIED"
+	VerificationRejected = "REJECTED"
+)
+
 // CreateLandRecord creates a new land record on the state channel
 // FEDERATED ARCHITECTURE CHANGE:
 //   - PropertyID is NO LONGER auto-generated here
@@ -68,30 +108,8 @@ func (c *LandRegistryContract) CreateLandRecord(
 	)
 }
 
-// ReadLandRecord retrieves a land record by property ID
-func (c *LandRegistryContract) ReadLandRecord(
-	ctx contractapi.TransactionContextInterface,
-	propertyID string,
-) (*LandRecord, error) {
-
-	landRecordJSON, err := ctx.GetStub().GetState(propertyID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
-	}
-	if landRecordJSON == nil {
-		return nil, fmt.Errorf("land record %s does not exist", propertyID)
-	}
-
-	var landRecord LandRecord
-	err = json.Unmarshal(landRecordJSON, &landRecord)
-	if err != nil {
-		return nil, err
-	}
-
-	return &landRecord, nil
-}
-
 // QueryLandBySurvey queries land records by district, mandal, village, and survey number
+// Uses the CouchDB location index, or the LAND~location composite key on LevelDB
 func (c *LandRegistryContract) QueryLandBySurvey(
 	ctx contractapi.TransactionContextInterface,
 	district string,
@@ -100,76 +118,43 @@ func (c *LandRegistryContract) QueryLandBySurvey(
 	surveyNo string,
 ) (*LandRecord, error) {
 
-	// In a real implementation, you'd use a rich query with CouchDB
-	// For now, we'll iterate through all records (not efficient for production)
+	if strings.TrimSpace(surveyNo) == "" {
+		return nil, fmt.Errorf("survey number is required")
+	}
 
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
+	landRecords, err := queryLandByLocation(ctx, district, mandal, village, surveyNo)
 	if err != nil {
 		return nil, err
 	}
-	defer resultsIterator.Close()
-
-	// Helper function for case-insensitive string comparison
-	eq := func(a, b string) bool {
-		return strings.ToLower(strings.TrimSpace(a)) == strings.ToLower(strings.TrimSpace(b))
+	if len(landRecords) == 0 {
+		return nil, fmt.Errorf("land record not found for the given survey details")
 	}
 
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-
-		var landRecord LandRecord
-		err = json.Unmarshal(queryResponse.Value, &landRecord)
-		if err != nil {
-			continue // Skip non-land records
-		}
-
-		// Check if all fields match (case-insensitive)
-		if eq(landRecord.District, district) &&
-			eq(landRecord.Mandal, mandal) &&
-			eq(landRecord.Village, village) &&
-			eq(landRecord.SurveyNo, surveyNo) {
-			return &landRecord, nil
+	// Prefer the live parcel over one retired by subdivision/amalgamation
+	for _, landRecord := range landRecords {
+		if landRecord.Status != RecordRetired {
+			return landRecord, nil
 		}
 	}
-
-	return nil, fmt.Errorf("land record not found for the given survey details")
+	return landRecords[0], nil
 }
 
 // GetAllLandRecords returns all land records
+// Walks the location index instead of the whole world state
 func (c *LandRegistryContract) GetAllLandRecords(
 	ctx contractapi.TransactionContextInterface,
 ) ([]*LandRecord, error) {
 
-	resultsIterator, err := ctx.GetStub().GetStateByRange("", "")
-	if err != nil {
-		return nil, err
-	}
-	defer resultsIterator.Close()
-
-	var landRecords []*LandRecord
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
-		}
-
-		var landRecord LandRecord
-		err = json.Unmarshal(queryResponse.Value, &landRecord)
-		if err != nil {
-			continue // Skip invalid records
-		}
-
-		landRecords = append(landRecords, &landRecord)
-	}
-
-	return landRecords, nil
+	return queryLandByLocation(ctx, "", "", "", "")
 }
 
 // TransferLandRecord transfers property ownership
 // Requires 'registrar' role for approval
+//
+// Deprecated: a single registrar call could overwrite Owner, even for
+// "rejected"/"pending" statuses. Ownership now changes only through the
+// staged workflow in transfer_request.go:
+// InitiateTransfer → AcceptTransfer → VerifyTransferDocuments → ApproveTransfer
 func (c *LandRegistryContract) TransferLandRecord(
 	ctx contractapi.TransactionContextInterface,
 	propertyID string,
@@ -182,75 +167,51 @@ func (c *LandRegistryContract) TransferLandRecord(
 		return nil, fmt.Errorf("only registrars can approve transfers: %v", err)
 	}
 
-	// Validate approval status
-	validStatuses := map[string]bool{
-		"approved": true,
-		"rejected": true,
-		"pending":  true,
-	}
-	if !validStatuses[strings.ToLower(approvalStatus)] {
-		return nil, fmt.Errorf("invalid approval status: %s", approvalStatus)
-	}
+	return nil, fmt.Errorf(
+		"❌ Legacy TransferLandRecord is deprecated\n" +
+			"Use staged flow: InitiateTransfer() → AcceptTransfer() → VerifyTransferDocuments() → ApproveTransfer()\n" +
+			"Ownership changes only after seller, buyer, sub-registrar and registrar have all signed off",
+	)
+}
 
-	// Read existing land record
-	landRecordJSON, err := ctx.GetStub().GetState(propertyID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read land record: %v", err)
+// putLandRecord stores a land record keyed by its Property ID
+// Also refreshes its lookup fields and composite-key indexes. State officials
+// may only write records inside their jurisdiction. Private fields move to
+// the private collection, leaving landRecord as the public view.
+func putLandRecord(ctx contractapi.TransactionContextInterface, landRecord *LandRecord) error {
+	if err := requireRecordJurisdiction(ctx, landRecord); err != nil {
+		return err
 	}
-	if landRecordJSON == nil {
-		return nil, fmt.Errorf("land record %s does not exist", propertyID)
+	if err := storePrivateDetails(ctx, DocTypeLandRecord, landRecord.PropertyID, landRecord); err != nil {
+		return err
 	}
 
-	var landRecord LandRecord
-	err = json.Unmarshal(landRecordJSON, &landRecord)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal land record: %v", err)
+	landRecord.DocType = DocTypeLandRecord
+	landRecord.ActiveLeases = nil
+	landRecord.SchemaVersion = currentSchemaVersion(DocTypeLandRecord)
+	landRecord.Lookup = newLandLookup(landRecord)
+	if err := indexLandRecord(ctx, landRecord); err != nil {
+		return err
 	}
 
-	oldOwner := landRecord.Owner
-
-	// Update owner
-	landRecord.Owner = newOwner
-	landRecord.LastUpdated = time.Now().Format("2006-01-02")
-
-	// Store updated record
-	updatedRecordJSON, err := json.Marshal(landRecord)
+	key, err := stateKey(ctx, DocTypeLandRecord, landRecord.PropertyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal updated record: %v", err)
+		return err
 	}
-
-	if err := ctx.GetStub().PutState(propertyID, updatedRecordJSON); err != nil {
-		return nil, fmt.Errorf("failed to update land record: %v", err)
-	}
-
-	// Emit PropertyTransferredEvent
-	if err := c.emitPropertyTransferredEvent(
-		ctx,
-		propertyID,
-		oldOwner,
-		newOwner,
-		approvalStatus,
-	); err != nil {
-		fmt.Printf("warning: failed to emit PropertyTransferredEvent: %v\n", err)
+	if err := putDocument(ctx, key, landRecord.PropertyID, landRecord); err != nil {
+		return fmt.Errorf("failed to update land record: %v", err)
 	}
+	return nil
+}
 
-	// Emit PropertyApprovedEvent if approved
-	if strings.ToLower(approvalStatus) == "approved" {
-		clientID, err := ctx.GetClientIdentity().GetID()
-		if err != nil {
-			fmt.Printf("warning: failed to get client identity: %v\n", err)
-		}
-		if err := c.emitPropertyApprovedEvent(
-			ctx,
-			propertyID,
-			clientID,
-			"transfer_approved",
-		); err != nil {
-			fmt.Printf("warning: failed to emit PropertyApprovedEvent: %v\n", err)
-		}
+// getLandRecordJSON reads a land record, falling back to its pre-migration flat key
+// Returns nil (no error) when the property does not exist.
+func getLandRecordJSON(ctx contractapi.TransactionContextInterface, propertyID string) ([]byte, error) {
+	key, err := stateKey(ctx, DocTypeLandRecord, propertyID)
+	if err != nil {
+		return nil, err
 	}
-
-	return &landRecord, nil
+	return getDocument(ctx, key, propertyID)
 }
 
 // LinkDocumentHash links an off-chain document hash to a property
@@ -274,7 +235,7 @@ func (c *LandRegistryContract) LinkDocumentHash(
 	}
 
 	// Verify property exists
-	landRecordJSON, err := ctx.GetStub().GetState(propertyID)
+	landRecordJSON, err := getLandRecordJSON(ctx, propertyID)
 	if err != nil {
 		return fmt.Errorf("failed to verify property: %v", err)
 	}
@@ -282,13 +243,22 @@ func (c *LandRegistryContract) LinkDocumentHash(
 		return fmt.Errorf("property %s does not exist", propertyID)
 	}
 
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Store document hash reference in ledger
-	docRefKey := fmt.Sprintf("DOC_%s_%s", propertyID, documentHash)
+	docRefKey, err := stateKey(ctx, DocTypeDocumentRef, propertyID, documentHash)
+	if err != nil {
+		return err
+	}
 	docRef := map[string]string{
+		"docType":      DocTypeDocumentRef,
 		"propertyId":   propertyID,
 		"documentHash": documentHash,
 		"documentType": documentType,
-		"linkedAt":     time.Now().Format("2006-01-02T15:04:05Z"),
+		"linkedAt":     txTime.Format(time.RFC3339),
 	}
 
 	docRefJSON, err := json.Marshal(docRef)
@@ -296,7 +266,7 @@ func (c *LandRegistryContract) LinkDocumentHash(
 		return fmt.Errorf("failed to marshal document reference: %v", err)
 	}
 
-	if err := ctx.GetStub().PutState(docRefKey, docRefJSON); err != nil {
+	if err := putRawDocument(ctx, docRefKey, fmt.Sprintf("DOC_%s_%s", propertyID, documentHash), docRefJSON); err != nil {
 		return fmt.Errorf("failed to store document reference: %v", err)
 	}
 
@@ -321,7 +291,7 @@ func (c *LandRegistryContract) GetTransactionHistory(
 ) (interface{}, error) {
 
 	// Verify property exists
-	landRecordJSON, err := ctx.GetStub().GetState(propertyID)
+	landRecordJSON, err := getLandRecordJSON(ctx, propertyID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify property: %v", err)
 	}
@@ -329,31 +299,49 @@ func (c *LandRegistryContract) GetTransactionHistory(
 		return nil, fmt.Errorf("property %s does not exist", propertyID)
 	}
 
-	// Get historical state versions
-	historyIterator, err := ctx.GetStub().GetHistoryForKey(propertyID)
+	// Get historical state versions: the pre-migration flat key first, then the composite key
+	key, err := stateKey(ctx, DocTypeLandRecord, propertyID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get history: %v", err)
+		return nil, err
 	}
-	defer historyIterator.Close()
 
 	var history []interface{}
-	for historyIterator.HasNext() {
-		entry, err := historyIterator.Next()
+	for _, historyKey := range []string{propertyID, key} {
+		historyIterator, err := ctx.GetStub().GetHistoryForKey(historyKey)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("failed to get history: %v", err)
 		}
 
-		historyEntry := map[string]interface{}{
-			"txId":      entry.TxId,
-			"value":     string(entry.Value),
-			"timestamp": entry.Timestamp.AsTime().Unix(),
+		for historyIterator.HasNext() {
+			entry, err := historyIterator.Next()
+			if err != nil {
+				historyIterator.Close()
+				return nil, err
+			}
+			if entry.IsDelete && historyKey == propertyID {
+				continue // Flat key removed by MigrateKeyLayout
+			}
+
+			historyEntry := map[string]interface{}{
+				"txId":      entry.TxId,
+				"value":     string(entry.Value),
+				"timestamp": entry.Timestamp.AsTime().Unix(),
+			}
+			history = append(history, historyEntry)
 		}
-		history = append(history, historyEntry)
+		historyIterator.Close()
+	}
+
+	var landRecord LandRecord
+	if err := decodeDocument(DocTypeLandRecord, landRecordJSON, &landRecord); err != nil {
+		return nil, fmt.Errorf("failed to parse land record: %v", err)
 	}
 
 	return map[string]interface{}{
 		"propertyId":       propertyID,
 		"transactionCount": len(history),
 		"transactions":     history,
+		"parentIds":        landRecord.ParentIDs,
+		"childIds":         landRecord.ChildIDs,
 	}, nil
 }
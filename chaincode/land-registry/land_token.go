@@ -8,9 +8,11 @@ import (
 )
 
 type LandToken struct {
-	TokenID string `json:"tokenId"`
-	OwnerID string `json:"ownerId"`
-	Status  string `json:"status"`
+	DocType       string `json:"docType"` // LAND_TOKEN
+	SchemaVersion int    `json:"schemaVersion"`
+	TokenID       string `json:"tokenId"`
+	OwnerID       string `json:"ownerId"`
+	Status        string `json:"status"`
 }
 
 func (c *LandRegistryContract) MintLandToken(
@@ -19,20 +21,29 @@ func (c *LandRegistryContract) MintLandToken(
 	ownerID string,
 ) error {
 
-	requireRole(ctx, "jt_sub_registrar")
+	if err := requireRole(ctx, "jt_sub_registrar"); err != nil {
+		return fmt.Errorf("only joint sub-registrars can mint land tokens: %v", err)
+	}
+
+	tokenKey, err := stateKey(ctx, DocTypeLandToken, tokenID)
+	if err != nil {
+		return err
+	}
 
-	exists, _ := ctx.GetStub().GetState("LAND_" + tokenID)
+	exists, _ := getDocument(ctx, tokenKey, "LAND_"+tokenID)
 	if exists != nil {
 		return fmt.Errorf("land token already exists")
 	}
 
 	token := LandToken{
-		TokenID: tokenID,
-		OwnerID: ownerID,
-		Status:  "ACTIVE",
+		DocType:       DocTypeLandToken,
+		SchemaVersion: currentSchemaVersion(DocTypeLandToken),
+		TokenID:       tokenID,
+		OwnerID:       ownerID,
+		Status:        "ACTIVE",
 	}
 
 	data, _ := json.Marshal(token)
-	return ctx.GetStub().PutState("LAND_"+tokenID, data)
+	return putRawDocument(ctx, tokenKey, "LAND_"+tokenID, data)
 }
 
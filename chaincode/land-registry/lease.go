@@ -0,0 +1,540 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Lease is a registered lease or tenancy over a property, or a portion of it
+// Stored under composite key LEASE~<propertyID>~<leaseID>. Leases for more
+// than 11 months must be registered; shorter ones may be. A lease runs with
+// the land: a transfer does not end it, and the transfer lists it so the
+// buyer takes subject to it. Leases of a parcel may not overlap in time
+// unless a registrar certifies, against the lease schedules, that their
+// portions are separate.
+type Lease struct {
+	DocType         string         `json:"docType"` // LEASE
+	LeaseID         string         `json:"leaseId"`
+	PropertyID      string         `json:"propertyId"`
+	Portion         string         `json:"portion,omitempty"`      // As described in the lease schedule; empty for the whole parcel
+	SeparateFrom    []string       `json:"separateFrom,omitempty"` // Leases certified to cover a different portion
+	LessorHash      string         `json:"lessorHash,omitempty"`   // Salted hash of the owners at registration, from LandRecord.PrivateHashes
+	LesseeID        string         `json:"lesseeId"`               // PERSON_ ID of the lessee
+	StartDate       string         `json:"startDate"`              // YYYY-MM-DD, inclusive
+	EndDate         string         `json:"endDate"`                // YYYY-MM-DD, inclusive; extended by renewals
+	RentSchedule    []RentPeriod   `json:"rentSchedule"`
+	SecurityDeposit Money          `json:"securityDeposit"`
+	DocumentHash    string         `json:"documentHash"`
+	Status          string         `json:"status"` // ACTIVE, TERMINATED
+	Renewals        []LeaseRenewal `json:"renewals,omitempty"`
+	TerminatedOn    string         `json:"terminatedOn,omitempty"` // Last day of an early-terminated lease
+	TerminationNote string         `json:"terminationNote,omitempty"`
+	RegisteredAt    string         `json:"registeredAt"`
+	RegisteredBy    string         `json:"registeredBy"`
+	RegisteredTxID  string         `json:"registeredTxId"`
+}
+
+// RentPeriod is the monthly rent payable from a date until the next period starts
+type RentPeriod struct {
+	From        string `json:"from"` // YYYY-MM-DD
+	MonthlyRent Money  `json:"monthlyRent"`
+}
+
+// LeaseRenewal records one extension of a lease's term
+type LeaseRenewal struct {
+	PreviousEndDate string `json:"previousEndDate"`
+	NewEndDate      string `json:"newEndDate"`
+	DocumentHash    string `json:"documentHash"`
+	RenewedAt       string `json:"renewedAt"`
+	TxID            string `json:"txId"`
+}
+
+// rentPeriodInput is one entry of a rent schedule as submitted
+type rentPeriodInput struct {
+	From        string `json:"from"`
+	MonthlyRent string `json:"monthlyRent"`
+}
+
+// Lease statuses
+// An ACTIVE lease binds the property until its end date; until it starts it
+// still counts as active, as it is already registered against the parcel.
+const (
+	LeaseActive     = "ACTIVE"
+	LeaseTerminated = "TERMINATED"
+)
+
+// RegisterLease records a lease over a property, or a portion of it, to a registered lessee
+// rentScheduleJSON is a JSON array of {"from", "monthlyRent"}; the first
+// period starts with the lease and the rest follow in date order.
+// separateFromJSON is a JSON array of lease IDs, possibly empty, whose
+// portions the registrar has checked do not overlap this lease's portion.
+// Requires 'registrar' role
+func (c *LandRegistryContract) RegisterLease(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	lesseeID string,
+	portion string,
+	separateFromJSON string,
+	startDate string,
+	endDate string,
+	rentScheduleJSON string,
+	securityDeposit string,
+	documentHash string,
+) (*Lease, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can register leases: %v", err)
+	}
+
+	if len(documentHash) < 32 {
+		return nil, fmt.Errorf("invalid document hash format")
+	}
+	if err := requireLeaseTerm(startDate, endDate); err != nil {
+		return nil, err
+	}
+	schedule, err := parseRentSchedule(rentScheduleJSON, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if len(schedule) == 0 || schedule[0].From != startDate {
+		return nil, fmt.Errorf("the rent schedule must start on the lease start date %s", startDate)
+	}
+	var deposit Money
+	if strings.TrimSpace(securityDeposit) != "" {
+		if deposit, err = parseRupees(securityDeposit); err != nil {
+			return nil, fmt.Errorf("invalid security deposit: %v", err)
+		}
+	}
+
+	portion = strings.TrimSpace(portion)
+	var separateFrom []string
+	if strings.TrimSpace(separateFromJSON) != "" {
+		if err := json.Unmarshal([]byte(separateFromJSON), &separateFrom); err != nil {
+			return nil, fmt.Errorf("invalid separate lease IDs: %v", err)
+		}
+	}
+	if len(separateFrom) > 0 && portion == "" {
+		return nil, fmt.Errorf("a lease of the whole parcel cannot be separate from other leases")
+	}
+	for _, leaseID := range separateFrom {
+		other, err := getLease(ctx, propertyID, leaseID)
+		if err != nil {
+			return nil, err
+		}
+		if other.Portion == "" {
+			return nil, fmt.Errorf("lease %s covers the whole parcel", leaseID)
+		}
+	}
+
+	landRecord, err := c.readLandRecordWithDetails(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if landRecord.Status == RecordRetired {
+		return nil, fmt.Errorf("land record %s is retired", propertyID)
+	}
+	if err := requireNotFrozen(ctx, propertyID); err != nil {
+		return nil, err
+	}
+	// The open transfer lists the parcel's leases for its buyer
+	active, err := getActiveTransferID(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if active != "" {
+		return nil, fmt.Errorf("property %s has an open transfer %s", propertyID, active)
+	}
+
+	lessee, err := getPerson(ctx, lesseeID)
+	if err != nil {
+		return nil, fmt.Errorf("lessee: %v", err)
+	}
+	if lessee.DateOfDeath != "" {
+		return nil, fmt.Errorf("lessee %s is deceased", lesseeID)
+	}
+
+	lease := &Lease{
+		PropertyID:      propertyID,
+		Portion:         portion,
+		SeparateFrom:    separateFrom,
+		LessorHash:      landRecord.PrivateHashes[PrivateFieldOwner],
+		LesseeID:        lesseeID,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		RentSchedule:    schedule,
+		SecurityDeposit: deposit,
+		DocumentHash:    documentHash,
+		Status:          LeaseActive,
+	}
+	if err := requireNoOverlappingLease(ctx, lease); err != nil {
+		return nil, err
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txID := ctx.GetStub().GetTxID()
+	lease.LeaseID = leaseIDFromTx(txID)
+	lease.RegisteredAt = txTime.Format(time.RFC3339)
+	lease.RegisteredBy = clientID
+	lease.RegisteredTxID = txID
+
+	if err := putLease(ctx, lease); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitLeaseEvent(ctx, EventLeaseRegistered, lease, ""); err != nil {
+		fmt.Printf("warning: failed to emit LeaseEvent: %v\n", err)
+	}
+
+	return lease, nil
+}
+
+// RenewLease extends an active lease to a later end date
+// rentScheduleJSON lists rent periods for the extension and may be empty to
+// carry the last rent forward.
+// Requires 'registrar' role
+func (c *LandRegistryContract) RenewLease(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	leaseID string,
+	newEndDate string,
+	rentScheduleJSON string,
+	documentHash string,
+) (*Lease, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can renew leases: %v", err)
+	}
+	if len(documentHash) < 32 {
+		return nil, fmt.Errorf("invalid document hash format")
+	}
+
+	lease, txTime, err := getLeaseInForce(ctx, propertyID, leaseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireLeaseTerm(lease.EndDate, newEndDate); err != nil {
+		return nil, err
+	}
+	if newEndDate == lease.EndDate {
+		return nil, fmt.Errorf("a renewal must extend the lease beyond %s", lease.EndDate)
+	}
+
+	var extension []RentPeriod
+	if strings.TrimSpace(rentScheduleJSON) != "" {
+		if extension, err = parseRentSchedule(rentScheduleJSON, lease.StartDate, newEndDate); err != nil {
+			return nil, err
+		}
+		if len(extension) > 0 && extension[0].From <= lease.EndDate {
+			return nil, fmt.Errorf("rent for the renewal must start after the current end date %s", lease.EndDate)
+		}
+	}
+
+	renewed := *lease
+	renewed.StartDate = dayAfter(lease.EndDate)
+	renewed.EndDate = newEndDate
+	if err := requireNoOverlappingLease(ctx, &renewed); err != nil {
+		return nil, err
+	}
+
+	lease.Renewals = append(lease.Renewals, LeaseRenewal{
+		PreviousEndDate: lease.EndDate,
+		NewEndDate:      newEndDate,
+		DocumentHash:    documentHash,
+		RenewedAt:       txTime.Format(time.RFC3339),
+		TxID:            ctx.GetStub().GetTxID(),
+	})
+	lease.EndDate = newEndDate
+	lease.RentSchedule = append(lease.RentSchedule, extension...)
+
+	if err := putLease(ctx, lease); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitLeaseEvent(ctx, EventLeaseRenewed, lease, ""); err != nil {
+		fmt.Printf("warning: failed to emit LeaseEvent: %v\n", err)
+	}
+
+	return lease, nil
+}
+
+// TerminateLease ends an active lease early
+// terminationDate is the last day of the lease and may not be in the past.
+// Requires 'registrar' role
+func (c *LandRegistryContract) TerminateLease(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	leaseID string,
+	terminationDate string,
+	reason string,
+) (*Lease, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can terminate leases: %v", err)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a reason for termination is required")
+	}
+	if _, err := time.Parse("2006-01-02", terminationDate); err != nil {
+		return nil, fmt.Errorf("invalid termination date %q (expected YYYY-MM-DD): %v", terminationDate, err)
+	}
+
+	lease, txTime, err := getLeaseInForce(ctx, propertyID, leaseID)
+	if err != nil {
+		return nil, err
+	}
+	if terminationDate < txTime.Format("2006-01-02") {
+		return nil, fmt.Errorf("termination date %s is in the past", terminationDate)
+	}
+	if terminationDate >= lease.EndDate {
+		return nil, fmt.Errorf("lease %s already ends on %s", leaseID, lease.EndDate)
+	}
+	if terminationDate < lease.StartDate {
+		return nil, fmt.Errorf("lease %s starts on %s; it cannot end before then", leaseID, lease.StartDate)
+	}
+
+	lease.Status = LeaseTerminated
+	lease.TerminatedOn = terminationDate
+	lease.TerminationNote = reason
+
+	if err := putLease(ctx, lease); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitLeaseEvent(ctx, EventLeaseTerminated, lease, reason); err != nil {
+		fmt.Printf("warning: failed to emit LeaseEvent: %v\n", err)
+	}
+
+	return lease, nil
+}
+
+// GetLeases returns every lease ever registered over a property
+func (c *LandRegistryContract) GetLeases(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) ([]*Lease, error) {
+	return listLeases(ctx, propertyID)
+}
+
+// GetActiveLeases returns the leases over a property that are in force or yet to start
+func (c *LandRegistryContract) GetActiveLeases(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) ([]*Lease, error) {
+	return listActiveLeases(ctx, propertyID)
+}
+
+// lastDay is the day a lease ends, after any early termination
+func (l *Lease) lastDay() string {
+	if l.TerminatedOn != "" {
+		return l.TerminatedOn
+	}
+	return l.EndDate
+}
+
+// overlaps reports whether two leases may cover some of the same land on some of the same days
+// Portions are free text that cannot be compared reliably, so leases running
+// at the same time overlap unless one was certified separate from the other.
+func (l *Lease) overlaps(other *Lease) bool {
+	if l.StartDate > other.lastDay() || other.StartDate > l.lastDay() {
+		return false
+	}
+	return !contains(l.SeparateFrom, other.LeaseID) && !contains(other.SeparateFrom, l.LeaseID)
+}
+
+// requireNoOverlappingLease fails when another lease covers the same portion during the term
+func requireNoOverlappingLease(ctx contractapi.TransactionContextInterface, lease *Lease) error {
+	leases, err := listLeases(ctx, lease.PropertyID)
+	if err != nil {
+		return err
+	}
+	for _, existing := range leases {
+		if existing.LeaseID != lease.LeaseID && lease.overlaps(existing) {
+			return fmt.Errorf("lease %s to %s already covers %s until %s and is not certified separate",
+				existing.LeaseID, existing.LesseeID, lease.PropertyID, existing.lastDay())
+		}
+	}
+	return nil
+}
+
+// requireLeaseTerm checks a term is a valid date range ending after it starts
+func requireLeaseTerm(startDate string, endDate string) error {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return fmt.Errorf("invalid start date %q (expected YYYY-MM-DD): %v", startDate, err)
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return fmt.Errorf("invalid end date %q (expected YYYY-MM-DD): %v", endDate, err)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("end date %s is before start date %s", endDate, startDate)
+	}
+	return nil
+}
+
+// parseRentSchedule parses rent periods, which must fall within the term in date order
+func parseRentSchedule(rentScheduleJSON string, startDate string, endDate string) ([]RentPeriod, error) {
+	var inputs []rentPeriodInput
+	if err := json.Unmarshal([]byte(rentScheduleJSON), &inputs); err != nil {
+		return nil, fmt.Errorf("invalid rent schedule: %v", err)
+	}
+
+	schedule := make([]RentPeriod, 0, len(inputs))
+	for _, input := range inputs {
+		if _, err := time.Parse("2006-01-02", input.From); err != nil {
+			return nil, fmt.Errorf("invalid rent period start %q (expected YYYY-MM-DD): %v", input.From, err)
+		}
+		if input.From < startDate || input.From > endDate {
+			return nil, fmt.Errorf("rent period from %s is outside the lease term", input.From)
+		}
+		if len(schedule) > 0 && input.From <= schedule[len(schedule)-1].From {
+			return nil, fmt.Errorf("rent periods must be in date order")
+		}
+		rent, err := parseRupees(input.MonthlyRent)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rent from %s: %v", input.From, err)
+		}
+		schedule = append(schedule, RentPeriod{From: input.From, MonthlyRent: rent})
+	}
+	return schedule, nil
+}
+
+// getLeaseInForce loads a lease that has not ended by the transaction date
+func getLeaseInForce(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	leaseID string,
+) (*Lease, time.Time, error) {
+	lease, err := getLease(ctx, propertyID, leaseID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if lease.Status != LeaseActive {
+		return nil, time.Time{}, fmt.Errorf("lease %s is %s", leaseID, lease.Status)
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if lease.EndDate < txTime.Format("2006-01-02") {
+		return nil, time.Time{}, fmt.Errorf("lease %s ended on %s", leaseID, lease.EndDate)
+	}
+	return lease, txTime, nil
+}
+
+// dayAfter returns the YYYY-MM-DD date following a valid YYYY-MM-DD date
+func dayAfter(date string) string {
+	day, _ := time.Parse("2006-01-02", date)
+	return day.AddDate(0, 0, 1).Format("2006-01-02")
+}
+
+// leaseIDFromTx derives the lease ID from the registering tx
+// The whole tx ID is kept, as distinct txs may share a prefix of it.
+func leaseIDFromTx(txID string) string {
+	return "LSE-" + txID
+}
+
+func getLease(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	leaseID string,
+) (*Lease, error) {
+	key, err := stateKey(ctx, DocTypeLease, propertyID, leaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaseJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lease: %v", err)
+	}
+	if leaseJSON == nil {
+		return nil, fmt.Errorf("lease %s does not exist on %s", leaseID, propertyID)
+	}
+
+	var lease Lease
+	if err := json.Unmarshal(leaseJSON, &lease); err != nil {
+		return nil, fmt.Errorf("failed to parse lease: %v", err)
+	}
+	return &lease, nil
+}
+
+func putLease(ctx contractapi.TransactionContextInterface, lease *Lease) error {
+	lease.DocType = DocTypeLease
+	key, err := stateKey(ctx, DocTypeLease, lease.PropertyID, lease.LeaseID)
+	if err != nil {
+		return err
+	}
+	if err := putDocument(ctx, key, "", lease); err != nil {
+		return fmt.Errorf("failed to store lease: %v", err)
+	}
+	return nil
+}
+
+func listLeases(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) ([]*Lease, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocTypeLease, []string{propertyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leases: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	leases := []*Lease{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var lease Lease
+		if err := json.Unmarshal(queryResponse.Value, &lease); err != nil {
+			return nil, fmt.Errorf("failed to parse lease: %v", err)
+		}
+		leases = append(leases, &lease)
+	}
+
+	return leases, nil
+}
+
+// listActiveLeases returns a property's leases that have not ended by the transaction date
+func listActiveLeases(ctx contractapi.TransactionContextInterface, propertyID string) ([]*Lease, error) {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	today := txTime.Format("2006-01-02")
+
+	leases, err := listLeases(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	active := []*Lease{}
+	for _, lease := range leases {
+		if lease.lastDay() >= today {
+			active = append(active, lease)
+		}
+	}
+	return active, nil
+}
+
+// requireNoActiveLeases fails while a lease would bind land about to get new Property IDs
+func requireNoActiveLeases(ctx contractapi.TransactionContextInterface, propertyID string) error {
+	leases, err := listActiveLeases(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if len(leases) > 0 {
+		return fmt.Errorf("property %s is leased to %s until %s (%s)", propertyID, leases[0].LesseeID, leases[0].lastDay(), leases[0].LeaseID)
+	}
+	return nil
+}
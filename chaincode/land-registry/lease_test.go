@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestLeasesCannotOverlapAndAreListedOnTransfer(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, sitaID, arjunID := s.personIDs["ravi"], s.personIDs["sita"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+
+	register := func(txID string, lesseeID string, portion string, separateFrom string, start string, end string) (endorsement, error) {
+		return s.step(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.RegisterLease(ctx, "CCLB-2025-TS-000001", lesseeID, portion, separateFrom, start, end,
+				`[{"from":"`+start+`","monthlyRent":"25000"}]`, "1.5 L", testDeedHash)
+		})
+	}
+	registered, err := register("tx-lease-shed", sitaID, "Godown shed, east side", "", "2025-04-01", "2026-03-31")
+	if err != nil {
+		t.Fatalf("lease registration failed: %v", err)
+	}
+	shed := registered.result.(*Lease)
+	owners := s.owners("CCLB-2025-TS-000001")
+	if shed.LessorHash != owners.PrivateHashes[PrivateFieldOwner] || shed.LesseeID != sitaID || shed.RentSchedule[0].MonthlyRent.Paise != 2500000 {
+		t.Fatalf("lease registered as %+v", shed)
+	}
+
+	if _, err := register("tx-lease-again", arjunID, "Shed on the east", "", "2026-01-01", "2026-12-31"); err == nil || !strings.Contains(err.Error(), "already covers") {
+		t.Fatalf("overlapping lease of the same portion: err = %v, want an overlap error", err)
+	}
+	if _, err := register("tx-lease-whole", arjunID, "", `["`+shed.LeaseID+`"]`, "2025-06-01", "2025-06-30"); err == nil || !strings.Contains(err.Error(), "whole parcel") {
+		t.Fatalf("lease of the whole parcel certified separate: err = %v, want it refused", err)
+	}
+	// Portions are free text, so only the registrar's certificate keeps them apart
+	if _, err := register("tx-lease-uncertified", arjunID, "Open field, west side", "", "2025-04-01", "2026-03-31"); err == nil || !strings.Contains(err.Error(), "not certified separate") {
+		t.Fatalf("uncertified lease of another portion: err = %v, want an overlap error", err)
+	}
+	if _, err := register("tx-lease-field", arjunID, "Open field, west side", `["`+shed.LeaseID+`"]`, "2025-04-01", "2026-03-31"); err != nil {
+		t.Fatalf("lease of a portion certified separate failed: %v", err)
+	}
+	landRecord, err := s.contract.ReadLandRecord(s.peer.ctx, "CCLB-2025-TS-000001")
+	if err != nil {
+		t.Fatalf("failed to read the land record: %v", err)
+	}
+	if len(landRecord.ActiveLeases) != 2 {
+		t.Fatalf("land record shows %d active leases, want the shed and the field", len(landRecord.ActiveLeases))
+	}
+
+	renewed := s.must("tx-renew", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RenewLease(ctx, "CCLB-2025-TS-000001", shed.LeaseID, "2027-03-31",
+			`[{"from":"2026-04-01","monthlyRent":"27500"}]`, testDeedHash)
+	}).(*Lease)
+	if renewed.EndDate != "2027-03-31" || len(renewed.Renewals) != 1 || len(renewed.RentSchedule) != 2 {
+		t.Fatalf("renewed lease %+v, want an extra year at the new rent", renewed)
+	}
+	terminated := s.must("tx-terminate", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.TerminateLease(ctx, "CCLB-2025-TS-000001", shed.LeaseID, "2026-09-30", "Lessee surrendered the shed")
+	}).(*Lease)
+	if terminated.Status != LeaseTerminated || terminated.lastDay() != "2026-09-30" {
+		t.Fatalf("terminated lease %+v, want it to end on 2026-09-30", terminated)
+	}
+	if _, err := register("tx-lease-after", arjunID, "Godown shed, east side", "", "2026-10-01", "2027-09-30"); err != nil {
+		t.Fatalf("lease after the early termination failed: %v", err)
+	}
+
+	transfer := s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+	}).(*TransferRequest)
+	if len(transfer.Leases) != 3 || !contains(transfer.Leases, shed.LeaseID) {
+		t.Fatalf("transfer lists leases %v, want all three, including %s", transfer.Leases, shed.LeaseID)
+	}
+	if _, err := register("tx-lease-locked", sitaID, "Well and pump house", "", "2025-04-01", "2025-12-31"); err == nil || !strings.Contains(err.Error(), "open transfer") {
+		t.Fatalf("lease during an open transfer: err = %v, want it locked", err)
+	}
+
+	mine := s.must("tx-mine", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.GetMyProperties(ctx)
+	}).(*MyProperties)
+	if len(mine.Holdings) != 1 || len(mine.Holdings[0].Leases) != 3 {
+		t.Fatalf("holdings %+v, want the parcel with its three leases", mine.Holdings)
+	}
+}
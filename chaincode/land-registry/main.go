@@ -7,9 +7,10 @@ import (
 )
 
 func main() {
-	chaincode, err := contractapi.NewChaincode(
-		new(LandRegistryContract),
-	)
+	contract := new(LandRegistryContract)
+	contract.BeforeTransaction = enforceAccessPolicy
+
+	chaincode, err := contractapi.NewChaincode(contract)
 	if err != nil {
 		log.Panicf("Error creating chaincode: %v", err)
 	}
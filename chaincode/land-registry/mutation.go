@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// MutationCase is the revenue-record (khata/patta) mutation that follows a transfer
+// Lifecycle:
+//
+//	NOTICE_ISSUED → (OBJECTION_FILED) → APPROVED | REJECTED
+//
+// Opened automatically by ApproveTransfer; third parties may object during the
+// notice period; a tahsildar decides once the notice period has run.
+type MutationCase struct {
+	DocType         string              `json:"docType"` // MUTATION
+	CaseID          string              `json:"caseId"`
+	PropertyID      string              `json:"propertyId"`
+	TransferID      string              `json:"transferId"`
+	FromPersonID    string              `json:"fromPersonId"` // PERSON_ ID of the outgoing holder
+	ToPersonID      string              `json:"toPersonId"`   // PERSON_ ID of the incoming holder
+	Status          string              `json:"status"`
+	NoticeIssuedAt  string              `json:"noticeIssuedAt"`
+	NoticeEndsAt    string              `json:"noticeEndsAt"`
+	Objections      []MutationObjection `json:"objections"`
+	DecidedBy       string              `json:"decidedBy,omitempty"`
+	DecidedAt       string              `json:"decidedAt,omitempty"`
+	DecisionRemarks string              `json:"decisionRemarks,omitempty"`
+	KhataNo         string              `json:"khataNo,omitempty"` // Revenue account number issued on approval
+}
+
+// MutationObjection is a third-party objection filed during the notice period
+type MutationObjection struct {
+	FiledBy      string `json:"filedBy"` // Client ID of the objector
+	Grounds      string `json:"grounds"`
+	DocumentHash string `json:"documentHash,omitempty"`
+	FiledAt      string `json:"filedAt"`
+	TxID         string `json:"txId"`
+}
+
+// Mutation case statuses (also mirrored on LandRecord.MutationStatus)
+const (
+	MutationNoticeIssued   = "NOTICE_ISSUED"
+	MutationObjectionFiled = "OBJECTION_FILED"
+	MutationApproved       = "APPROVED"
+	MutationRejected       = "REJECTED"
+)
+
+const (
+	mutationKeyPrefix = "MUTATION_" // Pre-migration flat key prefix
+
+	// mutationNoticePeriod is how long objections are accepted after notice
+	mutationNoticePeriod = 15 * 24 * time.Hour
+)
+
+// FileMutationObjection lets any identified party object during the notice period
+func (c *LandRegistryContract) FileMutationObjection(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	grounds string,
+	documentHash string,
+) (*MutationCase, error) {
+	if strings.TrimSpace(grounds) == "" {
+		return nil, fmt.Errorf("grounds for objection are required")
+	}
+
+	mutation, err := getMutationCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if mutation.Status != MutationNoticeIssued && mutation.Status != MutationObjectionFiled {
+		return nil, fmt.Errorf("mutation case %s is already %s", caseID, mutation.Status)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	noticeEnds, err := time.Parse(time.RFC3339, mutation.NoticeEndsAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notice period on %s: %v", caseID, err)
+	}
+	if txTime.After(noticeEnds) {
+		return nil, fmt.Errorf("notice period for %s ended at %s", caseID, mutation.NoticeEndsAt)
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+
+	mutation.Objections = append(mutation.Objections, MutationObjection{
+		FiledBy:      clientID,
+		Grounds:      grounds,
+		DocumentHash: documentHash,
+		FiledAt:      txTime.Format(time.RFC3339),
+		TxID:         ctx.GetStub().GetTxID(),
+	})
+	mutation.Status = MutationObjectionFiled
+
+	if err := putMutationCase(ctx, mutation); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitMutationEvent(ctx, EventMutationObjectionFiled, mutation, grounds); err != nil {
+		fmt.Printf("warning: failed to emit MutationEvent: %v\n", err)
+	}
+
+	return mutation, nil
+}
+
+// DecideMutation records the tahsildar's decision after the notice period
+// decision is APPROVE or REJECT; approval updates the revenue record on the
+// LandRecord. A case overtaken by a later transfer of the parcel, or by its
+// subdivision or amalgamation, is decided on the case alone.
+// Requires 'tahsildar' role
+func (c *LandRegistryContract) DecideMutation(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	decision string,
+	khataNo string,
+	remarks string,
+) (*MutationCase, error) {
+	if err := requireRole(ctx, "tahsildar"); err != nil {
+		return nil, fmt.Errorf("only tahsildars can decide mutations: %v", err)
+	}
+
+	decision = strings.ToUpper(strings.TrimSpace(decision))
+	if decision != "APPROVE" && decision != "REJECT" {
+		return nil, fmt.Errorf("invalid decision: %s (expected APPROVE or REJECT)", decision)
+	}
+	if decision == "APPROVE" && strings.TrimSpace(khataNo) == "" {
+		return nil, fmt.Errorf("khata/patta number is required to approve a mutation")
+	}
+
+	mutation, err := getMutationCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if mutation.Status != MutationNoticeIssued && mutation.Status != MutationObjectionFiled {
+		return nil, fmt.Errorf("mutation case %s is already %s", caseID, mutation.Status)
+	}
+	if (len(mutation.Objections) > 0 || decision == "REJECT") && strings.TrimSpace(remarks) == "" {
+		return nil, fmt.Errorf("remarks are required when rejecting or when objections were filed")
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	noticeEnds, err := time.Parse(time.RFC3339, mutation.NoticeEndsAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notice period on %s: %v", caseID, err)
+	}
+	if !txTime.After(noticeEnds) {
+		return nil, fmt.Errorf("notice period for %s runs until %s", caseID, mutation.NoticeEndsAt)
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+
+	landRecord, err := getLandRecord(ctx, mutation.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	eventName := EventMutationRejected
+	mutation.Status = MutationRejected
+	if decision == "APPROVE" {
+		eventName = EventMutationApproved
+		mutation.Status = MutationApproved
+		mutation.KhataNo = khataNo
+	}
+	mutation.DecidedBy = clientID
+	mutation.DecidedAt = txTime.Format(time.RFC3339)
+	mutation.DecisionRemarks = remarks
+
+	if err := putMutationCase(ctx, mutation); err != nil {
+		return nil, err
+	}
+	if landRecord.MutationCaseID == mutation.CaseID && landRecord.Status != RecordRetired {
+		if decision == "APPROVE" {
+			landRecord.RevenueOwnerID = mutation.ToPersonID
+			landRecord.KhataNo = khataNo
+		}
+		landRecord.MutationStatus = mutation.Status
+		landRecord.LastUpdated = txTime.Format("2006-01-02")
+		if err := putLandRecord(ctx, landRecord); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.emitMutationEvent(ctx, eventName, mutation, remarks); err != nil {
+		fmt.Printf("warning: failed to emit MutationEvent: %v\n", err)
+	}
+
+	return mutation, nil
+}
+
+// ReadMutationCase retrieves a mutation case by ID
+func (c *LandRegistryContract) ReadMutationCase(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+) (*MutationCase, error) {
+	return getMutationCase(ctx, caseID)
+}
+
+// openMutationCase issues mutation notice for a completed transfer
+// Called from ApproveTransfer; marks the LandRecord's revenue entry as pending.
+// The notice is announced by ApproveTransfer's PropertyTransferred event. The
+// buyer's parcel in an exchange gets a case of its own.
+func openMutationCase(
+	ctx contractapi.TransactionContextInterface,
+	transfer *TransferRequest,
+	landRecord *LandRecord,
+	txTime time.Time,
+) (*MutationCase, error) {
+	mutation := &MutationCase{
+		CaseID:         "MUT-" + strings.TrimPrefix(transfer.TransferID, "TRF-"),
+		PropertyID:     landRecord.PropertyID,
+		TransferID:     transfer.TransferID,
+		FromPersonID:   transfer.SellerID,
+		ToPersonID:     transfer.BuyerID,
+		Status:         MutationNoticeIssued,
+		NoticeIssuedAt: txTime.Format(time.RFC3339),
+		NoticeEndsAt:   txTime.Add(mutationNoticePeriod).Format(time.RFC3339),
+		Objections:     []MutationObjection{},
+	}
+	if landRecord.PropertyID == transfer.ExchangePropertyID {
+		mutation.CaseID += "-X"
+		mutation.FromPersonID, mutation.ToPersonID = transfer.BuyerID, transfer.SellerID
+	}
+
+	if err := putMutationCase(ctx, mutation); err != nil {
+		return nil, err
+	}
+
+	landRecord.MutationStatus = MutationNoticeIssued
+	landRecord.MutationCaseID = mutation.CaseID
+
+	return mutation, nil
+}
+
+func getMutationCase(ctx contractapi.TransactionContextInterface, caseID string) (*MutationCase, error) {
+	key, err := stateKey(ctx, DocTypeMutation, caseID)
+	if err != nil {
+		return nil, err
+	}
+	mutationJSON, err := getDocument(ctx, key, mutationKeyPrefix+caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mutation case: %v", err)
+	}
+	if mutationJSON == nil {
+		return nil, fmt.Errorf("mutation case %s does not exist", caseID)
+	}
+
+	var mutation MutationCase
+	if err := json.Unmarshal(mutationJSON, &mutation); err != nil {
+		return nil, fmt.Errorf("failed to parse mutation case: %v", err)
+	}
+	return &mutation, nil
+}
+
+func putMutationCase(ctx contractapi.TransactionContextInterface, mutation *MutationCase) error {
+	mutation.DocType = DocTypeMutation
+	key, err := stateKey(ctx, DocTypeMutation, mutation.CaseID)
+	if err != nil {
+		return err
+	}
+	if err := putDocument(ctx, key, mutationKeyPrefix+mutation.CaseID, mutation); err != nil {
+		return fmt.Errorf("failed to store mutation case: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestMutationObjectionsAndDecision(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, arjunID := s.personIDs["ravi"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+	tahsildar := testCreator(t, "StateOrgTSMSP", "tahsildar")
+
+	transfer := s.complete(s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+	}).(*TransferRequest), "arjun")
+	caseID := transfer.MutationCaseID
+	if landRecord := s.owners("CCLB-2025-TS-000001"); landRecord.MutationCaseID != caseID || landRecord.MutationStatus != MutationNoticeIssued {
+		t.Fatalf("record links mutation %s (%s), want %s with notice issued", landRecord.MutationCaseID, landRecord.MutationStatus, caseID)
+	}
+
+	object := func(txID string, grounds string) error {
+		_, err := s.step(txID, s.citizens["sita"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.FileMutationObjection(ctx, caseID, grounds, testDeedHash)
+		})
+		return err
+	}
+	decide := func(txID string, decision string, khataNo string, remarks string) (*MutationCase, error) {
+		endorsed, err := s.step(txID, tahsildar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.DecideMutation(ctx, caseID, decision, khataNo, remarks)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return endorsed.result.(*MutationCase), nil
+	}
+
+	if err := object("tx-object-blank", " "); err == nil || !strings.Contains(err.Error(), "grounds") {
+		t.Fatalf("objection without grounds: err = %v, want a grounds error", err)
+	}
+	if err := object("tx-object", "Boundary with survey 102 is disputed"); err != nil {
+		t.Fatalf("objection during the notice failed: %v", err)
+	}
+	if _, err := decide("tx-decide-early", "APPROVE", "KH-1042", "Objection heard"); err == nil || !strings.Contains(err.Error(), "notice period") {
+		t.Fatalf("decision inside the notice period: err = %v, want a notice period error", err)
+	}
+
+	s.txTime = s.txTime.Add(mutationNoticePeriod + time.Hour)
+	if err := object("tx-object-late", "Late objection"); err == nil || !strings.Contains(err.Error(), "ended") {
+		t.Fatalf("objection after the notice: err = %v, want it refused", err)
+	}
+	if _, err := decide("tx-decide-silent", "APPROVE", "KH-1042", ""); err == nil || !strings.Contains(err.Error(), "remarks") {
+		t.Fatalf("decision ignoring an objection: err = %v, want remarks required", err)
+	}
+	mutation, err := decide("tx-decide", "APPROVE", "KH-1042", "Boundary dispute is a civil matter")
+	if err != nil {
+		t.Fatalf("approval failed: %v", err)
+	}
+	if mutation.Status != MutationApproved || mutation.KhataNo != "KH-1042" {
+		t.Fatalf("mutation %s with khata %q, want approved as KH-1042", mutation.Status, mutation.KhataNo)
+	}
+	if landRecord := s.owners("CCLB-2025-TS-000001"); landRecord.RevenueOwnerID != arjunID || landRecord.KhataNo != "KH-1042" || landRecord.MutationStatus != MutationApproved {
+		t.Fatalf("revenue record %q/%q (%s), want Arjun under KH-1042", landRecord.RevenueOwnerID, landRecord.KhataNo, landRecord.MutationStatus)
+	}
+	if _, err := decide("tx-decide-again", "REJECT", "", "Second thoughts"); err == nil || !strings.Contains(err.Error(), "already") {
+		t.Fatalf("second decision: err = %v, want the case closed", err)
+	}
+}
+
+func TestSupersededMutationLeavesRevenueRecordAlone(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, sitaID, arjunID := s.personIDs["ravi"], s.personIDs["sita"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+	tahsildar := testCreator(t, "StateOrgTSMSP", "tahsildar")
+
+	first := s.complete(s.must("tx-initiate-1", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+	}).(*TransferRequest), "arjun")
+	// Arjun sells on inside the first notice period
+	second := s.complete(s.must("tx-initiate-2", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", sitaID, "50 L", testDeedHash)
+	}).(*TransferRequest), "sita")
+
+	s.txTime = s.txTime.Add(mutationNoticePeriod + time.Hour)
+	decided := s.must("tx-decide-first", tahsildar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.DecideMutation(ctx, first.MutationCaseID, "APPROVE", "KH-1042", "")
+	}).(*MutationCase)
+	if decided.Status != MutationApproved {
+		t.Fatalf("first mutation is %s, want it approved on the case", decided.Status)
+	}
+	landRecord := s.owners("CCLB-2025-TS-000001")
+	if landRecord.RevenueOwnerID != "" || landRecord.MutationCaseID != second.MutationCaseID || landRecord.MutationStatus != MutationNoticeIssued {
+		t.Fatalf("revenue record %q under %s (%s), want it left to pending case %s",
+			landRecord.RevenueOwnerID, landRecord.MutationCaseID, landRecord.MutationStatus, second.MutationCaseID)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// PropertyHolding is one land record held by a person
+type PropertyHolding struct {
+	Record  *LandRecord `json:"record"` // Public view; owner details stay in the private collection
+	Share   string      `json:"share"`  // Fraction of the parcel held, "1/1" for a sole owner
+	Tenancy string      `json:"tenancy"`
+	Leases  []*Lease    `json:"leases"` // Active leases over the parcel
+}
+
+// MyProperties is a person's current holdings and open transfers
+type MyProperties struct {
+	PersonID         string             `json:"personId"`
+	Holdings         []PropertyHolding  `json:"holdings"`
+	PendingTransfers []*TransferRequest `json:"pendingTransfers"` // As seller or buyer
+}
+
+// GetMyProperties returns the land records linked to the caller's PERSON_ ID
+// Co-owners are linked when the record is requested with their PERSON_ IDs,
+// on a completed transfer, or by LinkOwnerPerson; holdings known only by
+// name are not listed.
+func (c *LandRegistryContract) GetMyProperties(
+	ctx contractapi.TransactionContextInterface,
+) (*MyProperties, error) {
+	personID, err := callerPersonID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	indexIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(landPrivateCollection, landPersonIndex, []string{personID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s index: %v", landPersonIndex, err)
+	}
+	defer indexIterator.Close()
+
+	mine := &MyProperties{PersonID: personID, Holdings: []PropertyHolding{}}
+	for indexIterator.HasNext() {
+		queryResponse, err := indexIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		landRecord, err := readIndexedLandRecord(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		if landRecord == nil || landRecord.Status == RecordRetired {
+			continue
+		}
+
+		withDetails := *landRecord
+		if err := loadPrivateDetails(ctx, DocTypeLandRecord, landRecord.PropertyID, &withDetails); err != nil {
+			return nil, err
+		}
+		for _, coOwner := range withDetails.Owners {
+			if coOwner.PersonID != personID {
+				continue
+			}
+			leases, err := listActiveLeases(ctx, landRecord.PropertyID)
+			if err != nil {
+				return nil, err
+			}
+			mine.Holdings = append(mine.Holdings, PropertyHolding{
+				Record:  landRecord,
+				Share:   coOwner.Share,
+				Tenancy: landRecord.Tenancy,
+				Leases:  leases,
+			})
+		}
+	}
+
+	if mine.PendingTransfers, err = getOpenTransfersOf(ctx, personID); err != nil {
+		return nil, err
+	}
+	return mine, nil
+}
+
+// LinkOwnerPerson links one of a land record's co-owners to their registered PERSON_ ID
+// For co-owners recorded by name only. The person's registered name picks
+// the co-owner. No event is emitted, as the link is private.
+// Requires 'registrar' role
+func (c *LandRegistryContract) LinkOwnerPerson(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	personID string,
+) error {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return fmt.Errorf("only registrars can link owners: %v", err)
+	}
+
+	landRecord, err := c.readLandRecordWithDetails(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if landRecord.Status == RecordRetired {
+		return fmt.Errorf("land record %s is retired", propertyID)
+	}
+	person, err := getPerson(ctx, personID)
+	if err != nil {
+		return err
+	}
+	coOwner := findCoOwner(landRecord.Owners, person)
+	if coOwner < 0 {
+		return fmt.Errorf("person %s is not registered under the name of an owner of %s", personID, propertyID)
+	}
+	if landRecord.Owners[coOwner].PersonID != "" {
+		return fmt.Errorf("owner %s of %s is already linked", landRecord.Owners[coOwner].Name, propertyID)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return err
+	}
+	landRecord.Owners[coOwner].PersonID = personID
+	landRecord.LastUpdated = txTime.Format("2006-01-02")
+	return putLandRecord(ctx, landRecord)
+}
+
+// requireOwnerPerson checks that a PERSON_ ID is registered under the owner's name
+func requireOwnerPerson(ctx contractapi.TransactionContextInterface, personID string, owner string) error {
+	person, err := getPerson(ctx, personID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(strings.TrimSpace(person.Name), strings.TrimSpace(owner)) {
+		return fmt.Errorf("person %s is not registered under the owner's name", personID)
+	}
+	if person.DateOfDeath != "" {
+		return fmt.Errorf("person %s is deceased", personID)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestGetMyPropertiesFollowsLinkedOwner(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peer := newEndorser("peer0")
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	seller := testCreatorWithAttrs(t, "StateOrgTSMSP", map[string]string{"role": "citizen", "hf.EnrollmentID": "ravi"})
+	buyer := testCreatorWithAttrs(t, "StateOrgTSMSP", map[string]string{"role": "citizen", "hf.EnrollmentID": "sita"})
+	txTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	register := func(txID string, creator []byte, name string) string {
+		return peer.endorse(t, proposal{
+			txID:    txID,
+			creator: creator,
+			time:    txTime,
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.RegisterPerson(ctx, name)
+			},
+		}).result.(*Person).PersonID
+	}
+	sellerID := register("tx-register-seller", seller, "Ravi Kumar")
+	buyerID := register("tx-register-buyer", buyer, "Sita Devi")
+
+	private, _ := json.Marshal(privateDetailsInput{
+		Owner:         "Ravi Kumar",
+		OwnerPersonID: sellerID,
+		MarketValue:   "45 L",
+		Salt:          "3f1c9a7e5b2d8046",
+	})
+	requestID := peer.endorse(t, proposal{
+		txID:      "tx-request",
+		creator:   registrar,
+		time:      txTime,
+		transient: map[string][]byte{privateDetailsTransientKey: private},
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.RequestPropertyID(ctx, "TS", "", "101/A", "Rangareddy",
+				"Shamshabad", "Kothur", "2 acres", "agricultural", "", "")
+		},
+	}).result.(string)
+	peer.endorse(t, proposal{
+		txID:    "tx-bind",
+		creator: registrar,
+		time:    txTime,
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateStateRecord(ctx, "CCLB-2025-TS-000001", requestID, "")
+		},
+	})
+	peer.endorse(t, proposal{
+		txID:    "tx-initiate",
+		creator: seller,
+		time:    txTime.Add(time.Hour),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", buyerID, "50 L",
+				"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08")
+		},
+	})
+
+	myProperties := func(txID string, creator []byte) *MyProperties {
+		return peer.endorse(t, proposal{
+			txID:    txID,
+			creator: creator,
+			time:    txTime.Add(2 * time.Hour),
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.GetMyProperties(ctx)
+			},
+		}).result.(*MyProperties)
+	}
+
+	mine := myProperties("tx-mine-seller", seller)
+	if len(mine.Holdings) != 1 || mine.Holdings[0].Record.PropertyID != "CCLB-2025-TS-000001" ||
+		mine.Holdings[0].Share != "1/1" || mine.Holdings[0].Tenancy != TenancySole {
+		t.Fatalf("seller holdings = %+v, want the linked record", mine.Holdings)
+	}
+	if len(mine.PendingTransfers) != 1 {
+		t.Fatalf("seller pending transfers = %d, want 1", len(mine.PendingTransfers))
+	}
+
+	theirs := myProperties("tx-mine-buyer", buyer)
+	if len(theirs.Holdings) != 0 || len(theirs.PendingTransfers) != 1 || theirs.PendingTransfers[0].BuyerID != buyerID {
+		t.Fatalf("buyer view = %+v, want only the incoming transfer", theirs)
+	}
+}
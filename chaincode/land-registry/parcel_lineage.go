@@ -0,0 +1,549 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// LineageOperation records one subdivision or amalgamation
+// Stored under composite key LINEAGE~<operationID>; the affected LandRecords carry
+// ParentIDs/ChildIDs so the family tree can be walked in both directions.
+type LineageOperation struct {
+	DocType     string   `json:"docType"` // LINEAGE
+	OperationID string   `json:"operationId"`
+	Type        string   `json:"type"` // SUBDIVISION, AMALGAMATION, PARTITION
+	ParentIDs   []string `json:"parentIds"`
+	ChildIDs    []string `json:"childIds"`
+	PerformedBy string   `json:"performedBy"`
+	Timestamp   string   `json:"timestamp"`
+	TxID        string   `json:"txId"`
+}
+
+// SubdivisionChild describes one child parcel in a SubdivideProperty request
+// PropertyID must already be issued by CCLB (IssuePropertyID on cclb-global)
+type SubdivisionChild struct {
+	PropertyID  string `json:"propertyId"`
+	SurveyNo    string `json:"surveyNo"` // e.g. 123/1
+	Area        string `json:"area"`
+	MarketValue string `json:"marketValue"`          // Required, so the child can be assessed for tax
+	AllotteeID  string `json:"allotteeId,omitempty"` // Partition deeds only: PERSON_ ID of the co-owner who takes this parcel
+}
+
+// PropertyLineage is the family tree around a property
+type PropertyLineage struct {
+	PropertyID  string         `json:"propertyId"`
+	Ancestors   []*LineageNode `json:"ancestors"`
+	Descendants []*LineageNode `json:"descendants"`
+}
+
+// LineageNode is one parcel in a PropertyLineage walk
+type LineageNode struct {
+	PropertyID string   `json:"propertyId"`
+	SurveyNo   string   `json:"surveyNo"`
+	Area       Area     `json:"area"`
+	Status     string   `json:"status"`
+	ParentIDs  []string `json:"parentIds"`
+	ChildIDs   []string `json:"childIds"`
+	Depth      int      `json:"depth"` // Generations away from the queried property
+}
+
+// Lineage operation types and land record statuses
+const (
+	LineageSubdivision  = "SUBDIVISION"
+	LineageAmalgamation = "AMALGAMATION"
+	LineagePartition    = "PARTITION" // Subdivision among co-owners by a partition deed
+
+	RecordActive  = "ACTIVE"
+	RecordRetired = "RETIRED"
+)
+
+const lineageKeyPrefix = "LINEAGE_" // Pre-migration flat key prefix
+
+// SubdivideProperty retires a parent parcel and creates child parcels
+// childrenJSON is a JSON array of SubdivisionChild; child areas must sum to the parent's
+// Requires 'registrar' role
+func (c *LandRegistryContract) SubdivideProperty(
+	ctx contractapi.TransactionContextInterface,
+	parentID string,
+	childrenJSON string,
+) ([]*LandRecord, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can subdivide parcels: %v", err)
+	}
+
+	var children []SubdivisionChild
+	if err := json.Unmarshal([]byte(childrenJSON), &children); err != nil {
+		return nil, fmt.Errorf("invalid children JSON: %v", err)
+	}
+	for _, child := range children {
+		if child.AllotteeID != "" {
+			return nil, fmt.Errorf("child %s: parcels are allotted to co-owners by a partition deed, not a subdivision", child.PropertyID)
+		}
+	}
+
+	parent, err := c.readLandRecordWithDetails(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkParcelChangeable(ctx, parent); err != nil {
+		return nil, err
+	}
+
+	records, operation, err := subdivideParcel(ctx, parent, children, LineageSubdivision)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.emitLineageEvent(ctx, EventPropertySubdivided, operation); err != nil {
+		fmt.Printf("warning: failed to emit LineageEvent: %v\n", err)
+	}
+
+	return records, nil
+}
+
+// subdivideParcel retires a parent parcel and creates its children
+// Child areas must sum to the parent's. A child with an AllotteeID is held
+// solely by that co-owner of the parent; the rest keep the parent's owners.
+// Callers check the parent can change and emit the event.
+func subdivideParcel(
+	ctx contractapi.TransactionContextInterface,
+	parent *LandRecord,
+	children []SubdivisionChild,
+	operationType string,
+) ([]*LandRecord, *LineageOperation, error) {
+	parentID := parent.PropertyID
+	if len(children) < 2 {
+		return nil, nil, fmt.Errorf("a subdivision needs at least two child parcels")
+	}
+	if err := parent.Area.requireTyped("parent area"); err != nil {
+		return nil, nil, err
+	}
+
+	total := new(big.Rat)
+	childIDs := make([]string, 0, len(children))
+	areas := make([]Area, 0, len(children))
+	values := make([]Money, 0, len(children))
+	seen := map[string]bool{}
+	surveys := map[string]bool{}
+	for _, child := range children {
+		if child.PropertyID == "" || strings.TrimSpace(child.SurveyNo) == "" {
+			return nil, nil, fmt.Errorf("every child needs a propertyId and surveyNo")
+		}
+		if seen[child.PropertyID] || child.PropertyID == parentID {
+			return nil, nil, fmt.Errorf("duplicate child property ID %s", child.PropertyID)
+		}
+		seen[child.PropertyID] = true
+		if err := requireUnusedPropertyID(ctx, child.PropertyID); err != nil {
+			return nil, nil, err
+		}
+
+		// Children stay in the parent's village, each under its own survey number
+		lookup := newLandLookup(parent)
+		lookup.SurveyNo = normalizeLookup(child.SurveyNo)
+		if surveys[lookup.SurveyNo] {
+			return nil, nil, fmt.Errorf("duplicate child survey number %s", child.SurveyNo)
+		}
+		surveys[lookup.SurveyNo] = true
+		if err := requireSurveyAvailable(ctx, lookup, "", []string{parentID}); err != nil {
+			return nil, nil, fmt.Errorf("child %s: %v", child.PropertyID, err)
+		}
+
+		area, err := parseArea(child.Area)
+		if err != nil {
+			return nil, nil, fmt.Errorf("child %s: %v", child.PropertyID, err)
+		}
+		value, err := parseRupees(child.MarketValue)
+		if err != nil {
+			return nil, nil, fmt.Errorf("child %s: invalid market value: %v", child.PropertyID, err)
+		}
+		if err := value.requireTyped("market value"); err != nil {
+			return nil, nil, fmt.Errorf("child %s: %v", child.PropertyID, err)
+		}
+		if child.AllotteeID != "" && findCoOwner(parent.Owners, &Person{PersonID: child.AllotteeID}) < 0 {
+			return nil, nil, fmt.Errorf("child %s: allottee %s is not a co-owner of %s", child.PropertyID, child.AllotteeID, parentID)
+		}
+		total.Add(total, area.squareMetres())
+		childIDs = append(childIDs, child.PropertyID)
+		areas = append(areas, area)
+		values = append(values, value)
+	}
+	// Compared in exact square metres so children may be surveyed in a different unit
+	if total.Cmp(parent.Area.squareMetres()) != 0 {
+		return nil, nil, fmt.Errorf("child areas sum to %s but parent %s is %s",
+			areaFromSquareMetres(total, parent.Area.Unit), parentID, parent.Area)
+	}
+
+	operation, err := newLineageOperation(ctx, operationType, []string{parentID}, childIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Retire first so the parent's survey claim is released before children claim theirs
+	base := *parent
+	if err := retireParcel(ctx, parent, childIDs, operation); err != nil {
+		return nil, nil, err
+	}
+
+	records := make([]*LandRecord, 0, len(children))
+	for i, child := range children {
+		record := base
+		record.PropertyID = child.PropertyID
+		record.SurveyNo = strings.TrimSpace(child.SurveyNo)
+		record.Area = areas[i]
+		record.MarketValue = values[i]
+		record.LastUpdated = operation.Timestamp[:10]
+		record.VerifiedByCCLB = false
+		record.CCLBVerifyTx = ""
+		record.VerificationStatus = VerificationPending
+		record.VerificationReason = ""
+		record.MutationCaseID = ""
+		record.Status = RecordActive
+		record.ParentIDs = []string{parentID}
+		record.ChildIDs = nil
+		record.LineageOperationID = operation.OperationID
+		if child.AllotteeID != "" {
+			allottee := base.Owners[findCoOwner(base.Owners, &Person{PersonID: child.AllotteeID})]
+			record.Owners = soleOwner(allottee.Name, allottee.PersonID)
+			record.Tenancy = TenancySole
+			record.OwnerIDNumber = "" // The parent's was not necessarily the allottee's
+		}
+
+		if err := putLandRecord(ctx, &record); err != nil {
+			return nil, nil, err
+		}
+		records = append(records, &record)
+	}
+
+	return records, operation, nil
+}
+
+// AmalgamateProperties merges adjacent parcels of one owner into a new parcel
+// parentIDsJSON is a JSON array of Property IDs in the same village;
+// childPropertyID must already be issued by CCLB.
+// Requires 'registrar' role
+func (c *LandRegistryContract) AmalgamateProperties(
+	ctx contractapi.TransactionContextInterface,
+	parentIDsJSON string,
+	childPropertyID string,
+	surveyNo string,
+) (*LandRecord, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can amalgamate parcels: %v", err)
+	}
+
+	var parentIDs []string
+	if err := json.Unmarshal([]byte(parentIDsJSON), &parentIDs); err != nil {
+		return nil, fmt.Errorf("invalid parent IDs JSON: %v", err)
+	}
+	if len(parentIDs) < 2 {
+		return nil, fmt.Errorf("an amalgamation needs at least two parent parcels")
+	}
+	if strings.TrimSpace(surveyNo) == "" {
+		return nil, fmt.Errorf("survey number is required")
+	}
+	if err := requireUnusedPropertyID(ctx, childPropertyID); err != nil {
+		return nil, err
+	}
+
+	var parents []*LandRecord
+	var value Money
+	total := new(big.Rat)
+	seen := map[string]bool{}
+	for _, parentID := range parentIDs {
+		if seen[parentID] {
+			return nil, fmt.Errorf("duplicate parent property ID %s", parentID)
+		}
+		seen[parentID] = true
+
+		parent, err := c.readLandRecordWithDetails(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkParcelChangeable(ctx, parent); err != nil {
+			return nil, err
+		}
+
+		if len(parents) > 0 {
+			first := parents[0]
+			if !sameOwners(parent.Owners, first.Owners) || parent.Tenancy != first.Tenancy {
+				return nil, fmt.Errorf("parcels %s and %s have different owners", first.PropertyID, parentID)
+			}
+			if parent.StateCode != first.StateCode ||
+				!strings.EqualFold(parent.District, first.District) ||
+				!strings.EqualFold(parent.Mandal, first.Mandal) ||
+				!strings.EqualFold(parent.Village, first.Village) {
+				return nil, fmt.Errorf("parcels %s and %s are not in the same village", first.PropertyID, parentID)
+			}
+			if !strings.EqualFold(parent.LandType, first.LandType) {
+				return nil, fmt.Errorf("parcels %s and %s have different land types", first.PropertyID, parentID)
+			}
+		}
+
+		if err := parent.Area.requireTyped("area of " + parentID); err != nil {
+			return nil, err
+		}
+		if err := parent.MarketValue.requireTyped("market value of " + parentID); err != nil {
+			return nil, err
+		}
+		total.Add(total, parent.Area.squareMetres())
+		value.Paise += parent.MarketValue.Paise
+		parents = append(parents, parent)
+	}
+
+	lookup := newLandLookup(parents[0])
+	lookup.SurveyNo = normalizeLookup(surveyNo)
+	if err := requireSurveyAvailable(ctx, lookup, "", parentIDs); err != nil {
+		return nil, err
+	}
+
+	operation, err := newLineageOperation(ctx, LineageAmalgamation, parentIDs, []string{childPropertyID})
+	if err != nil {
+		return nil, err
+	}
+
+	// Retire first so a parent's survey claim is released before the child claims one
+	child := *parents[0]
+	for _, parent := range parents {
+		if err := retireParcel(ctx, parent, []string{childPropertyID}, operation); err != nil {
+			return nil, err
+		}
+	}
+
+	child.PropertyID = childPropertyID
+	child.SurveyNo = strings.TrimSpace(surveyNo)
+	child.Area = areaFromSquareMetres(total, parents[0].Area.Unit)
+	child.MarketValue = value
+	child.LastUpdated = operation.Timestamp[:10]
+	child.VerifiedByCCLB = false
+	child.CCLBVerifyTx = ""
+	child.VerificationStatus = VerificationPending
+	child.VerificationReason = ""
+	child.MutationCaseID = ""
+	child.Status = RecordActive
+	child.ParentIDs = parentIDs
+	child.ChildIDs = nil
+	child.LineageOperationID = operation.OperationID
+
+	if err := putLandRecord(ctx, &child); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitLineageEvent(ctx, EventPropertiesAmalgamated, operation); err != nil {
+		fmt.Printf("warning: failed to emit LineageEvent: %v\n", err)
+	}
+
+	return &child, nil
+}
+
+// GetPropertyLineage walks parents and children of a property to any depth
+func (c *LandRegistryContract) GetPropertyLineage(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) (*PropertyLineage, error) {
+	root, err := getLandRecord(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors, err := walkLineage(ctx, c, root, func(r *LandRecord) []string { return r.ParentIDs })
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := walkLineage(ctx, c, root, func(r *LandRecord) []string { return r.ChildIDs })
+	if err != nil {
+		return nil, err
+	}
+
+	return &PropertyLineage{
+		PropertyID:  propertyID,
+		Ancestors:   ancestors,
+		Descendants: descendants,
+	}, nil
+}
+
+// ReadLineageOperation retrieves a subdivision/amalgamation record
+func (c *LandRegistryContract) ReadLineageOperation(
+	ctx contractapi.TransactionContextInterface,
+	operationID string,
+) (*LineageOperation, error) {
+	key, err := stateKey(ctx, DocTypeLineage, operationID)
+	if err != nil {
+		return nil, err
+	}
+	operationJSON, err := getDocument(ctx, key, lineageKeyPrefix+operationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lineage operation: %v", err)
+	}
+	if operationJSON == nil {
+		return nil, fmt.Errorf("lineage operation %s does not exist", operationID)
+	}
+
+	var operation LineageOperation
+	if err := json.Unmarshal(operationJSON, &operation); err != nil {
+		return nil, fmt.Errorf("failed to parse lineage operation: %v", err)
+	}
+	return &operation, nil
+}
+
+// walkLineage does a breadth-first walk in one direction (parents or children)
+func walkLineage(
+	ctx contractapi.TransactionContextInterface,
+	c *LandRegistryContract,
+	root *LandRecord,
+	next func(*LandRecord) []string,
+) ([]*LineageNode, error) {
+	nodes := []*LineageNode{}
+	visited := map[string]bool{root.PropertyID: true}
+
+	type pending struct {
+		id    string
+		depth int
+	}
+	var queue []pending
+	for _, id := range next(root) {
+		queue = append(queue, pending{id, 1})
+	}
+
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+		if visited[item.id] {
+			continue
+		}
+		visited[item.id] = true
+
+		record, err := getLandRecord(ctx, item.id)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &LineageNode{
+			PropertyID: record.PropertyID,
+			SurveyNo:   record.SurveyNo,
+			Area:       record.Area,
+			Status:     record.Status,
+			ParentIDs:  record.ParentIDs,
+			ChildIDs:   record.ChildIDs,
+			Depth:      item.depth,
+		})
+		for _, id := range next(record) {
+			queue = append(queue, pending{id, item.depth + 1})
+		}
+	}
+
+	return nodes, nil
+}
+
+// checkParcelChangeable rejects retired parcels and parcels with open transfers,
+// court orders, unpaid tax, leases or charges
+func checkParcelChangeable(ctx contractapi.TransactionContextInterface, record *LandRecord) error {
+	if record.Status == RecordRetired {
+		return fmt.Errorf("land record %s is retired", record.PropertyID)
+	}
+
+	active, err := getActiveTransferID(ctx, record.PropertyID)
+	if err != nil {
+		return err
+	}
+	if active != "" {
+		return fmt.Errorf("property %s has an open transfer %s", record.PropertyID, active)
+	}
+
+	if err := requireNotFrozen(ctx, record.PropertyID); err != nil {
+		return err
+	}
+	// Arrears left on a retired parent could never be collected through a transfer
+	if err := checkNoTaxDues(ctx, record.PropertyID); err != nil {
+		return err
+	}
+	if err := requireNoActiveLeases(ctx, record.PropertyID); err != nil {
+		return err
+	}
+	return requireNoActiveEncumbrances(ctx, record.PropertyID)
+}
+
+// requireNoActiveEncumbrances fails while a parcel carries a charge that would not follow it into new parcels
+func requireNoActiveEncumbrances(ctx contractapi.TransactionContextInterface, propertyID string) error {
+	encumbrances, err := listEncumbrances(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	for _, encumbrance := range encumbrances {
+		if encumbrance.Status == EncumbranceActive {
+			return fmt.Errorf("property %s has an active %s (%s)", propertyID, encumbrance.Type, encumbrance.EncumbranceID)
+		}
+	}
+	return nil
+}
+
+// requireUnusedPropertyID ensures a CCLB-issued ID is not already bound on this channel
+func requireUnusedPropertyID(ctx contractapi.TransactionContextInterface, propertyID string) error {
+	if propertyID == "" {
+		return fmt.Errorf("property ID is required")
+	}
+	existing, err := getLandRecordJSON(ctx, propertyID)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("property ID %s is already in use", propertyID)
+	}
+	return nil
+}
+
+// retireParcel marks a parent parcel RETIRED and links it to its children
+func retireParcel(
+	ctx contractapi.TransactionContextInterface,
+	parent *LandRecord,
+	childIDs []string,
+	operation *LineageOperation,
+) error {
+	parent.Status = RecordRetired
+	parent.ChildIDs = append(parent.ChildIDs, childIDs...)
+	parent.LineageOperationID = operation.OperationID
+	parent.LastUpdated = operation.Timestamp[:10]
+	return putLandRecord(ctx, parent)
+}
+
+// newLineageOperation builds and stores the lineage record for this transaction
+func newLineageOperation(
+	ctx contractapi.TransactionContextInterface,
+	operationType string,
+	parentIDs []string,
+	childIDs []string,
+) (*LineageOperation, error) {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txID := ctx.GetStub().GetTxID()
+
+	// A tx splits or merges parcels once, so its whole tx ID names the operation
+	operation := &LineageOperation{
+		DocType:     DocTypeLineage,
+		OperationID: "LIN-" + txID,
+		Type:        operationType,
+		ParentIDs:   parentIDs,
+		ChildIDs:    childIDs,
+		PerformedBy: clientID,
+		Timestamp:   txTime.Format(time.RFC3339),
+		TxID:        txID,
+	}
+
+	key, err := stateKey(ctx, DocTypeLineage, operation.OperationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := putDocument(ctx, key, "", operation); err != nil {
+		return nil, fmt.Errorf("failed to store lineage operation: %v", err)
+	}
+	return operation, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestLineageKeepsParcelsAssessable(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID := s.personIDs["ravi"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+	s.record("CCLB-2025-TS-000002", "101/B", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+	taxOfficer := testCreator(t, "StateOrgTSMSP", "tax_officer")
+	s.must("tx-rates", taxOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.SetTaxRateTable(ctx, "2025-26", "{}", 50)
+	})
+	assess := func(txID string, propertyID string) (endorsement, error) {
+		return s.step(txID, taxOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.AssessPropertyTax(ctx, propertyID, "2025-26")
+		})
+	}
+
+	// Arrears must be cleared before the parcel is carved up
+	assessed, err := assess("tx-assess-parent", "CCLB-2025-TS-000001")
+	if err != nil {
+		t.Fatalf("assessment failed: %v", err)
+	}
+	if _, err := s.step("tx-amalgamate-in-arrears", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.AmalgamateProperties(ctx, `["CCLB-2025-TS-000001","CCLB-2025-TS-000002"]`, "CCLB-2025-TS-000003", "101")
+	}); err == nil || !strings.Contains(err.Error(), "unpaid property tax") {
+		t.Fatalf("amalgamation with tax due: err = %v, want a tax dues error", err)
+	}
+	s.must("tx-pay", taxOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RecordTaxPayment(ctx, "CCLB-2025-TS-000001", "2025-26", assessed.result.(*TaxAssessment).TaxDuePaise, "RCPT-0001")
+	})
+
+	merged := s.must("tx-amalgamate", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.AmalgamateProperties(ctx, `["CCLB-2025-TS-000001","CCLB-2025-TS-000002"]`, "CCLB-2025-TS-000003", "101")
+	}).(*LandRecord)
+	if merged := s.owners(merged.PropertyID); merged.MarketValue.Paise != 2*4500000*100 {
+		t.Fatalf("amalgamated market value %d paise, want the parents' 90 L", merged.MarketValue.Paise)
+	}
+	mergedTax, err := assess("tx-assess-merged", "CCLB-2025-TS-000003")
+	if err != nil {
+		t.Fatalf("assessment of the amalgamated parcel failed: %v", err)
+	}
+	s.must("tx-pay-merged", taxOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RecordTaxPayment(ctx, "CCLB-2025-TS-000003", "2025-26", mergedTax.result.(*TaxAssessment).TaxDuePaise, "RCPT-0002")
+	})
+	if _, err := assess("tx-assess-retired", "CCLB-2025-TS-000002"); err == nil || !strings.Contains(err.Error(), "retired") {
+		t.Fatalf("assessment of a retired parcel: err = %v, want it refused", err)
+	}
+
+	if _, err := s.step("tx-subdivide-unvalued", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.SubdivideProperty(ctx, "CCLB-2025-TS-000003", `[
+			{"propertyId":"CCLB-2025-TS-000011","surveyNo":"101/1","area":"2 acres","marketValue":"45 L"},
+			{"propertyId":"CCLB-2025-TS-000012","surveyNo":"101/2","area":"2 acres"}]`)
+	}); err == nil || !strings.Contains(err.Error(), "market value") {
+		t.Fatalf("subdivision with an unvalued child: err = %v, want a market value error", err)
+	}
+}
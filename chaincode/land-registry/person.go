@@ -15,16 +15,17 @@ func (c *LandRegistryContract) RegisterPerson(
 	name string,
 ) (*Person, error) {
 
-	clientID, err := ctx.GetClientIdentity().GetID()
-if err != nil {
-    return nil, err
-}
-
-// 🔑 make clientID ledger-safe
-hash := sha256.Sum256([]byte(clientID))
-personKey := "PERSON_" + hex.EncodeToString(hash[:])
+	personKey, err := callerPersonID(ctx)
+	if err != nil {
+		return nil, err
+	}
 	
-	existing, _ := ctx.GetStub().GetState(personKey)
+	personStateKey, err := stateKey(ctx, DocTypePerson, personKey)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, _ := getDocument(ctx, personStateKey, personKey)
 	if existing != nil {
 		return nil, fmt.Errorf("person already registered")
 	}
@@ -32,14 +33,128 @@ personKey := "PERSON_" + hex.EncodeToString(hash[:])
 	role, _, _ := ctx.GetClientIdentity().GetAttributeValue("role")
 
 	person := Person{
-		PersonID: personKey,
-		Name:     name,
-		Role:     role,
+		DocType:       DocTypePerson,
+		SchemaVersion: currentSchemaVersion(DocTypePerson),
+		PersonID:      personKey,
+		Name:          name,
+		Role:          role,
+	}
+
+	if err := putPerson(ctx, &person); err != nil {
+		return nil, err
+	}
+
+	return &person, nil
+}
+
+// callerPersonID derives the PERSON_ ID for the calling identity
+func callerPersonID(ctx contractapi.TransactionContextInterface) (string, error) {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return "", err
 	}
 
-	data, _ := json.Marshal(person)
-	ctx.GetStub().PutState(personKey, data)
+	// 🔑 make clientID ledger-safe
+	hash := sha256.Sum256([]byte(clientID))
+	return "PERSON_" + hex.EncodeToString(hash[:]), nil
+}
+
+// getPerson loads a registered person by PERSON_ ID
+func getPerson(ctx contractapi.TransactionContextInterface, personID string) (*Person, error) {
+	key, err := stateKey(ctx, DocTypePerson, personID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := getDocument(ctx, key, personID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read person: %v", err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("person %s is not registered", personID)
+	}
 
+	var person Person
+	if err := decodeDocument(DocTypePerson, data, &person); err != nil {
+		return nil, fmt.Errorf("failed to parse person: %v", err)
+	}
+	// Persons stored before names moved to the private collection have them inline
+	if person.Name == "" {
+		details, err := getPersonPrivateDetails(ctx, personID)
+		if err != nil {
+			return nil, err
+		}
+		person.Name = details.Name
+	}
 	return &person, nil
 }
 
+// putPerson stores an updated person under its PERSON_ ID
+// The name goes to the private collection; the public document has none.
+func putPerson(ctx contractapi.TransactionContextInterface, person *Person) error {
+	person.DocType = DocTypePerson
+	person.SchemaVersion = currentSchemaVersion(DocTypePerson)
+	if person.Name != "" {
+		if err := putPersonPrivateDetails(ctx, person); err != nil {
+			return err
+		}
+	}
+
+	key, err := stateKey(ctx, DocTypePerson, person.PersonID)
+	if err != nil {
+		return err
+	}
+	public := *person
+	public.Name = ""
+	if err := putDocument(ctx, key, person.PersonID, &public); err != nil {
+		return fmt.Errorf("failed to store person: %v", err)
+	}
+	return nil
+}
+
+// PersonPrivateDetails is the private half of a registered person
+type PersonPrivateDetails struct {
+	DocType    string `json:"docType"`    // PRIVATE_DETAILS
+	RecordType string `json:"recordType"` // PERSON
+	ID         string `json:"id"`         // PERSON_ ID
+	Name       string `json:"name"`
+}
+
+func putPersonPrivateDetails(ctx contractapi.TransactionContextInterface, person *Person) error {
+	key, err := stateKey(ctx, DocTypePrivateDetails, DocTypePerson, person.PersonID)
+	if err != nil {
+		return err
+	}
+	detailsJSON, err := json.Marshal(PersonPrivateDetails{
+		DocType:    DocTypePrivateDetails,
+		RecordType: DocTypePerson,
+		ID:         person.PersonID,
+		Name:       person.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal private details: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(landPrivateCollection, key, detailsJSON); err != nil {
+		return fmt.Errorf("failed to store private details: %v", err)
+	}
+	return nil
+}
+
+func getPersonPrivateDetails(ctx contractapi.TransactionContextInterface, personID string) (*PersonPrivateDetails, error) {
+	key, err := stateKey(ctx, DocTypePrivateDetails, DocTypePerson, personID)
+	if err != nil {
+		return nil, err
+	}
+	detailsJSON, err := ctx.GetStub().GetPrivateData(landPrivateCollection, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private details: %v", err)
+	}
+	if detailsJSON == nil {
+		return nil, fmt.Errorf("private details of %s are not available on this peer", personID)
+	}
+
+	var details PersonPrivateDetails
+	if err := json.Unmarshal(detailsJSON, &details); err != nil {
+		return nil, fmt.Errorf("failed to parse private details: %v", err)
+	}
+	return &details, nil
+}
@@ -1,8 +1,12 @@
 package main
 
 type Person struct {
-	PersonID string `json:"personId"`
-	Name     string `json:"name"`
-	Role     string `json:"role"`
-}
+	DocType       string `json:"docType"` // PERSON
+	SchemaVersion int    `json:"schemaVersion"`
+	PersonID      string `json:"personId"`
+	Name          string `json:"name,omitempty"` // Private: in landPrivateCollection; empty on the public document
+	Role          string `json:"role"`
 
+	DateOfDeath      string `json:"dateOfDeath,omitempty"`      // Set by RegisterDeath
+	SuccessionCaseID string `json:"successionCaseId,omitempty"` // Succession case for the deceased
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// landPrivateCollection holds owner PII and valuations (see collections_config.template.json)
+// Only salted hashes of these fields stay on the public LandRecord.
+const landPrivateCollection = "landPrivateDetails"
+
+// privateDetailsTransientKey is the transient map entry carrying private inputs
+// Transient data is not recorded in the block, unlike transaction arguments.
+const privateDetailsTransientKey = "privateDetails"
+
+// minSaltLength is the shortest salt accepted from clients
+const minSaltLength = 16
+
+// Private fields, as named in LandRecord.PrivateHashes
+const (
+	PrivateFieldOwner         = "owner"
+	PrivateFieldOwnerIDNumber = "ownerIdNumber"
+	PrivateFieldMarketValue   = "marketValue"
+)
+
+// LandPrivateDetails is the private half of a land record or draft
+type LandPrivateDetails struct {
+	DocType       string    `json:"docType"`    // PRIVATE_DETAILS
+	RecordType    string    `json:"recordType"` // LAND_RECORD or DRAFT, or SUCCESSION for a case's heirs
+	ID            string    `json:"id"`         // Property ID, request ID of a draft, or case ID
+	Owner         string    `json:"owner"`      // Co-owner names, see ownerSummary
+	Owners        []CoOwner `json:"owners,omitempty"`
+	OwnerIDNumber string    `json:"ownerIdNumber,omitempty"` // Aadhaar-like identifier
+	OwnerPersonID string    `json:"ownerPersonId,omitempty"` // Deprecated: sole owner's PERSON_ ID before Owners
+	MarketValue   Money     `json:"marketValue"`
+	Salt          string    `json:"salt"` // Disclosed together with a value to verify it
+}
+
+// privateDetailsInput is the transient map form of a record's private details
+// Either Owner (with an optional OwnerPersonID) or Owners and Tenancy is given.
+type privateDetailsInput struct {
+	Owner         string    `json:"owner"`
+	OwnerPersonID string    `json:"ownerPersonId"`
+	Owners        []CoOwner `json:"owners"`
+	Tenancy       string    `json:"tenancy"`
+	OwnerIDNumber string    `json:"ownerIdNumber"`
+	MarketValue   string    `json:"marketValue"`
+	Salt          string    `json:"salt"`
+}
+
+// ReadLandPrivateDetails returns the owner PII and valuation of a land record
+// Only peers and clients of the collection's member orgs can read it.
+// Requires a state official role
+func (c *LandRegistryContract) ReadLandPrivateDetails(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) (*LandPrivateDetails, error) {
+	if err := requireRole(ctx, jurisdictionRoles...); err != nil {
+		return nil, fmt.Errorf("only state officials can read private details: %v", err)
+	}
+
+	landRecord, err := getLandRecord(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(landRecord.PrivateHashes) == 0 {
+		return nil, fmt.Errorf("land record %s has not been moved to the private collection (run MigrateRecords)", propertyID)
+	}
+
+	details, err := getPrivateDetails(ctx, DocTypeLandRecord, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, fmt.Errorf("private details of %s are not available on this peer", propertyID)
+	}
+	return details, nil
+}
+
+// VerifyPrivateDetail checks a disclosed value against the salted hash on a land record
+// field is owner (co-owner names joined by ", "), ownerIdNumber or marketValue; the salt comes from whoever
+// disclosed the value (an official reading ReadLandPrivateDetails).
+func (c *LandRegistryContract) VerifyPrivateDetail(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	field string,
+	value string,
+	salt string,
+) (bool, error) {
+	landRecord, err := getLandRecord(ctx, propertyID)
+	if err != nil {
+		return false, err
+	}
+	hash, ok := landRecord.PrivateHashes[field]
+	if !ok {
+		return false, fmt.Errorf("land record %s has no hash for %s", propertyID, field)
+	}
+
+	canonical, err := canonicalPrivateValue(field, value)
+	if err != nil {
+		return false, err
+	}
+	return privateFieldHash(salt, field, canonical) == hash, nil
+}
+
+// readLandRecordWithDetails reads a land record with its private details filled in
+func (c *LandRegistryContract) readLandRecordWithDetails(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) (*LandRecord, error) {
+	landRecord, err := getLandRecord(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadPrivateDetails(ctx, DocTypeLandRecord, propertyID, landRecord); err != nil {
+		return nil, err
+	}
+	return landRecord, nil
+}
+
+// loadPrivateDetails fills in a record's owners, identifier and market value
+// Records written before the private collection still hold them inline, and
+// records written before co-ownership have a single Owner.
+func loadPrivateDetails(ctx contractapi.TransactionContextInterface, docType string, id string, record *LandRecord) error {
+	legacyPersonID := ""
+	if len(record.PrivateHashes) > 0 {
+		details, err := getPrivateDetails(ctx, docType, id)
+		if err != nil {
+			return err
+		}
+		if details == nil {
+			return fmt.Errorf("private details of %s are not available on this peer", id)
+		}
+		record.Owner = details.Owner
+		record.Owners = details.Owners
+		record.OwnerIDNumber = details.OwnerIDNumber
+		record.MarketValue = details.MarketValue
+		record.privateSalt = details.Salt
+		legacyPersonID = details.OwnerPersonID
+	}
+
+	if len(record.Owners) == 0 && record.Owner != "" {
+		record.Owners = soleOwner(record.Owner, legacyPersonID)
+	}
+	return nil
+}
+
+// storePrivateDetails moves a record's private fields into the collection
+// The record is left as its public view: private fields cleared and salted
+// hashes in PrivateHashes. Records read without their details keep the hashes
+// they have. Land records are also indexed by owner inside the collection.
+func storePrivateDetails(ctx contractapi.TransactionContextInterface, docType string, id string, record *LandRecord) error {
+	if record.Owner == "" && len(record.Owners) == 0 && record.OwnerIDNumber == "" && record.MarketValue == (Money{}) {
+		return nil
+	}
+	if len(record.Owners) == 0 && record.Owner != "" {
+		record.Owners = soleOwner(record.Owner, "")
+	}
+	if len(record.Owners) > 0 {
+		record.Owner = ownerSummary(record.Owners)
+	}
+
+	previous, err := getPrivateDetails(ctx, docType, id)
+	if err != nil {
+		return err
+	}
+	salt := record.privateSalt
+	if salt == "" && previous != nil {
+		salt = previous.Salt
+	}
+	if salt == "" {
+		// Only reached for values that were already public (legacy records and
+		// argument-based requests), so a salt anyone can derive exposes nothing new
+		salt = derivedSalt(ctx, id)
+	}
+
+	details := LandPrivateDetails{
+		DocType:       DocTypePrivateDetails,
+		RecordType:    docType,
+		ID:            id,
+		Owner:         record.Owner,
+		Owners:        record.Owners,
+		OwnerIDNumber: record.OwnerIDNumber,
+		MarketValue:   record.MarketValue,
+		Salt:          salt,
+	}
+	key, err := stateKey(ctx, DocTypePrivateDetails, docType, id)
+	if err != nil {
+		return err
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal private details: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(landPrivateCollection, key, detailsJSON); err != nil {
+		return fmt.Errorf("failed to store private details: %v", err)
+	}
+
+	if docType == DocTypeLandRecord {
+		if err := indexLandOwner(ctx, previous, &details); err != nil {
+			return err
+		}
+	}
+
+	record.PrivateHashes = details.hashes()
+	record.Owner = ""
+	record.Owners = nil
+	record.OwnerIDNumber = ""
+	record.MarketValue = Money{}
+	record.privateSalt = ""
+	return nil
+}
+
+// indexLandOwner keeps the owner and person indexes inside the private collection
+// Every co-owner has an entry in both.
+func indexLandOwner(ctx contractapi.TransactionContextInterface, previous *LandPrivateDetails, details *LandPrivateDetails) error {
+	var previousNames, previousPersonIDs []string
+	if previous != nil {
+		previousNames, previousPersonIDs = previous.indexValues()
+	}
+	names, personIDs := details.indexValues()
+	if err := updatePrivateIndex(ctx, landOwnerIndex, previousNames, names, details.ID); err != nil {
+		return err
+	}
+	return updatePrivateIndex(ctx, landPersonIndex, previousPersonIDs, personIDs, details.ID)
+}
+
+// indexValues returns the normalized owner names and linked PERSON_ IDs to index
+func (d *LandPrivateDetails) indexValues() ([]string, []string) {
+	owners := d.Owners
+	if len(owners) == 0 && d.Owner != "" {
+		owners = soleOwner(d.Owner, d.OwnerPersonID)
+	}
+
+	var names, personIDs []string
+	for _, owner := range owners {
+		names = append(names, normalizeLookup(owner.Name))
+		if owner.PersonID != "" {
+			personIDs = append(personIDs, owner.PersonID)
+		}
+	}
+	return names, personIDs
+}
+
+// updatePrivateIndex replaces a property's entries in a private index
+func updatePrivateIndex(ctx contractapi.TransactionContextInterface, index string, previous []string, current []string, propertyID string) error {
+	keep := map[string]bool{}
+	for _, value := range current {
+		keep[value] = true
+	}
+	for _, value := range previous {
+		if keep[value] {
+			continue
+		}
+		previousKey, err := ctx.GetStub().CreateCompositeKey(index, []string{value, propertyID})
+		if err != nil {
+			return fmt.Errorf("failed to create %s index key: %v", index, err)
+		}
+		if err := ctx.GetStub().DelPrivateData(landPrivateCollection, previousKey); err != nil {
+			return fmt.Errorf("failed to delete %s index entry: %v", index, err)
+		}
+	}
+
+	for _, value := range current {
+		key, err := ctx.GetStub().CreateCompositeKey(index, []string{value, propertyID})
+		if err != nil {
+			return fmt.Errorf("failed to create %s index key: %v", index, err)
+		}
+		if err := ctx.GetStub().PutPrivateData(landPrivateCollection, key, []byte{0x00}); err != nil {
+			return fmt.Errorf("failed to store %s index entry: %v", index, err)
+		}
+	}
+	return nil
+}
+
+// storePrivateParties keeps the names of a case's parties in the private collection
+// The public document lists the parties without names (see withoutNames).
+func storePrivateParties(ctx contractapi.TransactionContextInterface, docType string, id string, parties []CoOwner) error {
+	details := LandPrivateDetails{
+		DocType:    DocTypePrivateDetails,
+		RecordType: docType,
+		ID:         id,
+		Owner:      ownerSummary(parties),
+		Owners:     parties,
+		Salt:       derivedSalt(ctx, id),
+	}
+	key, err := stateKey(ctx, DocTypePrivateDetails, docType, id)
+	if err != nil {
+		return err
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to marshal private details: %v", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(landPrivateCollection, key, detailsJSON); err != nil {
+		return fmt.Errorf("failed to store private details: %v", err)
+	}
+	return nil
+}
+
+// loadPrivateParties returns a case's parties with their names, as storePrivateParties kept them
+func loadPrivateParties(ctx contractapi.TransactionContextInterface, docType string, id string) ([]CoOwner, error) {
+	details, err := getPrivateDetails(ctx, docType, id)
+	if err != nil {
+		return nil, err
+	}
+	if details == nil {
+		return nil, fmt.Errorf("private details of %s are not available on this peer", id)
+	}
+	return details.Owners, nil
+}
+
+func getPrivateDetails(ctx contractapi.TransactionContextInterface, docType string, id string) (*LandPrivateDetails, error) {
+	key, err := stateKey(ctx, DocTypePrivateDetails, docType, id)
+	if err != nil {
+		return nil, err
+	}
+	detailsJSON, err := ctx.GetStub().GetPrivateData(landPrivateCollection, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private details: %v", err)
+	}
+	if detailsJSON == nil {
+		return nil, nil
+	}
+
+	var details LandPrivateDetails
+	if err := json.Unmarshal(detailsJSON, &details); err != nil {
+		return nil, fmt.Errorf("failed to parse private details: %v", err)
+	}
+	return &details, nil
+}
+
+// transientPrivateDetails reads private inputs from the transient map (nil if absent)
+func transientPrivateDetails(ctx contractapi.TransactionContextInterface) (*privateDetailsInput, error) {
+	transient, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transient data: %v", err)
+	}
+	inputJSON, ok := transient[privateDetailsTransientKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var input privateDetailsInput
+	if err := json.Unmarshal(inputJSON, &input); err != nil {
+		return nil, fmt.Errorf("invalid %s transient data: %v", privateDetailsTransientKey, err)
+	}
+	if len(input.Salt) < minSaltLength {
+		return nil, fmt.Errorf("%s salt must be at least %d characters", privateDetailsTransientKey, minSaltLength)
+	}
+	return &input, nil
+}
+
+// hashes returns the salted hash of every private field that has a value
+func (d *LandPrivateDetails) hashes() map[string]string {
+	values := map[string]string{
+		PrivateFieldOwner:         normalizeLookup(d.Owner),
+		PrivateFieldOwnerIDNumber: normalizeLookup(d.OwnerIDNumber),
+		PrivateFieldMarketValue:   canonicalMoney(d.MarketValue),
+	}
+	hashes := map[string]string{}
+	for field, value := range values {
+		if value != "" {
+			hashes[field] = privateFieldHash(d.Salt, field, value)
+		}
+	}
+	return hashes
+}
+
+// privateFieldHash is the hex SHA-256 of salt, field name and canonical value
+func privateFieldHash(salt string, field string, value string) string {
+	sum := sha256.Sum256([]byte(salt + "\x00" + field + "\x00" + value))
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalPrivateValue normalizes a disclosed value the way it was hashed
+func canonicalPrivateValue(field string, value string) (string, error) {
+	switch field {
+	case PrivateFieldOwner, PrivateFieldOwnerIDNumber:
+		return normalizeLookup(value), nil
+	case PrivateFieldMarketValue:
+		return canonicalMoney(Money{Legacy: value}), nil
+	default:
+		return "", fmt.Errorf("unknown private field: %s", field)
+	}
+}
+
+// canonicalMoney renders an amount as paise, or as normalized text if it cannot be parsed
+func canonicalMoney(m Money) string {
+	if m.Legacy != "" {
+		parsed, err := parseRupees(m.Legacy)
+		if err != nil {
+			return normalizeLookup(m.Legacy)
+		}
+		m = parsed
+	}
+	if m.Paise == 0 {
+		return ""
+	}
+	return strconv.FormatInt(m.Paise, 10)
+}
+
+// derivedSalt is the salt for private details that arrived without one
+func derivedSalt(ctx contractapi.TransactionContextInterface, id string) string {
+	sum := sha256.Sum256([]byte(ctx.GetStub().GetTxID() + "\x00" + id))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestPrivateDetailsStayOffPublicState(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peer := newEndorser("peer0")
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	txTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+	salt := "3f1c9a7e5b2d8046"
+
+	private, _ := json.Marshal(privateDetailsInput{
+		Owner:         "Ravi Kumar",
+		OwnerIDNumber: "1234 5678 9012",
+		MarketValue:   "45 L",
+		Salt:          salt,
+	})
+	requestID := peer.endorse(t, proposal{
+		txID:      "tx-request",
+		creator:   registrar,
+		time:      txTime,
+		transient: map[string][]byte{privateDetailsTransientKey: private},
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.RequestPropertyID(ctx, "TS", "", "101/A", "Rangareddy",
+				"Shamshabad", "Kothur", "2 acres", "agricultural", "", "")
+		},
+	}).result.(string)
+	created := peer.endorse(t, proposal{
+		txID:    "tx-bind",
+		creator: registrar,
+		time:    txTime.Add(time.Hour),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CreateStateRecord(ctx, "CCLB-2025-TS-000001", requestID, "")
+		},
+	})
+
+	for key, value := range created.state {
+		if strings.Contains(string(value), "Ravi Kumar") || strings.Contains(string(value), "1234 5678 9012") {
+			t.Fatalf("public state %q holds private details: %s", key, value)
+		}
+	}
+
+	details, err := contract.ReadLandPrivateDetails(peer.ctx, "CCLB-2025-TS-000001")
+	if err != nil {
+		t.Fatalf("failed to read private details: %v", err)
+	}
+	if details.Owner != "Ravi Kumar" || details.MarketValue.Paise != 4500000*100 || details.Salt != salt {
+		t.Fatalf("private details = %+v", details)
+	}
+
+	verify := func(field string, value string, salt string) bool {
+		t.Helper()
+		ok, err := contract.VerifyPrivateDetail(peer.ctx, "CCLB-2025-TS-000001", field, value, salt)
+		if err != nil {
+			t.Fatalf("failed to verify %s: %v", field, err)
+		}
+		return ok
+	}
+	if !verify(PrivateFieldOwner, " ravi kumar ", salt) || !verify(PrivateFieldMarketValue, "45 lakh", salt) {
+		t.Fatal("disclosed owner and market value did not verify")
+	}
+	if verify(PrivateFieldOwner, "Sita Devi", salt) || verify(PrivateFieldOwner, "Ravi Kumar", "wrong-salt-000000") {
+		t.Fatal("a wrong owner or salt verified")
+	}
+
+	owned, err := contract.QueryLandByOwner(peer.ctx, "Ravi Kumar")
+	if err != nil || len(owned) != 1 || owned[0].PropertyID != "CCLB-2025-TS-000001" {
+		t.Fatalf("owner query = %+v (%v), want the new record", owned, err)
+	}
+}
+
+func TestOwnerNamesStayOffPublicDocumentsAndEvents(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, sitaID, arjunID := s.personIDs["ravi"], s.personIDs["sita"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+	s.record("CCLB-2025-TS-000002", "102", CoOwner{Name: "Sita Devi", PersonID: sitaID, Share: "1"})
+	tahsildar := testCreator(t, "StateOrgTSMSP", "tahsildar")
+
+	// A lease, a sale through its mutation, and a succession to a registered and an unregistered heir
+	s.must("tx-lease", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RegisterLease(ctx, "CCLB-2025-TS-000002", arjunID, "", "", "2025-04-01", "2026-03-31",
+			`[{"from":"2025-04-01","monthlyRent":"25000"}]`, "1.5 L", testDeedHash)
+	})
+	sale := s.complete(s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+	}).(*TransferRequest), "arjun")
+	s.txTime = s.txTime.Add(mutationNoticePeriod + time.Hour)
+	s.must("tx-mutate", tahsildar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.DecideMutation(ctx, sale.MutationCaseID, "APPROVE", "KH-1042", "")
+	})
+	caseID := s.must("tx-death", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RegisterDeath(ctx, sitaID, "2025-03-10", testDeedHash)
+	}).(*SuccessionCase).CaseID
+	heirs, _ := json.Marshal([]CoOwner{
+		{Name: "Arjun Rao", PersonID: arjunID, Share: "1/2"},
+		{Name: "Meena Kumari", Share: "1/2"},
+	})
+	s.must("tx-heirs", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.DeclareHeirs(ctx, caseID, SuccessionIntestate, "", string(heirs))
+	})
+	s.txTime = s.txTime.Add(successionNoticePeriod + time.Hour)
+	s.must("tx-devolve", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.DecideSuccession(ctx, caseID, "APPROVE", "")
+	})
+
+	names := []string{"Ravi Kumar", "Sita Devi", "Arjun Rao", "Meena Kumari"}
+	leaks := func(where string, data string) {
+		for _, name := range names {
+			if strings.Contains(strings.ToLower(data), strings.ToLower(name)) {
+				t.Errorf("%s names %s: %s", where, name, data)
+			}
+		}
+	}
+	for key, value := range s.peer.stub.State {
+		leaks("public key "+key, key+" "+string(value))
+	}
+	if len(s.peer.published) == 0 {
+		t.Fatal("no events were published")
+	}
+	for _, payload := range s.peer.published {
+		leaks("event", string(payload))
+	}
+
+	// The names are still there for those who may read the private collection
+	if person, err := getPerson(s.peer.ctx, arjunID); err != nil || person.Name != "Arjun Rao" {
+		t.Fatalf("person %s = %+v (%v), want Arjun's name from private details", arjunID, person, err)
+	}
+	devolved := s.owners("CCLB-2025-TS-000002")
+	if len(devolved.Owners) != 2 || devolved.Owners[0].Name != "Arjun Rao" || devolved.Owners[1].Name != "Meena Kumari" {
+		t.Fatalf("owners after succession %+v, want both heirs by name", devolved.Owners)
+	}
+	if revenue := s.owners("CCLB-2025-TS-000001"); revenue.RevenueOwnerID != arjunID {
+		t.Fatalf("revenue owner %q, want Arjun's PERSON_ ID", revenue.RevenueOwnerID)
+	}
+}
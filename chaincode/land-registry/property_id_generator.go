@@ -3,8 +3,8 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
@@ -15,13 +15,15 @@ import (
 
 // PropertyIDCounter tracks sequence numbers per state per year (for atomicity)
 type PropertyIDCounter struct {
-	State    string `json:"state"`
-	Year     int    `json:"year"`
-	Sequence int    `json:"sequence"`
+	DocType       string `json:"docType"` // COUNTER
+	SchemaVersion int    `json:"schemaVersion"`
+	State         string `json:"state"`
+	Year          int    `json:"year"`
+	Sequence      int    `json:"sequence"`
 }
 
 const (
-	counterKeyPrefix = "COUNTER_"
+	counterKeyPrefix = "COUNTER_" // Pre-migration flat key prefix
 )
 
 // GeneratePropertyID creates an atomic, globally unique Property ID
@@ -36,11 +38,20 @@ func (c *LandRegistryContract) GeneratePropertyID(
 		return "", fmt.Errorf("invalid or unsupported state: %s", state)
 	}
 
-	currentYear := time.Now().Year()
-	counterKey := fmt.Sprintf("%s%s_%d", counterKeyPrefix, stateCode, currentYear)
+	// Year comes from the transaction header so every endorser picks the same counter
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return "", err
+	}
+	currentYear := txTime.Year()
+	counterKey, err := stateKey(ctx, DocTypeCounter, stateCode, strconv.Itoa(currentYear))
+	if err != nil {
+		return "", err
+	}
+	legacyKey := fmt.Sprintf("%s%s_%d", counterKeyPrefix, stateCode, currentYear)
 
 	// Read current counter (atomically within transaction)
-	counterJSON, err := ctx.GetStub().GetState(counterKey)
+	counterJSON, err := getDocument(ctx, counterKey, legacyKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to read counter: %v", err)
 	}
@@ -54,13 +65,15 @@ func (c *LandRegistryContract) GeneratePropertyID(
 			Sequence: 0,
 		}
 	} else {
-		err = json.Unmarshal(counterJSON, &counter)
+		err = decodeDocument(DocTypeCounter, counterJSON, &counter)
 		if err != nil {
 			return "", fmt.Errorf("failed to parse counter: %v", err)
 		}
 	}
 
 	// Increment sequence atomically
+	counter.DocType = DocTypeCounter
+	counter.SchemaVersion = currentSchemaVersion(DocTypeCounter)
 	counter.Sequence++
 
 	// Persist updated counter
@@ -68,7 +81,7 @@ func (c *LandRegistryContract) GeneratePropertyID(
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal counter: %v", err)
 	}
-	err = ctx.GetStub().PutState(counterKey, updatedCounterJSON)
+	err = putRawDocument(ctx, counterKey, legacyKey, updatedCounterJSON)
 	if err != nil {
 		return "", fmt.Errorf("failed to update counter: %v", err)
 	}
@@ -89,24 +102,33 @@ func (c *LandRegistryContract) GetPropertyIDCounter(
 		return nil, fmt.Errorf("invalid state: %s", state)
 	}
 
-	currentYear := time.Now().Year()
-	counterKey := fmt.Sprintf("%s%s_%d", counterKeyPrefix, stateCode, currentYear)
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	currentYear := txTime.Year()
+	counterKey, err := stateKey(ctx, DocTypeCounter, stateCode, strconv.Itoa(currentYear))
+	if err != nil {
+		return nil, err
+	}
 
-	counterJSON, err := ctx.GetStub().GetState(counterKey)
+	counterJSON, err := getDocument(ctx, counterKey, fmt.Sprintf("%s%s_%d", counterKeyPrefix, stateCode, currentYear))
 	if err != nil {
 		return nil, err
 	}
 
 	if counterJSON == nil {
 		return &PropertyIDCounter{
-			State:    stateCode,
-			Year:     currentYear,
-			Sequence: 0,
+			DocType:       DocTypeCounter,
+			SchemaVersion: currentSchemaVersion(DocTypeCounter),
+			State:         stateCode,
+			Year:          currentYear,
+			Sequence:      0,
 		}, nil
 	}
 
 	var counter PropertyIDCounter
-	err = json.Unmarshal(counterJSON, &counter)
+	err = decodeDocument(DocTypeCounter, counterJSON, &counter)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,471 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TaxRateTable holds the property tax rates for one fiscal year
+// Rates are in basis points of market value (50 = 0.50%), keyed by LandType.
+// Stored under composite key TAX_RATE~<fiscalYear>; only tax officers may change it.
+type TaxRateTable struct {
+	DocType        string    `json:"docType"`    // TAX_RATE
+	FiscalYear     string    `json:"fiscalYear"` // e.g. 2026-27
+	Rates          []TaxRate `json:"rates"`
+	DefaultRateBps int64     `json:"defaultRateBps"` // Used when LandType has no entry
+	UpdatedBy      string    `json:"updatedBy"`
+	UpdatedAt      string    `json:"updatedAt"`
+}
+
+// TaxRate is the rate applied to one land type
+type TaxRate struct {
+	LandType string `json:"landType"`
+	RateBps  int64  `json:"rateBps"`
+}
+
+// TaxAssessment is the tax levied on a property for a fiscal year
+// Stored under composite key TAX_ASSESSMENT~<propertyID>~<fiscalYear>
+type TaxAssessment struct {
+	DocType            string       `json:"docType"` // TAX_ASSESSMENT
+	PropertyID         string       `json:"propertyId"`
+	FiscalYear         string       `json:"fiscalYear"`
+	LandType           string       `json:"landType"`
+	AssessedValuePaise int64        `json:"assessedValuePaise"`
+	RateBps            int64        `json:"rateBps"`
+	TaxDuePaise        int64        `json:"taxDuePaise"`
+	PaidPaise          int64        `json:"paidPaise"`
+	Status             string       `json:"status"` // DUE, PARTIAL, PAID
+	AssessedBy         string       `json:"assessedBy"`
+	AssessedAt         string       `json:"assessedAt"`
+	Payments           []TaxPayment `json:"payments"`
+}
+
+// TaxPayment is one receipt against a TaxAssessment
+type TaxPayment struct {
+	AmountPaise int64  `json:"amountPaise"`
+	ReceiptRef  string `json:"receiptRef"`
+	RecordedBy  string `json:"recordedBy"`
+	RecordedAt  string `json:"recordedAt"`
+	TxID        string `json:"txId"`
+}
+
+// TaxArrears summarises unpaid tax across fiscal years
+type TaxArrears struct {
+	PropertyID       string       `json:"propertyId"`
+	OutstandingPaise int64        `json:"outstandingPaise"`
+	Years            []YearArrear `json:"years"`
+	NoDues           bool         `json:"noDues"`
+}
+
+// YearArrear is the unpaid balance for one fiscal year
+type YearArrear struct {
+	FiscalYear       string `json:"fiscalYear"`
+	OutstandingPaise int64  `json:"outstandingPaise"`
+}
+
+// Tax assessment statuses
+const (
+	TaxDue     = "DUE"
+	TaxPartial = "PARTIAL"
+	TaxPaid    = "PAID"
+)
+
+const taxRateKeyPrefix = "TAXRATE_" // Pre-migration flat key prefix
+
+var fiscalYearPattern = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+
+// SetTaxRateTable replaces the rate table for a fiscal year
+// ratesJSON maps land type to basis points, e.g. {"agricultural": 10, "residential": 50}
+// Requires 'tax_officer' role
+func (c *LandRegistryContract) SetTaxRateTable(
+	ctx contractapi.TransactionContextInterface,
+	fiscalYear string,
+	ratesJSON string,
+	defaultRateBps int64,
+) (*TaxRateTable, error) {
+	if err := requireRole(ctx, "tax_officer"); err != nil {
+		return nil, fmt.Errorf("only tax officers can change tax rates: %v", err)
+	}
+	if err := validateFiscalYear(fiscalYear); err != nil {
+		return nil, err
+	}
+	if defaultRateBps < 0 || defaultRateBps > 10000 {
+		return nil, fmt.Errorf("default rate must be between 0 and 10000 bps")
+	}
+
+	var rateMap map[string]int64
+	if err := json.Unmarshal([]byte(ratesJSON), &rateMap); err != nil {
+		return nil, fmt.Errorf("invalid rates JSON: %v", err)
+	}
+
+	table := &TaxRateTable{
+		FiscalYear:     fiscalYear,
+		Rates:          []TaxRate{},
+		DefaultRateBps: defaultRateBps,
+	}
+	for landType, bps := range rateMap {
+		if bps < 0 || bps > 10000 {
+			return nil, fmt.Errorf("rate for %s must be between 0 and 10000 bps", landType)
+		}
+		table.Rates = append(table.Rates, TaxRate{
+			LandType: strings.ToLower(strings.TrimSpace(landType)),
+			RateBps:  bps,
+		})
+	}
+	// Map iteration order is random; sort so every endorser writes identical bytes
+	sort.Slice(table.Rates, func(i, j int) bool {
+		return table.Rates[i].LandType < table.Rates[j].LandType
+	})
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	table.DocType = DocTypeTaxRateTable
+	table.UpdatedBy = clientID
+	table.UpdatedAt = txTime.Format(time.RFC3339)
+
+	key, err := stateKey(ctx, DocTypeTaxRateTable, fiscalYear)
+	if err != nil {
+		return nil, err
+	}
+	if err := putDocument(ctx, key, taxRateKeyPrefix+fiscalYear, table); err != nil {
+		return nil, fmt.Errorf("failed to store tax rate table: %v", err)
+	}
+
+	return table, nil
+}
+
+// GetTaxRateTable returns the rate table for a fiscal year
+func (c *LandRegistryContract) GetTaxRateTable(
+	ctx contractapi.TransactionContextInterface,
+	fiscalYear string,
+) (*TaxRateTable, error) {
+	return getTaxRateTable(ctx, fiscalYear)
+}
+
+// AssessPropertyTax levies tax for a fiscal year from MarketValue and LandType
+// Requires 'tax_officer' role
+func (c *LandRegistryContract) AssessPropertyTax(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	fiscalYear string,
+) (*TaxAssessment, error) {
+	if err := requireRole(ctx, "tax_officer"); err != nil {
+		return nil, fmt.Errorf("only tax officers can assess tax: %v", err)
+	}
+	if err := validateFiscalYear(fiscalYear); err != nil {
+		return nil, err
+	}
+
+	landRecord, err := c.readLandRecordWithDetails(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if landRecord.Status == RecordRetired {
+		return nil, fmt.Errorf("land record %s is retired; assess its successor parcels instead", propertyID)
+	}
+
+	existing, err := getTaxAssessment(ctx, propertyID, fiscalYear)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("property %s is already assessed for %s", propertyID, fiscalYear)
+	}
+
+	table, err := getTaxRateTable(ctx, fiscalYear)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := landRecord.MarketValue.requireTyped("market value"); err != nil {
+		return nil, fmt.Errorf("cannot assess %s: %v", propertyID, err)
+	}
+	valuePaise := landRecord.MarketValue.Paise
+	rateBps := table.rateFor(landRecord.LandType)
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assessment := &TaxAssessment{
+		PropertyID:         propertyID,
+		FiscalYear:         fiscalYear,
+		LandType:           landRecord.LandType,
+		AssessedValuePaise: valuePaise,
+		RateBps:            rateBps,
+		TaxDuePaise:        valuePaise * rateBps / 10000,
+		Status:             TaxDue,
+		AssessedBy:         clientID,
+		AssessedAt:         txTime.Format(time.RFC3339),
+		Payments:           []TaxPayment{},
+	}
+	if assessment.TaxDuePaise == 0 {
+		assessment.Status = TaxPaid
+	}
+
+	if err := putTaxAssessment(ctx, assessment); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitTaxEvent(ctx, EventTaxAssessed, assessment, 0, ""); err != nil {
+		fmt.Printf("warning: failed to emit TaxEvent: %v\n", err)
+	}
+
+	return assessment, nil
+}
+
+// RecordTaxPayment applies a payment receipt to an assessment
+// Requires 'tax_officer' role
+func (c *LandRegistryContract) RecordTaxPayment(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	fiscalYear string,
+	amountPaise int64,
+	receiptRef string,
+) (*TaxAssessment, error) {
+	if err := requireRole(ctx, "tax_officer"); err != nil {
+		return nil, fmt.Errorf("only tax officers can record tax payments: %v", err)
+	}
+	if amountPaise <= 0 {
+		return nil, fmt.Errorf("payment amount must be positive")
+	}
+	if strings.TrimSpace(receiptRef) == "" {
+		return nil, fmt.Errorf("a receipt reference is required")
+	}
+
+	assessment, err := getTaxAssessment(ctx, propertyID, fiscalYear)
+	if err != nil {
+		return nil, err
+	}
+	if assessment == nil {
+		return nil, fmt.Errorf("property %s has no assessment for %s", propertyID, fiscalYear)
+	}
+	for _, payment := range assessment.Payments {
+		if payment.ReceiptRef == receiptRef {
+			return nil, fmt.Errorf("receipt %s already recorded", receiptRef)
+		}
+	}
+	outstanding := assessment.TaxDuePaise - assessment.PaidPaise
+	if amountPaise > outstanding {
+		return nil, fmt.Errorf("payment %d exceeds outstanding %d paise", amountPaise, outstanding)
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	assessment.Payments = append(assessment.Payments, TaxPayment{
+		AmountPaise: amountPaise,
+		ReceiptRef:  receiptRef,
+		RecordedBy:  clientID,
+		RecordedAt:  txTime.Format(time.RFC3339),
+		TxID:        ctx.GetStub().GetTxID(),
+	})
+	assessment.PaidPaise += amountPaise
+	assessment.Status = TaxPartial
+	if assessment.PaidPaise == assessment.TaxDuePaise {
+		assessment.Status = TaxPaid
+	}
+
+	if err := putTaxAssessment(ctx, assessment); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitTaxEvent(ctx, EventTaxPaid, assessment, amountPaise, receiptRef); err != nil {
+		fmt.Printf("warning: failed to emit TaxEvent: %v\n", err)
+	}
+
+	return assessment, nil
+}
+
+// GetTaxAssessments returns all assessments for a property
+func (c *LandRegistryContract) GetTaxAssessments(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) ([]*TaxAssessment, error) {
+	return listTaxAssessments(ctx, propertyID)
+}
+
+// GetTaxArrears computes outstanding tax per fiscal year for a property
+func (c *LandRegistryContract) GetTaxArrears(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) (*TaxArrears, error) {
+	return computeTaxArrears(ctx, propertyID)
+}
+
+// checkNoTaxDues fails when a property has unpaid tax (consulted by ApproveTransfer)
+func checkNoTaxDues(ctx contractapi.TransactionContextInterface, propertyID string) error {
+	arrears, err := computeTaxArrears(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if !arrears.NoDues {
+		return fmt.Errorf("property %s has %d paise of unpaid property tax", propertyID, arrears.OutstandingPaise)
+	}
+	return nil
+}
+
+func computeTaxArrears(ctx contractapi.TransactionContextInterface, propertyID string) (*TaxArrears, error) {
+	assessments, err := listTaxAssessments(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+
+	arrears := &TaxArrears{PropertyID: propertyID, Years: []YearArrear{}}
+	for _, assessment := range assessments {
+		outstanding := assessment.TaxDuePaise - assessment.PaidPaise
+		if outstanding <= 0 {
+			continue
+		}
+		arrears.OutstandingPaise += outstanding
+		arrears.Years = append(arrears.Years, YearArrear{
+			FiscalYear:       assessment.FiscalYear,
+			OutstandingPaise: outstanding,
+		})
+	}
+	arrears.NoDues = arrears.OutstandingPaise == 0
+
+	return arrears, nil
+}
+
+// rateFor returns the basis-point rate for a land type, falling back to the default
+func (t *TaxRateTable) rateFor(landType string) int64 {
+	key := strings.ToLower(strings.TrimSpace(landType))
+	for _, rate := range t.Rates {
+		if rate.LandType == key {
+			return rate.RateBps
+		}
+	}
+	return t.DefaultRateBps
+}
+
+// validateFiscalYear accepts Indian fiscal years written as YYYY-YY (e.g. 2026-27)
+func validateFiscalYear(fiscalYear string) error {
+	m := fiscalYearPattern.FindStringSubmatch(fiscalYear)
+	if m == nil {
+		return fmt.Errorf("invalid fiscal year %q (expected YYYY-YY)", fiscalYear)
+	}
+	start, _ := strconv.Atoi(m[1])
+	end, _ := strconv.Atoi(m[2])
+	if (start+1)%100 != end {
+		return fmt.Errorf("invalid fiscal year %q (years must be consecutive)", fiscalYear)
+	}
+	return nil
+}
+
+func getTaxRateTable(ctx contractapi.TransactionContextInterface, fiscalYear string) (*TaxRateTable, error) {
+	key, err := stateKey(ctx, DocTypeTaxRateTable, fiscalYear)
+	if err != nil {
+		return nil, err
+	}
+	tableJSON, err := getDocument(ctx, key, taxRateKeyPrefix+fiscalYear)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tax rate table: %v", err)
+	}
+	if tableJSON == nil {
+		return nil, fmt.Errorf("no tax rate table for %s", fiscalYear)
+	}
+
+	var table TaxRateTable
+	if err := json.Unmarshal(tableJSON, &table); err != nil {
+		return nil, fmt.Errorf("failed to parse tax rate table: %v", err)
+	}
+	return &table, nil
+}
+
+// getTaxAssessment returns nil (no error) when the property has not been assessed for the year
+func getTaxAssessment(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	fiscalYear string,
+) (*TaxAssessment, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(DocTypeTaxAssessment, []string{propertyID, fiscalYear})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tax assessment key: %v", err)
+	}
+
+	assessmentJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tax assessment: %v", err)
+	}
+	if assessmentJSON == nil {
+		return nil, nil
+	}
+
+	var assessment TaxAssessment
+	if err := json.Unmarshal(assessmentJSON, &assessment); err != nil {
+		return nil, fmt.Errorf("failed to parse tax assessment: %v", err)
+	}
+	return &assessment, nil
+}
+
+func putTaxAssessment(ctx contractapi.TransactionContextInterface, assessment *TaxAssessment) error {
+	assessment.DocType = DocTypeTaxAssessment
+	key, err := ctx.GetStub().CreateCompositeKey(
+		DocTypeTaxAssessment,
+		[]string{assessment.PropertyID, assessment.FiscalYear},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create tax assessment key: %v", err)
+	}
+
+	assessmentJSON, err := json.Marshal(assessment)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tax assessment: %v", err)
+	}
+	if err := ctx.GetStub().PutState(key, assessmentJSON); err != nil {
+		return fmt.Errorf("failed to store tax assessment: %v", err)
+	}
+	return nil
+}
+
+func listTaxAssessments(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) ([]*TaxAssessment, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DocTypeTaxAssessment, []string{propertyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tax assessments: %v", err)
+	}
+	defer resultsIterator.Close()
+
+	assessments := []*TaxAssessment{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		var assessment TaxAssessment
+		if err := json.Unmarshal(queryResponse.Value, &assessment); err != nil {
+			return nil, fmt.Errorf("failed to parse tax assessment: %v", err)
+		}
+		assessments = append(assessments, &assessment)
+	}
+
+	return assessments, nil
+}
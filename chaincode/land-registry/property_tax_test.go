@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestPropertyTaxArrearsBlockTransfer(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, arjunID := s.personIDs["ravi"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"}) // Agricultural, worth 45 L
+	taxOfficer := testCreator(t, "StateOrgTSMSP", "tax_officer")
+
+	setRates := func(txID string, creator []byte, fiscalYear string, rates string, defaultBps int64) (endorsement, error) {
+		return s.step(txID, creator, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.SetTaxRateTable(ctx, fiscalYear, rates, defaultBps)
+		})
+	}
+	refusals := []struct {
+		name    string
+		creator []byte
+		year    string
+		rates   string
+		err     string
+	}{
+		{"registrar", s.registrar, "2025-26", `{"agricultural": 10}`, "only tax officers"},
+		{"citizen", s.citizens["ravi"], "2025-26", `{"agricultural": 10}`, "only tax officers"},
+		{"rate out of range", taxOfficer, "2025-26", `{"agricultural": 10001}`, "between 0 and 10000"},
+		{"years not consecutive", taxOfficer, "2025-27", `{"agricultural": 10}`, "consecutive"},
+	}
+	for _, tc := range refusals {
+		if _, err := setRates("tx-rates-"+tc.name, tc.creator, tc.year, tc.rates, 50); err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Fatalf("%s setting rates: err = %v, want %q", tc.name, err, tc.err)
+		}
+	}
+	for _, table := range []struct{ year, rates string }{
+		{"2024-25", `{"Agricultural ": 20, "commercial": 100}`},
+		{"2025-26", `{"agricultural": 10}`},
+	} {
+		if _, err := setRates("tx-rates-"+table.year, taxOfficer, table.year, table.rates, 50); err != nil {
+			t.Fatalf("setting %s rates failed: %v", table.year, err)
+		}
+	}
+	table, err := s.contract.GetTaxRateTable(s.peer.ctx, "2024-25")
+	if err != nil || !reflect.DeepEqual(table.Rates, []TaxRate{{"agricultural", 20}, {"commercial", 100}}) {
+		t.Fatalf("2024-25 rate table %+v (%v), want normalized land types in order", table, err)
+	}
+
+	// 45 L at 0.20% and then 0.10%
+	for fiscalYear, wantDue := range map[string]int64{"2024-25": 900000, "2025-26": 450000} {
+		assessment := s.must("tx-assess-"+fiscalYear, taxOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.AssessPropertyTax(ctx, "CCLB-2025-TS-000001", fiscalYear)
+		}).(*TaxAssessment)
+		if assessment.TaxDuePaise != wantDue || assessment.Status != TaxDue {
+			t.Fatalf("%s assessment %+v, want %d paise due", fiscalYear, assessment, wantDue)
+		}
+	}
+	if _, err := s.step("tx-assess-again", taxOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.AssessPropertyTax(ctx, "CCLB-2025-TS-000001", "2025-26")
+	}); err == nil || !strings.Contains(err.Error(), "already assessed") {
+		t.Fatalf("assessing a year twice: err = %v, want it refused", err)
+	}
+	if _, err := s.step("tx-assess-registrar", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.AssessPropertyTax(ctx, "CCLB-2025-TS-000001", "2026-27")
+	}); err == nil || !strings.Contains(err.Error(), "only tax officers") {
+		t.Fatalf("registrar assessing tax: err = %v, want it refused", err)
+	}
+
+	pay := func(txID string, fiscalYear string, paise int64, receipt string) (*TaxAssessment, error) {
+		paid, err := s.step(txID, taxOfficer, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.RecordTaxPayment(ctx, "CCLB-2025-TS-000001", fiscalYear, paise, receipt)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return paid.result.(*TaxAssessment), nil
+	}
+	if partial, err := pay("tx-pay-1", "2024-25", 400000, "RCPT-2024-0001"); err != nil || partial.Status != TaxPartial || partial.PaidPaise != 400000 {
+		t.Fatalf("first instalment = %+v (%v), want PARTIAL with 400000 paid", partial, err)
+	}
+	if _, err := pay("tx-pay-duplicate", "2024-25", 100000, "RCPT-2024-0001"); err == nil || !strings.Contains(err.Error(), "already recorded") {
+		t.Fatalf("reusing a receipt: err = %v, want it refused", err)
+	}
+	if _, err := pay("tx-pay-over", "2024-25", 600000, "RCPT-2024-0002"); err == nil || !strings.Contains(err.Error(), "exceeds outstanding") {
+		t.Fatalf("overpaying: err = %v, want it refused", err)
+	}
+
+	arrears, err := s.contract.GetTaxArrears(s.peer.ctx, "CCLB-2025-TS-000001")
+	wantYears := []YearArrear{{"2024-25", 500000}, {"2025-26", 450000}}
+	if err != nil || arrears.NoDues || arrears.OutstandingPaise != 950000 || !reflect.DeepEqual(arrears.Years, wantYears) {
+		t.Fatalf("arrears %+v (%v), want %v outstanding", arrears, err, wantYears)
+	}
+	if paid, err := pay("tx-pay-2", "2024-25", 500000, "RCPT-2024-0002"); err != nil || paid.Status != TaxPaid || len(paid.Payments) != 2 {
+		t.Fatalf("second instalment = %+v (%v), want PAID after two receipts", paid, err)
+	}
+
+	// The current year is still unpaid, so the registrar cannot approve a sale
+	transfer := s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+	}).(*TransferRequest)
+	s.must("tx-accept", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.AcceptTransfer(ctx, transfer.TransferID)
+	})
+	s.must("tx-verify", s.subRegistrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.VerifyTransferDocuments(ctx, transfer.TransferID, "1.5 L", "SD-2025-0001")
+	})
+	approve := func(txID string) (endorsement, error) {
+		return s.step(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.ApproveTransfer(ctx, transfer.TransferID)
+		})
+	}
+	if _, err := approve("tx-approve-arrears"); err == nil || !strings.Contains(err.Error(), "450000 paise of unpaid property tax") {
+		t.Fatalf("approving with arrears: err = %v, want the dues reported", err)
+	}
+	if landRecord := s.owners("CCLB-2025-TS-000001"); landRecord.Owners[0].PersonID != raviID {
+		t.Fatalf("owners after a blocked approval %+v, want Ravi", landRecord.Owners)
+	}
+
+	if _, err := pay("tx-pay-3", "2025-26", 450000, "RCPT-2025-0001"); err != nil {
+		t.Fatalf("paying the current year failed: %v", err)
+	}
+	if _, err := approve("tx-approve"); err != nil {
+		t.Fatalf("approving with no dues failed: %v", err)
+	}
+	if landRecord := s.owners("CCLB-2025-TS-000001"); landRecord.Owners[0].PersonID != arjunID {
+		t.Fatalf("owners after approval %+v, want Arjun", landRecord.Owners)
+	}
+}
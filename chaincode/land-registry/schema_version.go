@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Schema versions
+// Stored entities carry a schemaVersion field. Documents written before it
+// existed read as version 0. On read, upcasters lift old JSON one version at a
+// time to the current struct shape; MigrateRecords rewrites them in place.
+//
+// Version history:
+//   - 1: docType on every document (composite key layout) and an explicit
+//     ACTIVE status on land records. Legacy area/marketValue text is still
+//     accepted by Area/Money and normalized by MigrateLandQuantities.
+//   - 2 (drafts): lifecycle status and a deadline to bind a Property ID.
+//   - 2 (land records), 3 (drafts): owner, owner ID number and market value
+//     in landPrivateCollection, with salted hashes on the public document.
+//   - 3 (land records), 4 (drafts): co-owners with shares (private) and a
+//     tenancy; earlier documents were solely owned.
+//   - 2 (persons): name in landPrivateCollection.
+
+// upcaster converts a decoded document from version N to N+1 in place
+type upcaster func(doc map[string]interface{}) error
+
+// documentSchema describes how one document type evolves
+type documentSchema struct {
+	upcasters []upcaster         // upcasters[N] lifts version N to N+1
+	document  func() interface{} // Empty value of the current struct
+}
+
+// documentSchemas lists every versioned document type
+// The current version of a type is the number of its upcasters.
+var documentSchemas = map[string]documentSchema{
+	DocTypeLandRecord: {
+		upcasters: []upcaster{upcastLandRecordV0, upcastInlinePrivateDetails, upcastSoleTenancy},
+		document:  func() interface{} { return &LandRecord{} },
+	},
+	DocTypeDraft: {
+		upcasters: []upcaster{withDocType(DocTypeDraft), upcastDraftV1, upcastInlinePrivateDetails, upcastSoleTenancy},
+		document:  func() interface{} { return &LandRecord{} },
+	},
+	DocTypeApplication: {
+		upcasters: []upcaster{withDocType(DocTypeApplication)},
+		document:  func() interface{} { return &LandApplication{} },
+	},
+	DocTypeLandToken: {
+		upcasters: []upcaster{withDocType(DocTypeLandToken)},
+		document:  func() interface{} { return &LandToken{} },
+	},
+	DocTypePerson: {
+		upcasters: []upcaster{withDocType(DocTypePerson), upcastPrivateName},
+		document:  func() interface{} { return &Person{} },
+	},
+	DocTypeCounter: {
+		upcasters: []upcaster{withDocType(DocTypeCounter)},
+		document:  func() interface{} { return &PropertyIDCounter{} },
+	},
+}
+
+// schemaMigrationOrder is the order in which MigrateRecords visits document types
+var schemaMigrationOrder = []string{
+	DocTypeLandRecord,
+	DocTypeDraft,
+	DocTypeApplication,
+	DocTypeLandToken,
+	DocTypePerson,
+	DocTypeCounter,
+}
+
+// RecordMigrationReport summarizes one MigrateRecords batch
+type RecordMigrationReport struct {
+	Examined     int    `json:"examined"`
+	Migrated     int    `json:"migrated"`     // Documents rewritten at the current version
+	DocType      string `json:"docType"`      // Document type the next batch resumes in
+	NextStartKey string `json:"nextStartKey"` // Last key examined within DocType
+	Done         bool   `json:"done"`
+}
+
+// recordMigrationProgress is the resumable cursor of MigrateRecords
+type recordMigrationProgress struct {
+	DocType string `json:"docType"`
+	Entity  string `json:"entity"` // Document type being migrated
+	LastKey string `json:"lastKey"`
+	Done    bool   `json:"done"`
+}
+
+// MigrateRecords rewrites stored entities at their current schema version
+// Examines up to batchSize documents per call and records its position, so it
+// can be invoked repeatedly until Done. A finished run starts over on the next
+// call, which picks up documents left behind by a later schema change.
+// Requires 'registrar' role
+func (c *LandRegistryContract) MigrateRecords(
+	ctx contractapi.TransactionContextInterface,
+	batchSize int,
+) (*RecordMigrationReport, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can migrate records: %v", err)
+	}
+	if batchSize < 1 || batchSize > maxMigrationBatch {
+		return nil, fmt.Errorf("batch size must be between 1 and %d", maxMigrationBatch)
+	}
+
+	progressKey, err := stateKey(ctx, DocTypeMigration, "schemaVersion")
+	if err != nil {
+		return nil, err
+	}
+	progress := recordMigrationProgress{DocType: DocTypeMigration}
+	progressJSON, err := ctx.GetStub().GetState(progressKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration progress: %v", err)
+	}
+	if progressJSON != nil {
+		if err := json.Unmarshal(progressJSON, &progress); err != nil {
+			return nil, fmt.Errorf("failed to parse migration progress: %v", err)
+		}
+	}
+	if progress.Done || progress.Entity == "" {
+		progress = recordMigrationProgress{DocType: DocTypeMigration, Entity: schemaMigrationOrder[0]}
+	}
+
+	report := &RecordMigrationReport{}
+	for i, docType := range schemaMigrationOrder {
+		if docType != progress.Entity {
+			continue
+		}
+
+		exhausted, err := migrateRecordBatch(ctx, docType, batchSize-report.Examined, &progress, report)
+		if err != nil {
+			return nil, err
+		}
+		if !exhausted {
+			break
+		}
+		if i == len(schemaMigrationOrder)-1 {
+			progress.Done = true
+			break
+		}
+		progress.Entity = schemaMigrationOrder[i+1]
+		progress.LastKey = ""
+	}
+
+	progressJSON, err = json.Marshal(progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal migration progress: %v", err)
+	}
+	if err := ctx.GetStub().PutState(progressKey, progressJSON); err != nil {
+		return nil, fmt.Errorf("failed to store migration progress: %v", err)
+	}
+
+	report.DocType = progress.Entity
+	report.NextStartKey = progress.LastKey
+	report.Done = progress.Done
+	return report, nil
+}
+
+// migrateRecordBatch upgrades documents of one type after the progress cursor
+// Returns true once every document of the type has been examined.
+func migrateRecordBatch(
+	ctx contractapi.TransactionContextInterface,
+	docType string,
+	limit int,
+	progress *recordMigrationProgress,
+	report *RecordMigrationReport,
+) (bool, error) {
+	if limit <= 0 {
+		return false, nil
+	}
+
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(docType, []string{})
+	if err != nil {
+		return false, err
+	}
+	defer resultsIterator.Close()
+
+	examined := 0
+	for resultsIterator.HasNext() {
+		if examined == limit {
+			return false, nil
+		}
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return false, err
+		}
+		if queryResponse.Key <= progress.LastKey {
+			continue // Examined by an earlier batch
+		}
+		examined++
+		report.Examined++
+		progress.LastKey = queryResponse.Key
+
+		version, err := documentSchemaVersion(queryResponse.Value)
+		if err != nil {
+			return false, fmt.Errorf("failed to read schema version of %s: %v", queryResponse.Key, err)
+		}
+		if version == currentSchemaVersion(docType) {
+			continue
+		}
+
+		document := documentSchemas[docType].document()
+		if err := decodeDocument(docType, queryResponse.Value, document); err != nil {
+			return false, fmt.Errorf("failed to upgrade %s: %v", queryResponse.Key, err)
+		}
+		switch docType {
+		case DocTypeLandRecord:
+			err = putLandRecord(ctx, document.(*LandRecord))
+		case DocTypeDraft:
+			err = putDraftAt(ctx, queryResponse.Key, document.(*LandRecord))
+		case DocTypePerson:
+			err = putPerson(ctx, document.(*Person))
+		default:
+			err = putDocument(ctx, queryResponse.Key, "", document)
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to store %s: %v", queryResponse.Key, err)
+		}
+		report.Migrated++
+	}
+
+	return true, nil
+}
+
+// currentSchemaVersion returns the version new documents of a type are written at
+func currentSchemaVersion(docType string) int {
+	return len(documentSchemas[docType].upcasters)
+}
+
+// documentSchemaVersion reads the schemaVersion of stored JSON (0 if absent)
+func documentSchemaVersion(data []byte) (int, error) {
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, err
+	}
+	return header.SchemaVersion, nil
+}
+
+// decodeDocument unmarshals stored JSON into v, upcasting older versions first
+// Documents from a newer schema than this chaincode knows are rejected rather
+// than silently losing fields.
+func decodeDocument(docType string, data []byte, v interface{}) error {
+	version, err := documentSchemaVersion(data)
+	if err != nil {
+		return err
+	}
+	current := currentSchemaVersion(docType)
+	if version > current {
+		return fmt.Errorf("%s schema version %d is newer than supported version %d", docType, version, current)
+	}
+	if version == current {
+		return json.Unmarshal(data, v)
+	}
+
+	// UseNumber keeps paise and milli-unit integers exact through the map
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc map[string]interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return err
+	}
+	for from := version; from < current; from++ {
+		if err := documentSchemas[docType].upcasters[from](doc); err != nil {
+			return fmt.Errorf("failed to upcast %s from version %d: %v", docType, from, err)
+		}
+	}
+	doc["schemaVersion"] = current
+
+	upcasted, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(upcasted, v)
+}
+
+// upcastLandRecordV0 adds the docType and status of unversioned land records
+func upcastLandRecordV0(doc map[string]interface{}) error {
+	if err := withDocType(DocTypeLandRecord)(doc); err != nil {
+		return err
+	}
+	if status, _ := doc["status"].(string); status == "" {
+		doc["status"] = RecordActive
+	}
+	return nil
+}
+
+// upcastDraftV1 gives drafts from before the draft lifecycle a status and deadline
+// The deadline counts from the draft's last update, as no request time was kept.
+func upcastDraftV1(doc map[string]interface{}) error {
+	if status, _ := doc["status"].(string); status == "" {
+		doc["status"] = DraftPendingID
+	}
+	if expiresAt, _ := doc["expiresAt"].(string); expiresAt == "" {
+		if lastUpdated, _ := doc["lastUpdated"].(string); lastUpdated != "" {
+			updated, err := time.Parse("2006-01-02", lastUpdated)
+			if err != nil {
+				return fmt.Errorf("invalid lastUpdated %q: %v", lastUpdated, err)
+			}
+			doc["expiresAt"] = updated.Add(draftTimeout).Format(time.RFC3339)
+		}
+	}
+	return nil
+}
+
+// upcastInlinePrivateDetails leaves owner and market value inline for now
+// putLandRecord and putDraft move them to the private collection, which an
+// upcaster cannot write, when the document is next stored (e.g. by MigrateRecords).
+func upcastInlinePrivateDetails(doc map[string]interface{}) error {
+	return nil
+}
+
+// upcastPrivateName leaves a person's name inline for now
+// putPerson moves it to the private collection when the person is next stored.
+func upcastPrivateName(doc map[string]interface{}) error {
+	return nil
+}
+
+// upcastSoleTenancy marks records from before co-ownership as solely owned
+// The owner becomes a single CoOwner when private details are loaded.
+func upcastSoleTenancy(doc map[string]interface{}) error {
+	if _, ok := doc["tenancy"]; !ok {
+		doc["tenancy"] = TenancySole
+	}
+	return nil
+}
+
+// withDocType returns an upcaster that fills in a missing docType
+func withDocType(docType string) upcaster {
+	return func(doc map[string]interface{}) error {
+		if existing, _ := doc["docType"].(string); existing == "" {
+			doc["docType"] = docType
+		}
+		return nil
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestMigrateRecordsResumesAcrossBatches(t *testing.T) {
+	s := newDeedScenario(t) // Registers three people at the current version
+
+	// Documents as older chaincode versions wrote them
+	legacy := map[[2]string]string{
+		{DocTypeLandRecord, "CCLB-2025-TS-000001"}: `{"propertyId":"CCLB-2025-TS-000001","stateCode":"TS","owner":"Ravi Kumar",
+			"surveyNo":"101","district":"Rangareddy","mandal":"Shamshabad","village":"Kothur",
+			"area":{"milli":2000,"unit":"acre","sqMetreMilli":8093713},"landType":"agricultural",
+			"marketValue":{"paise":450000000},"lastUpdated":"2023-06-01"}`,
+		{DocTypeLandRecord, "CCLB-2025-TS-000002"}: `{"propertyId":"CCLB-2025-TS-000002","stateCode":"TS","owner":"Sita Devi",
+			"surveyNo":"102","district":"Rangareddy","mandal":"Shamshabad","village":"Kothur",
+			"area":"3 acres","landType":"agricultural","marketValue":"60 L","lastUpdated":"2023-06-01"}`,
+		{DocTypeLandRecord, "CCLB-2025-TS-000003"}: `{"docType":"LAND_RECORD","schemaVersion":2,"propertyId":"CCLB-2025-TS-000003",
+			"stateCode":"TS","surveyNo":"103","district":"Rangareddy","mandal":"Shamshabad","village":"Kothur",
+			"area":{"milli":1000,"unit":"acre","sqMetreMilli":4046856},"landType":"agricultural","status":"ACTIVE",
+			"privateHashes":{"owner":"5d41402abc4b2a76b9719d911017c592"},"lastUpdated":"2024-06-01"}`,
+		{DocTypeDraft, "REQ-TS-old"}: `{"docType":"DRAFT","schemaVersion":2,"requestId":"REQ-TS-old","stateCode":"TS",
+			"owner":"Arjun Rao","surveyNo":"104","district":"Rangareddy","mandal":"Shamshabad","village":"Kothur",
+			"area":{"milli":500,"unit":"acre","sqMetreMilli":2023428},"landType":"agricultural",
+			"marketValue":{"paise":150000000},"status":"PENDING_ID","expiresAt":"2024-07-01T00:00:00Z","lastUpdated":"2024-06-01"}`,
+	}
+	keys := map[[2]string]string{}
+	s.must("tx-seed", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		for id, doc := range legacy {
+			key, err := ctx.GetStub().CreateCompositeKey(id[0], []string{id[1]})
+			if err != nil {
+				return nil, err
+			}
+			keys[id] = key
+			if err := ctx.GetStub().PutState(key, []byte(doc)); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+
+	migrate := func(txID string, batchSize int) (*RecordMigrationReport, error) {
+		endorsed, err := s.step(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.MigrateRecords(ctx, batchSize)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return endorsed.result.(*RecordMigrationReport), nil
+	}
+	for _, batchSize := range []int{0, maxMigrationBatch + 1} {
+		if _, err := migrate("tx-migrate-invalid", batchSize); err == nil {
+			t.Fatalf("batch size %d was accepted", batchSize)
+		}
+	}
+
+	tests := []struct {
+		examined int
+		migrated int
+		docType  string
+		done     bool
+	}{
+		{3, 3, DocTypeDraft, false},  // Every land record; the cursor moves to drafts
+		{3, 1, DocTypePerson, false}, // The draft, then stops inside the people
+		{1, 0, DocTypeCounter, true}, // The last person finishes the run
+		{3, 0, DocTypeDraft, false},  // A new run starts over and finds nothing to do
+	}
+	var reports []*RecordMigrationReport
+	for i, tc := range tests {
+		report, err := migrate("tx-migrate", 3)
+		if err != nil {
+			t.Fatalf("batch %d failed: %v", i+1, err)
+		}
+		if report.Examined != tc.examined || report.Migrated != tc.migrated || report.DocType != tc.docType || report.Done != tc.done {
+			t.Fatalf("batch %d = %+v, want %d examined, %d migrated, resuming in %s, done %v",
+				i+1, report, tc.examined, tc.migrated, tc.docType, tc.done)
+		}
+		reports = append(reports, report)
+	}
+	if docType, _, err := s.peer.stub.SplitCompositeKey(reports[1].NextStartKey); err != nil || docType != DocTypePerson {
+		t.Fatalf("batch 2 resumes after %q (%v), want a person key", reports[1].NextStartKey, err)
+	}
+
+	for id, key := range keys {
+		version, err := documentSchemaVersion(s.peer.stub.State[key])
+		if err != nil || version != currentSchemaVersion(id[0]) {
+			t.Fatalf("%s stored at version %d (%v), want %d", id[1], version, err, currentSchemaVersion(id[0]))
+		}
+	}
+	var public LandRecord
+	if err := json.Unmarshal(s.peer.stub.State[keys[[2]string{DocTypeLandRecord, "CCLB-2025-TS-000001"}]], &public); err != nil {
+		t.Fatalf("failed to parse migrated record: %v", err)
+	}
+	if public.DocType != DocTypeLandRecord || public.Status != RecordActive || public.Owner != "" || len(public.PrivateHashes) == 0 {
+		t.Fatalf("migrated public record %+v, want an active record with its owner moved to the private collection", public)
+	}
+	if migrated := s.owners("CCLB-2025-TS-000001"); migrated.Tenancy != TenancySole || migrated.Owners[0].Name != "Ravi Kumar" {
+		t.Fatalf("migrated record held %s by %+v, want Ravi as sole owner", migrated.Tenancy, migrated.Owners)
+	}
+	if untouched := s.owners("CCLB-2025-TS-000002"); untouched.Area.Legacy != "3 acres" {
+		t.Fatalf("area %+v, want quantities left for MigrateLandQuantities", untouched.Area)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Document types
+// Every stored document carries its type in a docType field, and the same
+// value is the object type of its composite key: <DOC_TYPE>~<id>[~<id>...]
+const (
+	DocTypeLandRecord     = "LAND_RECORD"     // propertyId
+	DocTypeDraft          = "DRAFT"           // requestId
+	DocTypeCounter        = "COUNTER"         // stateCode~year
+	DocTypeDocumentRef    = "DOC_REF"         // propertyId~documentHash
+	DocTypeApplication    = "APPLICATION"     // appId
+	DocTypeLandToken      = "LAND_TOKEN"      // tokenId
+	DocTypePerson         = "PERSON"          // personId
+	DocTypeTransfer       = "TRANSFER"        // transferId
+	DocTypeActiveTransfer = "ACTIVE_TRANSFER" // propertyId (value is the raw transfer ID)
+	DocTypeEncumbrance    = "ENCUMBRANCE"     // propertyId~encumbranceId
+	DocTypeTaxRateTable   = "TAX_RATE"        // fiscalYear
+	DocTypeTaxAssessment  = "TAX_ASSESSMENT"  // propertyId~fiscalYear
+	DocTypeMutation       = "MUTATION"        // caseId
+	DocTypeLineage        = "LINEAGE"         // operationId
+	DocTypeMigration      = "MIGRATION"       // migration name
+	DocTypeSurveyClaim    = "SURVEY_CLAIM"    // district~mandal~village~surveyNo (normalized)
+	DocTypeSuccession     = "SUCCESSION"      // caseId
+	DocTypeLease          = "LEASE"           // propertyId~leaseId
+	DocTypeCourtOrder     = "COURT_ORDER"     // propertyId~orderId
+
+	DocTypePrivateDetails = "PRIVATE_DETAILS" // recordType~id, in landPrivateCollection
+)
+
+// KeyMigrationReport summarizes one MigrateKeyLayout batch
+type KeyMigrationReport struct {
+	Migrated     int      `json:"migrated"`
+	Skipped      []string `json:"skipped"`      // Flat keys that matched no known document type
+	NextStartKey string   `json:"nextStartKey"` // Where the next batch resumes
+	Done         bool     `json:"done"`
+}
+
+// keyMigrationProgress is the resumable cursor of MigrateKeyLayout
+type keyMigrationProgress struct {
+	DocType string `json:"docType"`
+	LastKey string `json:"lastKey"`
+	Done    bool   `json:"done"`
+}
+
+// legacyKeyPrefixes maps the flat key prefixes used before composite keys
+// Property IDs had no prefix and are recognized by their JSON instead.
+var legacyKeyPrefixes = []struct {
+	prefix  string
+	docType string
+}{
+	{"COUNTER_", DocTypeCounter},
+	{"DOC_", DocTypeDocumentRef},
+	{"APP_", DocTypeApplication},
+	{"LAND_", DocTypeLandToken},
+	{"PERSON_", DocTypePerson},
+	{"REQ-", DocTypeDraft},
+	{"ACTIVE_TRANSFER_", DocTypeActiveTransfer},
+	{"TRANSFER_", DocTypeTransfer},
+	{"MUTATION_", DocTypeMutation},
+	{"LINEAGE_", DocTypeLineage},
+	{"TAXRATE_", DocTypeTaxRateTable},
+}
+
+const maxMigrationBatch = 500
+
+// MigrateKeyLayout moves documents from flat keys to typed composite keys
+// Processes up to batchSize flat keys per call and records its position, so it
+// can be invoked repeatedly until Done. Reads fall back to flat keys meanwhile.
+// Requires 'registrar' role
+func (c *LandRegistryContract) MigrateKeyLayout(
+	ctx contractapi.TransactionContextInterface,
+	batchSize int,
+) (*KeyMigrationReport, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can migrate the key layout: %v", err)
+	}
+	if batchSize < 1 || batchSize > maxMigrationBatch {
+		return nil, fmt.Errorf("batch size must be between 1 and %d", maxMigrationBatch)
+	}
+
+	progressKey, err := stateKey(ctx, DocTypeMigration, "keyLayout")
+	if err != nil {
+		return nil, err
+	}
+	progress := keyMigrationProgress{DocType: DocTypeMigration}
+	progressJSON, err := ctx.GetStub().GetState(progressKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration progress: %v", err)
+	}
+	if progressJSON != nil {
+		if err := json.Unmarshal(progressJSON, &progress); err != nil {
+			return nil, fmt.Errorf("failed to parse migration progress: %v", err)
+		}
+	}
+
+	report := &KeyMigrationReport{Skipped: []string{}}
+
+	// Composite keys live in a separate namespace, so this range sees only flat keys
+	startKey := ""
+	if progress.LastKey != "" {
+		startKey = progress.LastKey + "\x00"
+	}
+	resultsIterator, err := ctx.GetStub().GetStateByRange(startKey, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	processed := 0
+	for processed < batchSize && resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		processed++
+		progress.LastKey = queryResponse.Key
+
+		migrated, err := migrateLegacyKey(ctx, queryResponse.Key, queryResponse.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate %s: %v", queryResponse.Key, err)
+		}
+		if !migrated {
+			report.Skipped = append(report.Skipped, queryResponse.Key)
+			continue
+		}
+		report.Migrated++
+	}
+	progress.Done = !resultsIterator.HasNext()
+
+	progressJSON, err = json.Marshal(progress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal migration progress: %v", err)
+	}
+	if err := ctx.GetStub().PutState(progressKey, progressJSON); err != nil {
+		return nil, fmt.Errorf("failed to store migration progress: %v", err)
+	}
+
+	report.NextStartKey = progress.LastKey
+	report.Done = progress.Done
+	return report, nil
+}
+
+// migrateLegacyKey rewrites one flat-key document under its composite key
+// Returns false for keys that match no known document type.
+func migrateLegacyKey(ctx contractapi.TransactionContextInterface, key string, value []byte) (bool, error) {
+	docType := ""
+	id := key
+	for _, legacy := range legacyKeyPrefixes {
+		if strings.HasPrefix(key, legacy.prefix) {
+			docType = legacy.docType
+			id = strings.TrimPrefix(key, legacy.prefix)
+			break
+		}
+	}
+
+	if docType == "" {
+		var landRecord LandRecord
+		if err := decodeDocument(DocTypeLandRecord, value, &landRecord); err != nil || landRecord.PropertyID != key {
+			return false, nil
+		}
+		// putLandRecord writes the composite key, re-indexes and removes the flat key
+		return true, putLandRecord(ctx, &landRecord)
+	}
+
+	if docType == DocTypeActiveTransfer {
+		newKey, err := stateKey(ctx, docType, id)
+		if err != nil {
+			return false, err
+		}
+		return true, putRawDocument(ctx, newKey, key, value)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return false, nil
+	}
+
+	var attributes []string
+	switch docType {
+	case DocTypeCounter:
+		attributes = []string{fmt.Sprint(doc["state"]), fmt.Sprint(doc["year"])}
+	case DocTypeDocumentRef:
+		attributes = []string{fmt.Sprint(doc["propertyId"]), fmt.Sprint(doc["documentHash"])}
+	case DocTypePerson, DocTypeDraft:
+		attributes = []string{key} // The flat key is the ID (PERSON_<hash>, REQ-...)
+	default:
+		attributes = []string{id}
+	}
+
+	newKey, err := stateKey(ctx, docType, attributes...)
+	if err != nil {
+		return false, err
+	}
+	doc["docType"] = docType
+	return true, putDocument(ctx, newKey, key, doc)
+}
+
+// stateKey builds the composite key of a document
+func stateKey(ctx contractapi.TransactionContextInterface, docType string, attributes ...string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(docType, attributes)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s key: %v", docType, err)
+	}
+	return key, nil
+}
+
+// getDocument reads a document by composite key, falling back to its flat key
+// Returns nil (no error) when neither exists.
+func getDocument(ctx contractapi.TransactionContextInterface, key string, legacyKey string) ([]byte, error) {
+	value, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, err
+	}
+	if value != nil || legacyKey == "" {
+		return value, nil
+	}
+	return ctx.GetStub().GetState(legacyKey)
+}
+
+// putDocument marshals and stores a document under its composite key
+// Any copy still under the flat key is removed so reads cannot see stale data.
+func putDocument(ctx contractapi.TransactionContextInterface, key string, legacyKey string, document interface{}) error {
+	value, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return putRawDocument(ctx, key, legacyKey, value)
+}
+
+func putRawDocument(ctx contractapi.TransactionContextInterface, key string, legacyKey string, value []byte) error {
+	if err := ctx.GetStub().PutState(key, value); err != nil {
+		return err
+	}
+	return deleteLegacyKey(ctx, legacyKey)
+}
+
+// deleteDocument removes a document under both its composite and flat keys
+func deleteDocument(ctx contractapi.TransactionContextInterface, key string, legacyKey string) error {
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return err
+	}
+	return deleteLegacyKey(ctx, legacyKey)
+}
+
+// deleteLegacyKey removes a flat key only if it is still present
+func deleteLegacyKey(ctx contractapi.TransactionContextInterface, legacyKey string) error {
+	if legacyKey == "" {
+		return nil
+	}
+	legacy, err := ctx.GetStub().GetState(legacyKey)
+	if err != nil {
+		return err
+	}
+	if legacy == nil {
+		return nil
+	}
+	return ctx.GetStub().DelState(legacyKey)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// flatRangeStub serves range queries the way a peer does: composite keys live
+// in a separate namespace, and an empty end key leaves the range open
+type flatRangeStub struct {
+	*privateDataStub
+}
+
+func (s *flatRangeStub) GetStateByRange(startKey string, endKey string) (shim.StateQueryIteratorInterface, error) {
+	iterator := &kvIterator{}
+	for key, value := range s.State {
+		if key == "" || key[0] == 0x00 || key < startKey || (endKey != "" && key >= endKey) {
+			continue
+		}
+		iterator.kvs = append(iterator.kvs, &queryresult.KV{Key: key, Value: value})
+	}
+	sort.Slice(iterator.kvs, func(i, j int) bool { return iterator.kvs[i].Key < iterator.kvs[j].Key })
+	return iterator, nil
+}
+
+func TestMigrateKeyLayoutMovesFlatKeysInBatches(t *testing.T) {
+	contract := new(LandRegistryContract)
+	stub := shimtest.NewMockStub("peer0", nil)
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(&flatRangeStub{&privateDataStub{stub}})
+	peer := &endorser{stub: stub, ctx: ctx}
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	txTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	documentHash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	docRefKey := "DOC_CCLB-2025-TS-000001_" + documentHash
+
+	// Documents as the chaincode stored them before composite keys, in key order
+	legacy := []struct {
+		key        string
+		value      string
+		docType    string
+		attributes []string
+	}{
+		{"APP_APP-7", `{"appId":"APP-7","ownerId":"x509::CN=ravi","docHash":"` + documentHash + `","status":"PENDING_VERIFICATION"}`,
+			DocTypeApplication, []string{"APP-7"}},
+		{"CCLB-2025-TS-000001", `{"propertyId":"CCLB-2025-TS-000001","stateCode":"TS","owner":"Ravi Kumar",
+			"surveyNo":"101","district":"Rangareddy","mandal":"Shamshabad","village":"Kothur",
+			"area":"2 acres","landType":"agricultural","marketValue":"45 L","lastUpdated":"2023-06-01"}`,
+			DocTypeLandRecord, []string{"CCLB-2025-TS-000001"}},
+		{docRefKey, `{"propertyId":"CCLB-2025-TS-000001","documentHash":"` + documentHash + `","documentType":"SALE_DEED"}`,
+			DocTypeDocumentRef, []string{"CCLB-2025-TS-000001", documentHash}},
+		{"PERSON_5d41402a", `{"personId":"PERSON_5d41402a","name":"Ravi Kumar","role":"citizen"}`,
+			DocTypePerson, []string{"PERSON_5d41402a"}},
+	}
+	seed := map[string]string{"NOTES_1": `{"note":"not a chaincode document"}`}
+	for _, document := range legacy {
+		seed[document.key] = document.value
+	}
+	peer.endorse(t, proposal{
+		txID:    "tx-seed",
+		creator: registrar,
+		time:    txTime,
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			for key, value := range seed {
+				if err := ctx.GetStub().PutState(key, []byte(value)); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		},
+	})
+
+	migrate := func(txID string) *KeyMigrationReport {
+		return peer.endorse(t, proposal{
+			txID:    txID,
+			creator: registrar,
+			time:    txTime,
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.MigrateKeyLayout(ctx, 3)
+			},
+		}).result.(*KeyMigrationReport)
+	}
+
+	// APP_, the bare Property ID and DOC_, then the unknown key and PERSON_
+	first := migrate("tx-migrate-1")
+	if first.Migrated != 3 || len(first.Skipped) != 0 || first.NextStartKey != docRefKey || first.Done {
+		t.Fatalf("first batch = %+v, want 3 migrated, resuming after %s", first, docRefKey)
+	}
+	if _, moved := stub.State["PERSON_5d41402a"]; !moved {
+		t.Fatal("first batch went past its batch size")
+	}
+	second := migrate("tx-migrate-2")
+	if second.Migrated != 1 || !reflect.DeepEqual(second.Skipped, []string{"NOTES_1"}) || !second.Done {
+		t.Fatalf("second batch = %+v, want the person migrated, NOTES_1 skipped and the run done", second)
+	}
+	if rerun := migrate("tx-migrate-3"); rerun.Migrated != 0 || !rerun.Done {
+		t.Fatalf("batch after completion = %+v, want nothing left to do", rerun)
+	}
+
+	for _, document := range legacy {
+		if _, left := stub.State[document.key]; left {
+			t.Errorf("legacy key %s was not deleted", document.key)
+		}
+		key, err := stub.CreateCompositeKey(document.docType, document.attributes)
+		if err != nil {
+			t.Fatalf("failed to create key: %v", err)
+		}
+		var stored struct {
+			DocType string `json:"docType"`
+		}
+		if err := json.Unmarshal(stub.State[key], &stored); err != nil || stored.DocType != document.docType {
+			t.Errorf("%s moved to %q as docType %q (%v), want %s", document.key, key, stored.DocType, err, document.docType)
+		}
+	}
+	if _, kept := stub.State["NOTES_1"]; !kept {
+		t.Error("unrecognized key NOTES_1 was deleted")
+	}
+
+	// Readers find the migrated documents under their new keys
+	person, err := getPerson(ctx, "PERSON_5d41402a")
+	if err != nil || person.Name != "Ravi Kumar" {
+		t.Fatalf("migrated person %+v (%v), want Ravi", person, err)
+	}
+	landRecord, err := contract.readLandRecordWithDetails(ctx, "CCLB-2025-TS-000001")
+	if err != nil || len(landRecord.Owners) != 1 || landRecord.Owners[0].Name != "Ravi Kumar" {
+		t.Fatalf("migrated land record %+v (%v), want Ravi as owner", landRecord, err)
+	}
+	locationKey, _ := stub.CreateCompositeKey(landLocationIndex,
+		[]string{"rangareddy", "shamshabad", "kothur", "101", "CCLB-2025-TS-000001"})
+	if _, indexed := stub.State[locationKey]; !indexed {
+		t.Error("migrated land record was not indexed by location")
+	}
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SuccessionCase devolves a deceased owner's land to their heirs
+// Lifecycle:
+//
+//	DEATH_REGISTERED → HEIRS_DECLARED → (OBJECTION_FILED) → DEVOLVED | REJECTED
+//
+// A registrar registers the death against the person's PERSON_ ID, which
+// freezes the list of parcels linked to them, then declares heirs. Anyone may
+// object during the notice period; a registrar then devolves or rejects.
+// Like a mutation notice, the case is public, including the parcels involved.
+type SuccessionCase struct {
+	DocType              string              `json:"docType"` // SUCCESSION
+	CaseID               string              `json:"caseId"`
+	PersonID             string              `json:"personId"`    // PERSON_ ID of the deceased
+	DateOfDeath          string              `json:"dateOfDeath"` // YYYY-MM-DD
+	DeathCertificateHash string              `json:"deathCertificateHash"`
+	PropertyIDs          []string            `json:"propertyIds"` // Parcels the deceased co-owned
+	Basis                string              `json:"basis,omitempty"`
+	WillHash             string              `json:"willHash,omitempty"` // Registered will, for TESTAMENTARY cases
+	Heirs                []CoOwner           `json:"heirs,omitempty"`    // Share is of the deceased's holding; names are private
+	Status               string              `json:"status"`
+	RegisteredAt         string              `json:"registeredAt"`
+	NoticeIssuedAt       string              `json:"noticeIssuedAt,omitempty"`
+	NoticeEndsAt         string              `json:"noticeEndsAt,omitempty"`
+	Objections           []MutationObjection `json:"objections"`
+	DecidedBy            string              `json:"decidedBy,omitempty"`
+	DecidedAt            string              `json:"decidedAt,omitempty"`
+	DecisionRemarks      string              `json:"decisionRemarks,omitempty"`
+}
+
+// Succession case statuses
+const (
+	SuccessionDeathRegistered = "DEATH_REGISTERED"
+	SuccessionHeirsDeclared   = "HEIRS_DECLARED"
+	SuccessionObjectionFiled  = "OBJECTION_FILED"
+	SuccessionDevolved        = "DEVOLVED"
+	SuccessionRejected        = "REJECTED"
+)
+
+// Succession bases
+const (
+	SuccessionIntestate    = "INTESTATE"    // Heirs and shares per personal law
+	SuccessionTestamentary = "TESTAMENTARY" // Per a registered will
+)
+
+// successionNoticePeriod is how long objections are accepted after heirs are declared
+const successionNoticePeriod = 30 * 24 * time.Hour
+
+// RegisterDeath opens a succession case for a registered person
+// Collects the parcels linked to the person (see LinkOwnerPerson for
+// co-owners still recorded by name only) and cancels open transfers in which
+// the deceased gives one of them, which would otherwise block devolution.
+// Requires 'registrar' role
+func (c *LandRegistryContract) RegisterDeath(
+	ctx contractapi.TransactionContextInterface,
+	personID string,
+	dateOfDeath string,
+	deathCertificateHash string,
+) (*SuccessionCase, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can register deaths: %v", err)
+	}
+	if len(deathCertificateHash) < 32 {
+		return nil, fmt.Errorf("invalid death certificate hash format")
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	died, err := time.Parse("2006-01-02", dateOfDeath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date of death %q (expected YYYY-MM-DD): %v", dateOfDeath, err)
+	}
+	if died.After(txTime) {
+		return nil, fmt.Errorf("date of death %s is in the future", dateOfDeath)
+	}
+
+	person, err := getPerson(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+	if person.SuccessionCaseID != "" {
+		return nil, fmt.Errorf("death of %s is already registered in %s", personID, person.SuccessionCaseID)
+	}
+
+	propertyIDs, err := linkedPropertyIDs(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+
+	// One case per registering tx, keyed by the whole tx ID
+	succession := &SuccessionCase{
+		CaseID:               "SUC-" + ctx.GetStub().GetTxID(),
+		PersonID:             personID,
+		DateOfDeath:          dateOfDeath,
+		DeathCertificateHash: deathCertificateHash,
+		PropertyIDs:          propertyIDs,
+		Status:               SuccessionDeathRegistered,
+		RegisteredAt:         txTime.Format(time.RFC3339),
+		Objections:           []MutationObjection{},
+	}
+	if err := putSuccessionCase(ctx, succession); err != nil {
+		return nil, err
+	}
+	if err := c.cancelTransfersOfDeceased(ctx, succession); err != nil {
+		return nil, err
+	}
+
+	person.DateOfDeath = dateOfDeath
+	person.SuccessionCaseID = succession.CaseID
+	if err := putPerson(ctx, person); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitSuccessionEvent(ctx, EventDeathRegistered, succession, ""); err != nil {
+		fmt.Printf("warning: failed to emit SuccessionEvent: %v\n", err)
+	}
+
+	return succession, nil
+}
+
+// DeclareHeirs records the heirs and their shares and issues the objection notice
+// heirsJSON is a list of {"name", "personId", "share"}; shares are fractions of
+// the deceased's holding and must add up to 1. Heirs need not be registered,
+// but a given personId must belong to a person of that name. Parcels held in
+// joint tenancy pass to the surviving joint tenants regardless. After a
+// rejection, declaring heirs again replaces the rejected declaration, its
+// objections and decision, and issues a fresh notice.
+// Requires 'registrar' role
+func (c *LandRegistryContract) DeclareHeirs(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	basis string,
+	willHash string,
+	heirsJSON string,
+) (*SuccessionCase, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can declare heirs: %v", err)
+	}
+
+	basis = strings.ToUpper(strings.TrimSpace(basis))
+	switch basis {
+	case SuccessionIntestate:
+		if willHash != "" {
+			return nil, fmt.Errorf("an %s succession has no will", SuccessionIntestate)
+		}
+	case SuccessionTestamentary:
+		if len(willHash) < 32 {
+			return nil, fmt.Errorf("a %s succession needs the registered will's hash", SuccessionTestamentary)
+		}
+	default:
+		return nil, fmt.Errorf("invalid basis: %s (expected %s or %s)", basis, SuccessionIntestate, SuccessionTestamentary)
+	}
+
+	var heirs []CoOwner
+	if err := json.Unmarshal([]byte(heirsJSON), &heirs); err != nil {
+		return nil, fmt.Errorf("invalid heirs: %v", err)
+	}
+	heirs, _, err := normalizeOwners(heirs, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid heirs: %v", err)
+	}
+
+	succession, err := getSuccessionCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if succession.Status != SuccessionDeathRegistered && succession.Status != SuccessionRejected {
+		return nil, fmt.Errorf("succession case %s is already %s", caseID, succession.Status)
+	}
+	for _, heir := range heirs {
+		if heir.PersonID == "" {
+			continue
+		}
+		if heir.PersonID == succession.PersonID {
+			return nil, fmt.Errorf("the deceased cannot be their own heir")
+		}
+		if err := requireOwnerPerson(ctx, heir.PersonID, heir.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Heirs need not be registered persons, so their names go to the private collection
+	if err := storePrivateParties(ctx, DocTypeSuccession, caseID, heirs); err != nil {
+		return nil, err
+	}
+	succession.Basis = basis
+	succession.WillHash = willHash
+	succession.Heirs = withoutNames(heirs)
+	succession.Status = SuccessionHeirsDeclared
+	succession.NoticeIssuedAt = txTime.Format(time.RFC3339)
+	succession.NoticeEndsAt = txTime.Add(successionNoticePeriod).Format(time.RFC3339)
+	succession.Objections = []MutationObjection{}
+	succession.DecidedBy = ""
+	succession.DecidedAt = ""
+	succession.DecisionRemarks = ""
+	if err := putSuccessionCase(ctx, succession); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitSuccessionEvent(ctx, EventHeirsDeclared, succession, ""); err != nil {
+		fmt.Printf("warning: failed to emit SuccessionEvent: %v\n", err)
+	}
+
+	return succession, nil
+}
+
+// FileSuccessionObjection lets any identified party object during the notice period
+func (c *LandRegistryContract) FileSuccessionObjection(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	grounds string,
+	documentHash string,
+) (*SuccessionCase, error) {
+	if strings.TrimSpace(grounds) == "" {
+		return nil, fmt.Errorf("grounds for objection are required")
+	}
+
+	succession, err := getSuccessionCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if succession.Status != SuccessionHeirsDeclared && succession.Status != SuccessionObjectionFiled {
+		return nil, fmt.Errorf("succession case %s is %s, not open for objections", caseID, succession.Status)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	noticeEnds, err := time.Parse(time.RFC3339, succession.NoticeEndsAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notice period on %s: %v", caseID, err)
+	}
+	if txTime.After(noticeEnds) {
+		return nil, fmt.Errorf("notice period for %s ended at %s", caseID, succession.NoticeEndsAt)
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+
+	succession.Objections = append(succession.Objections, MutationObjection{
+		FiledBy:      clientID,
+		Grounds:      grounds,
+		DocumentHash: documentHash,
+		FiledAt:      txTime.Format(time.RFC3339),
+		TxID:         ctx.GetStub().GetTxID(),
+	})
+	succession.Status = SuccessionObjectionFiled
+
+	if err := putSuccessionCase(ctx, succession); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitSuccessionEvent(ctx, EventSuccessionObjectionFiled, succession, grounds); err != nil {
+		fmt.Printf("warning: failed to emit SuccessionEvent: %v\n", err)
+	}
+
+	return succession, nil
+}
+
+// DecideSuccession devolves the deceased's parcels to the heirs, or rejects the declaration
+// decision is APPROVE or REJECT, after the notice period. Approval rewrites the
+// owners of every parcel in the case and links it from each LandRecord. A
+// rejected declaration can be replaced by declaring heirs again.
+// Requires 'registrar' role
+func (c *LandRegistryContract) DecideSuccession(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+	decision string,
+	remarks string,
+) (*SuccessionCase, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can decide successions: %v", err)
+	}
+
+	decision = strings.ToUpper(strings.TrimSpace(decision))
+	if decision != "APPROVE" && decision != "REJECT" {
+		return nil, fmt.Errorf("invalid decision: %s (expected APPROVE or REJECT)", decision)
+	}
+
+	succession, err := getSuccessionCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if succession.Status != SuccessionHeirsDeclared && succession.Status != SuccessionObjectionFiled {
+		return nil, fmt.Errorf("succession case %s is %s, not awaiting a decision", caseID, succession.Status)
+	}
+	if (len(succession.Objections) > 0 || decision == "REJECT") && strings.TrimSpace(remarks) == "" {
+		return nil, fmt.Errorf("remarks are required when rejecting or when objections were filed")
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	noticeEnds, err := time.Parse(time.RFC3339, succession.NoticeEndsAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notice period on %s: %v", caseID, err)
+	}
+	if !txTime.After(noticeEnds) {
+		return nil, fmt.Errorf("notice period for %s runs until %s", caseID, succession.NoticeEndsAt)
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+
+	eventName := EventSuccessionRejected
+	succession.Status = SuccessionRejected
+	if decision == "APPROVE" {
+		heirs, err := loadPrivateParties(ctx, DocTypeSuccession, caseID)
+		if err != nil {
+			return nil, err
+		}
+		for _, propertyID := range succession.PropertyIDs {
+			if err := c.devolveParcel(ctx, succession, heirs, propertyID, txTime); err != nil {
+				return nil, err
+			}
+		}
+		eventName = EventSuccessionDevolved
+		succession.Status = SuccessionDevolved
+	}
+	succession.DecidedBy = clientID
+	succession.DecidedAt = txTime.Format(time.RFC3339)
+	succession.DecisionRemarks = remarks
+
+	if err := putSuccessionCase(ctx, succession); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitSuccessionEvent(ctx, eventName, succession, remarks); err != nil {
+		fmt.Printf("warning: failed to emit SuccessionEvent: %v\n", err)
+	}
+
+	return succession, nil
+}
+
+// ReadSuccessionCase retrieves a succession case by ID
+func (c *LandRegistryContract) ReadSuccessionCase(
+	ctx contractapi.TransactionContextInterface,
+	caseID string,
+) (*SuccessionCase, error) {
+	return getSuccessionCase(ctx, caseID)
+}
+
+// cancelTransfersOfDeceased ends open transfers of the deceased's parcels that they are a party to
+// A co-owner's own transfer of their share is left to run its course.
+func (c *LandRegistryContract) cancelTransfersOfDeceased(
+	ctx contractapi.TransactionContextInterface,
+	succession *SuccessionCase,
+) error {
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return err
+	}
+	for _, propertyID := range succession.PropertyIDs {
+		transferID, err := getActiveTransferID(ctx, propertyID)
+		if err != nil {
+			return err
+		}
+		if transferID == "" {
+			continue
+		}
+		transfer, err := getTransferRequest(ctx, transferID)
+		if err != nil {
+			return err
+		}
+		if transfer.SellerID != succession.PersonID && transfer.BuyerID != succession.PersonID {
+			continue
+		}
+		reason := fmt.Sprintf("death of %s registered in %s", succession.PersonID, succession.CaseID)
+		if _, err := c.endTransfer(ctx, transfer, TransferCancelled, clientID, reason, EventTransferCancelled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// devolveParcel passes the deceased's share of one parcel to its successors
+// Joint tenants survive to the share; otherwise the declared heirs take it.
+func (c *LandRegistryContract) devolveParcel(
+	ctx contractapi.TransactionContextInterface,
+	succession *SuccessionCase,
+	heirs []CoOwner,
+	propertyID string,
+	txTime time.Time,
+) error {
+	landRecord, err := c.readLandRecordWithDetails(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if landRecord.Status == RecordRetired {
+		return fmt.Errorf("land record %s was retired after the death was registered; register the successor parcels", propertyID)
+	}
+	active, err := getActiveTransferID(ctx, propertyID)
+	if err != nil {
+		return err
+	}
+	if active != "" {
+		return fmt.Errorf("property %s has an open transfer %s", propertyID, active)
+	}
+	if err := requireNotFrozen(ctx, propertyID); err != nil {
+		return err
+	}
+
+	deceased := findCoOwner(landRecord.Owners, &Person{PersonID: succession.PersonID})
+	if deceased < 0 {
+		return fmt.Errorf("%s no longer holds a share of %s", succession.PersonID, propertyID)
+	}
+	if landRecord.Tenancy == TenancyJoint {
+		survivorship(landRecord, deceased)
+	} else {
+		devolveShare(landRecord, deceased, heirs)
+	}
+	landRecord.SuccessionCaseID = succession.CaseID
+	landRecord.LastUpdated = txTime.Format("2006-01-02")
+	return putLandRecord(ctx, landRecord)
+}
+
+// linkedPropertyIDs returns the active parcels linked to a person, in Property ID order
+func linkedPropertyIDs(ctx contractapi.TransactionContextInterface, personID string) ([]string, error) {
+	indexIterator, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(landPrivateCollection, landPersonIndex, []string{personID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s index: %v", landPersonIndex, err)
+	}
+	defer indexIterator.Close()
+
+	propertyIDs := []string{}
+	for indexIterator.HasNext() {
+		queryResponse, err := indexIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		landRecord, err := readIndexedLandRecord(ctx, queryResponse.Key)
+		if err != nil {
+			return nil, err
+		}
+		if landRecord != nil && landRecord.Status != RecordRetired {
+			propertyIDs = append(propertyIDs, landRecord.PropertyID)
+		}
+	}
+	return propertyIDs, nil
+}
+
+func getSuccessionCase(ctx contractapi.TransactionContextInterface, caseID string) (*SuccessionCase, error) {
+	key, err := stateKey(ctx, DocTypeSuccession, caseID)
+	if err != nil {
+		return nil, err
+	}
+	successionJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read succession case: %v", err)
+	}
+	if successionJSON == nil {
+		return nil, fmt.Errorf("succession case %s does not exist", caseID)
+	}
+
+	var succession SuccessionCase
+	if err := json.Unmarshal(successionJSON, &succession); err != nil {
+		return nil, fmt.Errorf("failed to parse succession case: %v", err)
+	}
+	return &succession, nil
+}
+
+func putSuccessionCase(ctx contractapi.TransactionContextInterface, succession *SuccessionCase) error {
+	succession.DocType = DocTypeSuccession
+	key, err := stateKey(ctx, DocTypeSuccession, succession.CaseID)
+	if err != nil {
+		return err
+	}
+	if err := putDocument(ctx, key, "", succession); err != nil {
+		return fmt.Errorf("failed to store succession case: %v", err)
+	}
+	return nil
+}
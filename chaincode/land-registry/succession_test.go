@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestSuccessionDevolvesSharesAfterNotice(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, sitaID, arjunID := s.personIDs["ravi"], s.personIDs["sita"], s.personIDs["arjun"]
+	owners := []CoOwner{
+		{Name: "Ravi Kumar", PersonID: raviID, Share: "1/2"},
+		{Name: "Sita Devi", PersonID: sitaID, Share: "1/2"},
+	}
+	s.recordWithTenancy("CCLB-2025-TS-000001", "101/A", TenancyInCommon, owners...)
+	s.recordWithTenancy("CCLB-2025-TS-000002", "101/B", TenancyJoint, owners...)
+
+	caseID := s.must("tx-death", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RegisterDeath(ctx, raviID, "2025-02-20", testDeedHash)
+	}).(*SuccessionCase).CaseID
+	heirs, _ := json.Marshal([]CoOwner{
+		{Name: "Arjun Rao", PersonID: arjunID, Share: "2/3"},
+		{Name: "Meena Kumari", Share: "1/3"},
+	})
+	succession := s.must("tx-heirs", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.DeclareHeirs(ctx, caseID, SuccessionIntestate, "", string(heirs))
+	}).(*SuccessionCase)
+	if len(succession.PropertyIDs) != 2 {
+		t.Fatalf("case covers %v, want both of Ravi's parcels", succession.PropertyIDs)
+	}
+
+	registered := s.txTime
+	decide := func(txID string) error {
+		_, err := s.step(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.DecideSuccession(ctx, caseID, "APPROVE", "")
+		})
+		return err
+	}
+	s.txTime = registered.Add(24 * time.Hour)
+	if err := decide("tx-decide-early"); err == nil || !strings.Contains(err.Error(), "notice period") {
+		t.Fatalf("decision inside the notice period: err = %v, want a notice period error", err)
+	}
+	s.txTime = registered.Add(successionNoticePeriod + time.Hour)
+	if err := decide("tx-decide"); err != nil {
+		t.Fatalf("devolution failed: %v", err)
+	}
+
+	inCommon := s.owners("CCLB-2025-TS-000001")
+	want := []CoOwner{
+		{Name: "Sita Devi", PersonID: sitaID, Share: "1/2"},
+		{Name: "Arjun Rao", PersonID: arjunID, Share: "1/3"},
+		{Name: "Meena Kumari", Share: "1/6"},
+	}
+	if len(inCommon.Owners) != len(want) || inCommon.SuccessionCaseID != caseID {
+		t.Fatalf("tenancy in common after succession: owners %+v, case %q; want %+v from %s", inCommon.Owners, inCommon.SuccessionCaseID, want, caseID)
+	}
+	for i := range want {
+		if inCommon.Owners[i] != want[i] {
+			t.Errorf("owner %d = %+v, want %+v", i, inCommon.Owners[i], want[i])
+		}
+	}
+
+	joint := s.owners("CCLB-2025-TS-000002")
+	if joint.Tenancy != TenancySole || len(joint.Owners) != 1 || joint.Owners[0].PersonID != sitaID || joint.Owners[0].Share != "1/1" {
+		t.Fatalf("joint tenancy after succession: tenancy %s, owners %+v; want Sita as sole survivor", joint.Tenancy, joint.Owners)
+	}
+}
+
+func TestRejectedSuccessionCanBeDeclaredAgain(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, sitaID, arjunID := s.personIDs["ravi"], s.personIDs["sita"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+
+	caseID := s.must("tx-death", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RegisterDeath(ctx, raviID, "2025-02-20", testDeedHash)
+	}).(*SuccessionCase).CaseID
+	declare := func(txID string, heir CoOwner) {
+		heirs, _ := json.Marshal([]CoOwner{heir})
+		s.must(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.DeclareHeirs(ctx, caseID, SuccessionIntestate, "", string(heirs))
+		})
+	}
+	decide := func(txID string, decision string) *SuccessionCase {
+		return s.must(txID, s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.DecideSuccession(ctx, caseID, decision, "Heirship certificate names another heir")
+		}).(*SuccessionCase)
+	}
+
+	declare("tx-heirs", CoOwner{Name: "Sita Devi", PersonID: sitaID, Share: "1"})
+	s.must("tx-object", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.FileSuccessionObjection(ctx, caseID, "I am the only son", testDeedHash)
+	})
+	s.txTime = s.txTime.Add(successionNoticePeriod + time.Hour)
+	decide("tx-reject", "REJECT")
+
+	declare("tx-heirs-again", CoOwner{Name: "Arjun Rao", PersonID: arjunID, Share: "1"})
+	if _, err := s.step("tx-decide-early", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.DecideSuccession(ctx, caseID, "APPROVE", "")
+	}); err == nil || !strings.Contains(err.Error(), "notice period") {
+		t.Fatalf("decision inside the fresh notice period: err = %v, want a notice period error", err)
+	}
+	s.txTime = s.txTime.Add(successionNoticePeriod + time.Hour)
+	devolved := decide("tx-approve", "APPROVE")
+	if devolved.Status != SuccessionDevolved || len(devolved.Objections) != 0 {
+		t.Fatalf("case %s with objections %v, want DEVOLVED without the rejected declaration's objections", devolved.Status, devolved.Objections)
+	}
+	if landRecord := s.owners("CCLB-2025-TS-000001"); len(landRecord.Owners) != 1 || landRecord.Owners[0].PersonID != arjunID {
+		t.Fatalf("owners after succession %+v, want Arjun", landRecord.Owners)
+	}
+}
+
+func TestRegisteringDeathCancelsTheDeceasedsOpenTransfers(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, arjunID := s.personIDs["ravi"], s.personIDs["arjun"]
+	s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+
+	sell := func(txID string) (endorsement, error) {
+		return s.step(txID, s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+		})
+	}
+	initiated, err := sell("tx-initiate")
+	if err != nil {
+		t.Fatalf("sale failed: %v", err)
+	}
+	transferID := initiated.result.(*TransferRequest).TransferID
+
+	s.must("tx-death", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+		return s.contract.RegisterDeath(ctx, raviID, "2025-02-20", testDeedHash)
+	})
+	transfer, err := s.contract.ReadTransferRequest(s.peer.ctx, transferID)
+	if err != nil || transfer.Status != TransferCancelled {
+		t.Fatalf("transfer after the seller's death: %+v, %v; want it cancelled", transfer, err)
+	}
+	if active, _ := getActiveTransferID(s.peer.ctx, "CCLB-2025-TS-000001"); active != "" {
+		t.Fatalf("parcel still locked by %s", active)
+	}
+	if _, err := sell("tx-initiate-again"); err == nil || !strings.Contains(err.Error(), "deceased") {
+		t.Fatalf("sale by a deceased seller: err = %v, want it refused", err)
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// SurveyClaim reserves a normalized district/mandal/village/survey tuple
+// Held by a pending draft from RequestPropertyID, then by the active land record
+// bound to it. Reading the claim key (not a rich query) makes concurrent
+// duplicate requests conflict at validation time.
+type SurveyClaim struct {
+	DocType    string `json:"docType"` // SURVEY_CLAIM
+	District   string `json:"district"`
+	Mandal     string `json:"mandal"`
+	Village    string `json:"village"`
+	SurveyNo   string `json:"surveyNo"`
+	PropertyID string `json:"propertyId,omitempty"` // Active land record holding the tuple
+	RequestID  string `json:"requestId,omitempty"`  // Pending draft holding the tuple
+}
+
+// ParcelConflict lists active land records sharing one survey tuple
+type ParcelConflict struct {
+	District    string   `json:"district"`
+	Mandal      string   `json:"mandal"`
+	Village     string   `json:"village"`
+	SurveyNo    string   `json:"surveyNo"`
+	PropertyIDs []string `json:"propertyIds"`
+}
+
+// GetParcelConflictReport lists survey tuples held by more than one active parcel
+// Such duplicates predate uniqueness checks and must be resolved by a registrar
+// (for example by amalgamating or correcting one of the records).
+func (c *LandRegistryContract) GetParcelConflictReport(
+	ctx contractapi.TransactionContextInterface,
+) ([]*ParcelConflict, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(landLocationIndex, []string{})
+	if err != nil {
+		return nil, err
+	}
+	defer resultsIterator.Close()
+
+	conflicts := []*ParcelConflict{}
+	var group *ParcelConflict
+	flush := func() error {
+		if group == nil || len(group.PropertyIDs) < 2 {
+			return nil
+		}
+		active, err := activePropertyIDs(ctx, group.PropertyIDs)
+		if err != nil {
+			return err
+		}
+		if len(active) > 1 {
+			group.PropertyIDs = active
+			conflicts = append(conflicts, group)
+		}
+		return nil
+	}
+
+	// Index keys sort by tuple, so the parcels of one tuple are adjacent
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 5 {
+			return nil, fmt.Errorf("invalid location index key: %v", err)
+		}
+
+		if group == nil || group.District != attributes[0] || group.Mandal != attributes[1] ||
+			group.Village != attributes[2] || group.SurveyNo != attributes[3] {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			group = &ParcelConflict{
+				District: attributes[0],
+				Mandal:   attributes[1],
+				Village:  attributes[2],
+				SurveyNo: attributes[3],
+			}
+		}
+		group.PropertyIDs = append(group.PropertyIDs, attributes[4])
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return conflicts, nil
+}
+
+// requireSurveyAvailable rejects a survey tuple already held by another parcel or draft
+// requestID is the draft being bound (if any); parcels in releasing are being
+// retired by the same transaction and may pass their tuple on.
+func requireSurveyAvailable(
+	ctx contractapi.TransactionContextInterface,
+	lookup LandLookup,
+	requestID string,
+	releasing []string,
+) error {
+	claim, err := getSurveyClaim(ctx, lookup)
+	if err != nil {
+		return err
+	}
+	if claim != nil {
+		switch {
+		case claim.PropertyID != "" && !contains(releasing, claim.PropertyID):
+			return fmt.Errorf("%s is already registered as %s", describeSurvey(lookup), claim.PropertyID)
+		case claim.PropertyID == "" && claim.RequestID != requestID:
+			return fmt.Errorf("%s is already requested by draft %s", describeSurvey(lookup), claim.RequestID)
+		}
+	}
+
+	// Records written before claims existed are only in the location index
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(landLocationIndex,
+		[]string{lookup.District, lookup.Mandal, lookup.Village, lookup.SurveyNo})
+	if err != nil {
+		return err
+	}
+	defer resultsIterator.Close()
+
+	var indexed []string
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 5 {
+			return fmt.Errorf("invalid location index key: %v", err)
+		}
+		if !contains(releasing, attributes[4]) {
+			indexed = append(indexed, attributes[4])
+		}
+	}
+	active, err := activePropertyIDs(ctx, indexed)
+	if err != nil {
+		return err
+	}
+	if len(active) > 0 {
+		return fmt.Errorf("%s is already registered as %s", describeSurvey(lookup), active[0])
+	}
+
+	return nil
+}
+
+// claimLandSurvey moves a land record's survey claim along with the record
+// Called from indexLandRecord with the committed version of the record (or nil).
+// Chaincode reads do not see this transaction's writes, so parcels retired by
+// an operation must be written before the parcels that take over their tuples.
+func claimLandSurvey(ctx contractapi.TransactionContextInterface, previous *LandRecord, landRecord *LandRecord) error {
+	if previous != nil && previous.Status != RecordRetired {
+		previousLookup := newLandLookup(previous)
+		if landRecord.Status == RecordRetired || !sameSurvey(previousLookup, landRecord.Lookup) {
+			if err := releaseSurveyClaim(ctx, previousLookup, previous.PropertyID, ""); err != nil {
+				return err
+			}
+		}
+	}
+	if landRecord.Status == RecordRetired {
+		return nil
+	}
+	return putSurveyClaim(ctx, landRecord.Lookup, landRecord.PropertyID, "")
+}
+
+// putSurveyClaim records the holder of a survey tuple
+func putSurveyClaim(ctx contractapi.TransactionContextInterface, lookup LandLookup, propertyID string, requestID string) error {
+	key, err := surveyClaimKey(ctx, lookup)
+	if err != nil {
+		return err
+	}
+	claim := SurveyClaim{
+		DocType:    DocTypeSurveyClaim,
+		District:   lookup.District,
+		Mandal:     lookup.Mandal,
+		Village:    lookup.Village,
+		SurveyNo:   lookup.SurveyNo,
+		PropertyID: propertyID,
+		RequestID:  requestID,
+	}
+	if err := putDocument(ctx, key, "", claim); err != nil {
+		return fmt.Errorf("failed to store survey claim: %v", err)
+	}
+	return nil
+}
+
+// releaseSurveyClaim frees a survey tuple if it is still held by the given parcel or draft
+func releaseSurveyClaim(ctx contractapi.TransactionContextInterface, lookup LandLookup, propertyID string, requestID string) error {
+	claim, err := getSurveyClaim(ctx, lookup)
+	if err != nil {
+		return err
+	}
+	if claim == nil || claim.PropertyID != propertyID || claim.RequestID != requestID {
+		return nil
+	}
+	key, err := surveyClaimKey(ctx, lookup)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return fmt.Errorf("failed to release survey claim: %v", err)
+	}
+	return nil
+}
+
+func getSurveyClaim(ctx contractapi.TransactionContextInterface, lookup LandLookup) (*SurveyClaim, error) {
+	key, err := surveyClaimKey(ctx, lookup)
+	if err != nil {
+		return nil, err
+	}
+	claimJSON, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read survey claim: %v", err)
+	}
+	if claimJSON == nil {
+		return nil, nil
+	}
+
+	var claim SurveyClaim
+	if err := json.Unmarshal(claimJSON, &claim); err != nil {
+		return nil, fmt.Errorf("failed to parse survey claim: %v", err)
+	}
+	return &claim, nil
+}
+
+func surveyClaimKey(ctx contractapi.TransactionContextInterface, lookup LandLookup) (string, error) {
+	return stateKey(ctx, DocTypeSurveyClaim, lookup.District, lookup.Mandal, lookup.Village, lookup.SurveyNo)
+}
+
+// activePropertyIDs filters Property IDs down to parcels that are not retired
+func activePropertyIDs(ctx contractapi.TransactionContextInterface, propertyIDs []string) ([]string, error) {
+	active := []string{}
+	for _, propertyID := range propertyIDs {
+		landRecordJSON, err := getLandRecordJSON(ctx, propertyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read land record %s: %v", propertyID, err)
+		}
+		if landRecordJSON == nil {
+			continue // Stale index entry
+		}
+		var landRecord LandRecord
+		if err := decodeDocument(DocTypeLandRecord, landRecordJSON, &landRecord); err != nil {
+			return nil, fmt.Errorf("failed to parse land record %s: %v", propertyID, err)
+		}
+		if landRecord.Status != RecordRetired {
+			active = append(active, propertyID)
+		}
+	}
+	return active, nil
+}
+
+func sameSurvey(a LandLookup, b LandLookup) bool {
+	return a.District == b.District && a.Mandal == b.Mandal && a.Village == b.Village && a.SurveyNo == b.SurveyNo
+}
+
+// describeSurvey names a survey tuple in error messages
+func describeSurvey(lookup LandLookup) string {
+	return fmt.Sprintf("survey %s in %s/%s/%s", lookup.SurveyNo, lookup.Village, lookup.Mandal, lookup.District)
+}
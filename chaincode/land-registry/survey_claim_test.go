@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestDuplicateParcelsAreRejected(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peer := newEndorser("peer0")
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	txTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	request := func(txID string, surveyNo string) proposal {
+		return proposal{
+			txID:    txID,
+			creator: registrar,
+			time:    txTime,
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.RequestPropertyID(ctx, "TS", "Ravi Kumar", surveyNo, "Rangareddy",
+					"Shamshabad", "Kothur", "2 acres", "agricultural", "45 L", "")
+			},
+		}
+	}
+	bind := func(txID string, propertyID string, requestID string) proposal {
+		return proposal{
+			txID:    txID,
+			creator: registrar,
+			time:    txTime.Add(time.Hour),
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.CreateStateRecord(ctx, propertyID, requestID, "")
+			},
+		}
+	}
+	expectError := func(p proposal, want string) {
+		t.Helper()
+		if _, err := peer.submit(t, p); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: error = %v, want one naming %s", p.txID, err, want)
+		}
+	}
+
+	first := peer.endorse(t, request("tx-request-1", "101/A")).result.(string)
+
+	// The same tuple, spelled differently, is still the same parcel
+	expectError(request("tx-request-2", " 101/a "), first)
+
+	peer.endorse(t, bind("tx-bind-1", "CCLB-2025-TS-000001", first))
+	expectError(request("tx-request-3", "101/A"), "CCLB-2025-TS-000001")
+
+	second := peer.endorse(t, request("tx-request-4", "102")).result.(string)
+	expectError(bind("tx-bind-2", "CCLB-2025-TS-000001", second), "CCLB-2025-TS-000001")
+
+	// Cancelling a draft frees its tuple for a new request
+	peer.endorse(t, proposal{
+		txID:    "tx-cancel",
+		creator: registrar,
+		time:    txTime.Add(time.Hour),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.CancelDraft(ctx, second, "wrong village")
+		},
+	})
+	peer.endorse(t, request("tx-request-5", "102"))
+}
+
+func TestParcelConflictReportListsExistingDuplicates(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peer := newEndorser("peer0")
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+
+	// Records written before uniqueness checks, bypassing the transactions
+	peer.endorse(t, proposal{
+		txID:    "tx-legacy",
+		creator: registrar,
+		time:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			for _, record := range []*LandRecord{
+				{PropertyID: "CCLB-2024-TS-000001", StateCode: "TS", District: "Rangareddy", Mandal: "Shamshabad", Village: "Kothur", SurveyNo: "7"},
+				{PropertyID: "CCLB-2024-TS-000002", StateCode: "TS", District: "rangareddy", Mandal: "Shamshabad", Village: "Kothur", SurveyNo: "7"},
+				{PropertyID: "CCLB-2024-TS-000003", StateCode: "TS", District: "Rangareddy", Mandal: "Shamshabad", Village: "Kothur", SurveyNo: "8"},
+			} {
+				if err := putLandRecord(ctx, record); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		},
+	})
+
+	for key := range peer.stub.State {
+		if strings.HasPrefix(key, "\x00"+DocTypeSurveyClaim+"\x00") {
+			delete(peer.stub.State, key)
+		}
+	}
+
+	conflicts, err := contract.GetParcelConflictReport(peer.ctx)
+	if err != nil {
+		t.Fatalf("conflict report failed: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].SurveyNo != "7" || len(conflicts[0].PropertyIDs) != 2 {
+		t.Fatalf("conflicts = %+v, want survey 7 held by two parcels", conflicts)
+	}
+
+	// Legacy records are found through the location index even without a claim
+	_, err = peer.submit(t, proposal{
+		txID:    "tx-request",
+		creator: registrar,
+		time:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.RequestPropertyID(ctx, "TS", "Ravi Kumar", "8", "Rangareddy",
+				"Shamshabad", "Kothur", "2 acres", "agricultural", "45 L", "")
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "CCLB-2024-TS-000003") {
+		t.Fatalf("error = %v, want one naming CCLB-2024-TS-000003", err)
+	}
+}
+
+func TestAmalgamationPassesOnParentSurvey(t *testing.T) {
+	contract := new(LandRegistryContract)
+	peer := newEndorser("peer0")
+	registrar := testCreator(t, "StateOrgTSMSP", "registrar")
+	txTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	requestAndBind := func(n int, surveyNo string) string {
+		propertyID := fmt.Sprintf("CCLB-2025-TS-%06d", n)
+		requestID := peer.endorse(t, proposal{
+			txID:    fmt.Sprintf("tx-request-%d", n),
+			creator: registrar,
+			time:    txTime,
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.RequestPropertyID(ctx, "TS", "Ravi Kumar", surveyNo, "Rangareddy",
+					"Shamshabad", "Kothur", "1 acre", "agricultural", "20 L", "")
+			},
+		}).result.(string)
+		peer.endorse(t, proposal{
+			txID:    fmt.Sprintf("tx-bind-%d", n),
+			creator: registrar,
+			time:    txTime,
+			invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return contract.CreateStateRecord(ctx, propertyID, requestID, "")
+			},
+		})
+		return propertyID
+	}
+	first, second := requestAndBind(1, "20"), requestAndBind(2, "21")
+
+	peer.endorse(t, proposal{
+		txID:    "tx-amalgamate",
+		creator: registrar,
+		time:    txTime.Add(time.Hour),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.AmalgamateProperties(ctx, `["`+first+`","`+second+`"]`, "CCLB-2025-TS-000003", "20")
+		},
+	})
+
+	claim, err := getSurveyClaim(peer.ctx, LandLookup{District: "rangareddy", Mandal: "shamshabad", Village: "kothur", SurveyNo: "20"})
+	if err != nil || claim == nil || claim.PropertyID != "CCLB-2025-TS-000003" {
+		t.Fatalf("survey 20 claim = %+v (%v), want the amalgamated parcel", claim, err)
+	}
+	if conflicts, err := contract.GetParcelConflictReport(peer.ctx); err != nil || len(conflicts) != 0 {
+		t.Fatalf("conflicts = %+v (%v), want none", conflicts, err)
+	}
+
+	// The retired parent's other survey number is free again
+	peer.endorse(t, proposal{
+		txID:    "tx-request-3",
+		creator: registrar,
+		time:    txTime.Add(2 * time.Hour),
+		invoke: func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return contract.RequestPropertyID(ctx, "TS", "Sita Devi", "21", "Rangareddy",
+				"Shamshabad", "Kothur", "1 acre", "agricultural", "20 L", "")
+		},
+	})
+}
@@ -0,0 +1,900 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// TransferRequest tracks a staged deed from initiation to ownership change
+// Lifecycle:
+//
+//	INITIATED (seller) → ACCEPTED (buyer) → VERIFIED (sub-registrar) → COMPLETED (registrar)
+//
+// Any open stage may end in REJECTED, CANCELLED (seller) or EXPIRED (stage timeout).
+// Only COMPLETED touches the LandRecord. A co-owner conveys their whole share;
+// out of a joint tenancy that needs every other joint tenant's consent. The
+// seller and buyer are the grantor and grantee of whatever DeedType it is.
+type TransferRequest struct {
+	DocType           string         `json:"docType"` // TRANSFER
+	TransferID        string         `json:"transferId"`
+	PropertyID        string         `json:"propertyId"`
+	DeedType          string         `json:"deedType"`                   // See Deed* types; SALE for requests opened before deed types
+	StampDutyCategory string         `json:"stampDutyCategory"`          // See StampDuty* categories
+	SellerID          string         `json:"sellerId"`                   // PERSON_ ID of the seller; names stay private
+	BuyerID           string         `json:"buyerId"`                    // PERSON_ ID of the buyer
+	Share             string         `json:"share"`                      // Fraction of the parcel sold, "1/1" for all of it
+	RequiredConsents  []string       `json:"requiredConsents,omitempty"` // PERSON_ IDs of joint tenants who must consent
+	Consents          []string       `json:"consents,omitempty"`         // Those who have
+	SaleConsideration Money          `json:"saleConsideration"`          // Zero for gifts and settlements
+	SaleDeedHash      string         `json:"saleDeedHash"`               // Hash of the deed, whatever its type
+	StampDutyPaid     Money          `json:"stampDutyPaid"`              // Zero until VerifyTransferDocuments
+	StampDutyReceipt  string         `json:"stampDutyReceipt,omitempty"`
+	Status            string         `json:"status"`
+	StatusReason      string         `json:"statusReason,omitempty"`
+	InitiatedAt       string         `json:"initiatedAt"`
+	UpdatedAt         string         `json:"updatedAt"`
+	ExpiresAt         string         `json:"expiresAt"`                // Deadline for the current stage
+	MutationCaseID    string         `json:"mutationCaseId,omitempty"` // Opened on completion
+	History           []TransferStep `json:"history"`
+
+	ExchangePropertyID     string             `json:"exchangePropertyId,omitempty"`     // EXCHANGE: the buyer's parcel, locked alongside PropertyID
+	ExchangeShare          string             `json:"exchangeShare,omitempty"`          // EXCHANGE: the buyer's share of it
+	ExchangeMutationCaseID string             `json:"exchangeMutationCaseId,omitempty"` // EXCHANGE: opened on completion
+	Allotments             []SubdivisionChild `json:"allotments,omitempty"`             // PARTITION: parcels each co-owner takes
+	LineageOperationID     string             `json:"lineageOperationId,omitempty"`     // PARTITION: the subdivision, on completion
+
+	Leases []string `json:"leases,omitempty"` // Active leases over the parcels at initiation; the buyer takes subject to them
+}
+
+// TransferStep is one status change of a TransferRequest
+type TransferStep struct {
+	Status    string `json:"status"`
+	Actor     string `json:"actor"` // Client ID (or PERSON_ ID) of the caller
+	Note      string `json:"note,omitempty"`
+	Timestamp string `json:"timestamp"`
+	TxID      string `json:"txId"`
+}
+
+// Transfer request statuses
+const (
+	TransferInitiated = "INITIATED"
+	TransferAccepted  = "ACCEPTED"
+	TransferVerified  = "VERIFIED"
+	TransferCompleted = "COMPLETED"
+	TransferRejected  = "REJECTED"
+	TransferCancelled = "CANCELLED"
+	TransferExpired   = "EXPIRED"
+)
+
+const (
+	// Pre-migration flat key prefixes
+	transferKeyPrefix       = "TRANSFER_"
+	activeTransferKeyPrefix = "ACTIVE_TRANSFER_"
+
+	// transferPartyIndex lists the open transfers of each seller and buyer
+	transferPartyIndex = "TRANSFER~party" // personId~transferId
+
+	// transferStageTimeout is how long each party has to act before the request can be expired
+	transferStageTimeout = 30 * 24 * time.Hour
+)
+
+// InitiateTransfer opens a sale of the caller's property, or share of it, to a registered buyer
+// Caller must be a citizen who is a linked co-owner or, for co-owners not yet
+// linked to a person, whose registered name matches theirs
+func (c *LandRegistryContract) InitiateTransfer(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	buyerID string,
+	saleConsideration string,
+	saleDeedHash string,
+) (*TransferRequest, error) {
+	return c.InitiateConveyance(ctx, propertyID, DeedSale, buyerID, saleConsideration, saleDeedHash, "")
+}
+
+// InitiateConveyance opens a deed of any type conveying the caller's share of a property
+// termsJSON is a DeedTerms object for an exchange or partition and empty
+// otherwise. Callers are as for InitiateTransfer; the grantee acts as buyer.
+func (c *LandRegistryContract) InitiateConveyance(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+	deedType string,
+	granteeID string,
+	consideration string,
+	deedHash string,
+	termsJSON string,
+) (*TransferRequest, error) {
+	if err := requireRole(ctx, "citizen"); err != nil {
+		return nil, fmt.Errorf("only citizens can initiate transfers: %v", err)
+	}
+
+	deedType = strings.ToUpper(strings.TrimSpace(deedType))
+	stampDutyCategory, ok := deedStampDutyCategories[deedType]
+	if !ok {
+		return nil, fmt.Errorf("invalid deed type %q (must be %s, %s, %s, %s, %s or %s)", deedType,
+			DeedSale, DeedGift, DeedSettlement, DeedRelease, DeedExchange, DeedPartition)
+	}
+	if len(deedHash) < 32 {
+		return nil, fmt.Errorf("invalid deed hash format")
+	}
+	amount, err := parseConsideration(deedType, consideration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid consideration: %v", err)
+	}
+	var terms DeedTerms
+	if strings.TrimSpace(termsJSON) != "" {
+		if err := json.Unmarshal([]byte(termsJSON), &terms); err != nil {
+			return nil, fmt.Errorf("invalid deed terms: %v", err)
+		}
+	}
+
+	landRecord, err := c.readLandRecordWithDetails(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if landRecord.Status == RecordRetired {
+		return nil, fmt.Errorf("land record %s is retired; transfer its successor parcels instead", propertyID)
+	}
+	if landRecord.VerificationStatus == VerificationRejected {
+		return nil, fmt.Errorf("land record %s is flagged by CCLB: %s", propertyID, landRecord.VerificationReason)
+	}
+
+	sellerID, err := callerPersonID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seller, err := getPerson(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if seller.DateOfDeath != "" {
+		return nil, fmt.Errorf("seller %s is deceased; the parcel passes by succession %s", sellerID, seller.SuccessionCaseID)
+	}
+	sellerIndex := findCoOwner(landRecord.Owners, seller)
+	if sellerIndex < 0 {
+		return nil, fmt.Errorf("caller is not an owner of %s", propertyID)
+	}
+	holding := landRecord.Owners[sellerIndex]
+	// A partition conveys the whole parcel, so it needs no severance first
+	if deedType != DeedPartition {
+		if err := requireShareConveyable(landRecord); err != nil {
+			return nil, err
+		}
+	}
+
+	var requiredConsents []string
+	if landRecord.Tenancy == TenancyJoint {
+		for i, coOwner := range landRecord.Owners {
+			if i == sellerIndex {
+				continue
+			}
+			if coOwner.PersonID == "" {
+				return nil, fmt.Errorf("joint tenant %s must be linked to a registered person before a share can be transferred", coOwner.Name)
+			}
+			requiredConsents = append(requiredConsents, coOwner.PersonID)
+		}
+	}
+
+	if granteeID == sellerID {
+		return nil, fmt.Errorf("buyer and seller must differ")
+	}
+	buyer, err := getPerson(ctx, granteeID)
+	if err != nil {
+		return nil, fmt.Errorf("buyer: %v", err)
+	}
+	if buyer.DateOfDeath != "" {
+		return nil, fmt.Errorf("buyer %s is deceased", granteeID)
+	}
+
+	active, err := getActiveTransferID(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if active != "" {
+		return nil, fmt.Errorf("property %s already has an open transfer %s", propertyID, active)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txID := ctx.GetStub().GetTxID()
+
+	transfer := &TransferRequest{
+		TransferID:        transferIDFromTx(txID),
+		PropertyID:        propertyID,
+		DeedType:          deedType,
+		StampDutyCategory: stampDutyCategory,
+		SellerID:          sellerID,
+		BuyerID:           granteeID,
+		Share:             holding.Share,
+		RequiredConsents:  requiredConsents,
+		SaleConsideration: amount,
+		SaleDeedHash:      deedHash,
+		Status:            TransferInitiated,
+		InitiatedAt:       txTime.Format(time.RFC3339),
+		UpdatedAt:         txTime.Format(time.RFC3339),
+		ExpiresAt:         txTime.Add(transferStageTimeout).Format(time.RFC3339),
+		History: []TransferStep{{
+			Status:    TransferInitiated,
+			Actor:     sellerID,
+			Timestamp: txTime.Format(time.RFC3339),
+			TxID:      txID,
+		}},
+	}
+	if err := c.prepareDeed(ctx, landRecord, transfer, buyer, terms); err != nil {
+		return nil, err
+	}
+	// No lease can be registered while the parcels are locked, so this list stays complete
+	for _, locked := range transfer.lockedProperties() {
+		if err := requireNotFrozen(ctx, locked); err != nil {
+			return nil, err
+		}
+		leases, err := listActiveLeases(ctx, locked)
+		if err != nil {
+			return nil, err
+		}
+		for _, lease := range leases {
+			transfer.Leases = append(transfer.Leases, lease.LeaseID)
+		}
+	}
+
+	if err := putTransferRequest(ctx, transfer); err != nil {
+		return nil, err
+	}
+	for _, locked := range transfer.lockedProperties() {
+		activeKey, err := stateKey(ctx, DocTypeActiveTransfer, locked)
+		if err != nil {
+			return nil, err
+		}
+		if err := ctx.GetStub().PutState(activeKey, []byte(transfer.TransferID)); err != nil {
+			return nil, fmt.Errorf("failed to lock property for transfer: %v", err)
+		}
+	}
+
+	if err := c.emitTransferEvent(ctx, EventTransferInitiated, transfer, ""); err != nil {
+		fmt.Printf("warning: failed to emit TransferStatusChangedEvent: %v\n", err)
+	}
+
+	return transfer, nil
+}
+
+// AcceptTransfer records the buyer's acceptance of an initiated transfer
+func (c *LandRegistryContract) AcceptTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+) (*TransferRequest, error) {
+	if err := requireRole(ctx, "citizen"); err != nil {
+		return nil, fmt.Errorf("only citizens can accept transfers: %v", err)
+	}
+
+	transfer, txTime, err := openTransferAtStage(ctx, transferID, TransferInitiated)
+	if err != nil {
+		return nil, err
+	}
+
+	callerID, err := callerPersonID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if callerID != transfer.BuyerID {
+		return nil, fmt.Errorf("only the buyer can accept transfer %s", transferID)
+	}
+
+	advanceTransfer(ctx, transfer, TransferAccepted, callerID, "", txTime)
+	if err := putTransferRequest(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitTransferEvent(ctx, EventTransferAccepted, transfer, TransferInitiated); err != nil {
+		fmt.Printf("warning: failed to emit TransferStatusChangedEvent: %v\n", err)
+	}
+
+	return transfer, nil
+}
+
+// ConsentToShareTransfer records a joint tenant's consent to a co-owner selling their share
+// Allowed at any open stage; ApproveTransfer needs every required consent.
+func (c *LandRegistryContract) ConsentToShareTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+) (*TransferRequest, error) {
+	if err := requireRole(ctx, "citizen"); err != nil {
+		return nil, fmt.Errorf("only citizens can consent to transfers: %v", err)
+	}
+
+	transfer, err := getTransferRequest(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOpenTransfer(transfer.Status) {
+		return nil, fmt.Errorf("transfer %s is already %s", transferID, transfer.Status)
+	}
+
+	callerID, err := callerPersonID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(transfer.RequiredConsents, callerID) {
+		return nil, fmt.Errorf("transfer %s does not need the caller's consent", transferID)
+	}
+	if contains(transfer.Consents, callerID) {
+		return nil, fmt.Errorf("caller already consented to transfer %s", transferID)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Consent does not advance the stage, so its deadline stays as it was
+	transfer.Consents = append(transfer.Consents, callerID)
+	transfer.UpdatedAt = txTime.Format(time.RFC3339)
+	transfer.History = append(transfer.History, TransferStep{
+		Status:    transfer.Status,
+		Actor:     callerID,
+		Note:      "co-owner consent",
+		Timestamp: txTime.Format(time.RFC3339),
+		TxID:      ctx.GetStub().GetTxID(),
+	})
+	if err := putTransferRequest(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitTransferEvent(ctx, EventTransferConsent, transfer, transfer.Status); err != nil {
+		fmt.Printf("warning: failed to emit TransferStatusChangedEvent: %v\n", err)
+	}
+
+	return transfer, nil
+}
+
+// VerifyTransferDocuments records stamp duty payment and document checks
+// Requires 'jt_sub_registrar' role
+func (c *LandRegistryContract) VerifyTransferDocuments(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+	stampDutyPaid string,
+	stampDutyReceipt string,
+) (*TransferRequest, error) {
+	if err := requireRole(ctx, "jt_sub_registrar"); err != nil {
+		return nil, fmt.Errorf("only sub-registrars can verify transfer documents: %v", err)
+	}
+
+	if strings.TrimSpace(stampDutyReceipt) == "" {
+		return nil, fmt.Errorf("stamp duty receipt reference is required")
+	}
+	stampDuty, err := parseRupees(stampDutyPaid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stamp duty amount: %v", err)
+	}
+
+	transfer, txTime, err := openTransferAtStage(ctx, transferID, TransferAccepted)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.StampDutyPaid = stampDuty
+	transfer.StampDutyReceipt = stampDutyReceipt
+	advanceTransfer(ctx, transfer, TransferVerified, clientID, "receipt "+stampDutyReceipt, txTime)
+	if err := putTransferRequest(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitTransferEvent(ctx, EventTransferVerified, transfer, TransferAccepted); err != nil {
+		fmt.Printf("warning: failed to emit TransferStatusChangedEvent: %v\n", err)
+	}
+
+	return transfer, nil
+}
+
+// ApproveTransfer is the registrar's final sign-off; only here does Owner change
+// An exchange changes both parcels in this one transaction. A partition
+// retires the parcel in favour of the allotted ones, which enter the revenue
+// records as new holdings, so it opens no mutation case.
+// Requires 'registrar' role
+func (c *LandRegistryContract) ApproveTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+) (*TransferRequest, error) {
+	if err := requireRole(ctx, "registrar"); err != nil {
+		return nil, fmt.Errorf("only registrars can approve transfers: %v", err)
+	}
+
+	transfer, txTime, err := openTransferAtStage(ctx, transferID, TransferVerified)
+	if err != nil {
+		return nil, err
+	}
+
+	landRecord, err := c.readLandRecordWithDetails(ctx, transfer.PropertyID)
+	if err != nil {
+		return nil, err
+	}
+	if landRecord.Status == RecordRetired {
+		return nil, fmt.Errorf("land record %s was retired since transfer %s was initiated", transfer.PropertyID, transferID)
+	}
+	// Parties are matched by PERSON_ ID, or by name while the owner is unlinked
+	sellerPerson, err := getPerson(ctx, transfer.SellerID)
+	if err != nil {
+		return nil, err
+	}
+	buyerPerson, err := getPerson(ctx, transfer.BuyerID)
+	if err != nil {
+		return nil, err
+	}
+	seller := findCoOwner(landRecord.Owners, sellerPerson)
+	if seller < 0 || landRecord.Owners[seller].Share != transfer.Share {
+		return nil, fmt.Errorf("ownership of %s changed since transfer %s was initiated", transfer.PropertyID, transferID)
+	}
+	sellerName := landRecord.Owners[seller].Name // As recorded, for the parcel given in exchange
+	for _, personID := range transfer.RequiredConsents {
+		if !contains(transfer.Consents, personID) {
+			return nil, fmt.Errorf("transfer %s still needs the consent of joint tenant %s", transferID, personID)
+		}
+	}
+
+	var exchanged *LandRecord
+	buyer := -1
+	if transfer.DeedType == DeedExchange {
+		if exchanged, err = c.readLandRecordWithDetails(ctx, transfer.ExchangePropertyID); err != nil {
+			return nil, err
+		}
+		if exchanged.Status == RecordRetired {
+			return nil, fmt.Errorf("land record %s was retired since transfer %s was initiated", exchanged.PropertyID, transferID)
+		}
+		buyer = findCoOwner(exchanged.Owners, buyerPerson)
+		if buyer < 0 || exchanged.Owners[buyer].Share != transfer.ExchangeShare {
+			return nil, fmt.Errorf("ownership of %s changed since transfer %s was initiated", exchanged.PropertyID, transferID)
+		}
+	}
+
+	for _, propertyID := range transfer.lockedProperties() {
+		// A court order recorded since initiation holds the transfer until it is lifted
+		if err := requireNotFrozen(ctx, propertyID); err != nil {
+			return nil, err
+		}
+
+		// Active mortgages/charges block the transfer unless the lender consented to it
+		if err := checkEncumbrancesCleared(ctx, propertyID, transferID); err != nil {
+			return nil, err
+		}
+
+		// Property tax must be fully paid ("no dues") before ownership changes
+		if err := checkNoTaxDues(ctx, propertyID); err != nil {
+			return nil, err
+		}
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.DeedType == DeedPartition {
+		// Charges and leases are recorded against a Property ID and would not follow the land
+		if err := requireNoActiveEncumbrances(ctx, transfer.PropertyID); err != nil {
+			return nil, err
+		}
+		if err := requireNoActiveLeases(ctx, transfer.PropertyID); err != nil {
+			return nil, err
+		}
+		_, operation, err := subdivideParcel(ctx, landRecord, transfer.Allotments, LineagePartition)
+		if err != nil {
+			return nil, err
+		}
+		transfer.LineageOperationID = operation.OperationID
+	} else {
+		if transfer.DeedType == DeedRelease {
+			// A co-owner still recorded by name only is linked, so the shares merge
+			if grantee := findCoOwner(landRecord.Owners, buyerPerson); grantee >= 0 {
+				landRecord.Owners[grantee].PersonID = transfer.BuyerID
+			}
+		}
+		transferShare(landRecord, seller, buyerPerson.Name, transfer.BuyerID)
+		if transfer.Share == "1/1" {
+			landRecord.OwnerIDNumber = "" // The seller's; the buyer's is not known here
+		}
+		landRecord.LastUpdated = txTime.Format("2006-01-02")
+
+		// Conveyance is complete; revenue records still need mutation by a tahsildar
+		mutation, err := openMutationCase(ctx, transfer, landRecord, txTime)
+		if err != nil {
+			return nil, err
+		}
+		transfer.MutationCaseID = mutation.CaseID
+
+		if err := putLandRecord(ctx, landRecord); err != nil {
+			return nil, err
+		}
+	}
+
+	if exchanged != nil {
+		transferShare(exchanged, buyer, sellerName, transfer.SellerID)
+		if transfer.ExchangeShare == "1/1" {
+			exchanged.OwnerIDNumber = ""
+		}
+		exchanged.LastUpdated = txTime.Format("2006-01-02")
+
+		mutation, err := openMutationCase(ctx, transfer, exchanged, txTime)
+		if err != nil {
+			return nil, err
+		}
+		transfer.ExchangeMutationCaseID = mutation.CaseID
+
+		if err := putLandRecord(ctx, exchanged); err != nil {
+			return nil, err
+		}
+	}
+
+	advanceTransfer(ctx, transfer, TransferCompleted, clientID, "", txTime)
+	if err := closeTransfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	// Fabric keeps only the last SetEvent of a transaction, so this single event
+	// marks the transfer completed, lists every parcel that changed hands and
+	// announces their mutation notices to the backend
+	if err := c.emitPropertyTransferredEvent(ctx, transfer); err != nil {
+		fmt.Printf("warning: failed to emit PropertyTransferredEvent: %v\n", err)
+	}
+
+	return transfer, nil
+}
+
+// RejectTransfer ends an open transfer without touching the LandRecord
+// Who may reject depends on the stage:
+//   - INITIATED: the buyer (declining the offer)
+//   - ACCEPTED:  a sub-registrar (documents or stamp duty deficient)
+//   - VERIFIED:  a registrar
+func (c *LandRegistryContract) RejectTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+	reason string,
+) (*TransferRequest, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("a rejection reason is required")
+	}
+
+	transfer, err := getTransferRequest(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+
+	var actor string
+	switch transfer.Status {
+	case TransferInitiated:
+		if err := requireRole(ctx, "citizen"); err != nil {
+			return nil, fmt.Errorf("only the buyer can decline transfer %s: %v", transferID, err)
+		}
+		if actor, err = callerPersonID(ctx); err != nil {
+			return nil, err
+		}
+		if actor != transfer.BuyerID {
+			return nil, fmt.Errorf("only the buyer can decline transfer %s", transferID)
+		}
+	case TransferAccepted:
+		if err := requireRole(ctx, "jt_sub_registrar"); err != nil {
+			return nil, fmt.Errorf("only sub-registrars can reject transfer %s: %v", transferID, err)
+		}
+	case TransferVerified:
+		if err := requireRole(ctx, "registrar"); err != nil {
+			return nil, fmt.Errorf("only registrars can reject transfer %s: %v", transferID, err)
+		}
+	default:
+		return nil, fmt.Errorf("transfer %s is already %s", transferID, transfer.Status)
+	}
+	if actor == "" {
+		if actor, err = ctx.GetClientIdentity().GetID(); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.endTransfer(ctx, transfer, TransferRejected, actor, reason, EventTransferRejected)
+}
+
+// CancelTransfer lets the seller withdraw a transfer before registrar approval
+func (c *LandRegistryContract) CancelTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+	reason string,
+) (*TransferRequest, error) {
+	if err := requireRole(ctx, "citizen"); err != nil {
+		return nil, fmt.Errorf("only citizens can cancel transfers: %v", err)
+	}
+
+	transfer, err := getTransferRequest(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOpenTransfer(transfer.Status) {
+		return nil, fmt.Errorf("transfer %s is already %s", transferID, transfer.Status)
+	}
+
+	callerID, err := callerPersonID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if callerID != transfer.SellerID {
+		return nil, fmt.Errorf("only the seller can cancel transfer %s", transferID)
+	}
+
+	return c.endTransfer(ctx, transfer, TransferCancelled, callerID, reason, EventTransferCancelled)
+}
+
+// ExpireTransfer closes a transfer whose current stage deadline has passed
+// Anyone may call this; it only succeeds once ExpiresAt is in the past
+func (c *LandRegistryContract) ExpireTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+) (*TransferRequest, error) {
+	transfer, err := getTransferRequest(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOpenTransfer(transfer.Status) {
+		return nil, fmt.Errorf("transfer %s is already %s", transferID, transfer.Status)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+	expired, err := stageExpired(transfer, txTime)
+	if err != nil {
+		return nil, err
+	}
+	if !expired {
+		return nil, fmt.Errorf("transfer %s does not expire until %s", transferID, transfer.ExpiresAt)
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.endTransfer(ctx, transfer, TransferExpired, clientID, "stage deadline "+transfer.ExpiresAt+" passed", EventTransferExpired)
+}
+
+// ReadTransferRequest retrieves a transfer request by ID
+func (c *LandRegistryContract) ReadTransferRequest(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+) (*TransferRequest, error) {
+	return getTransferRequest(ctx, transferID)
+}
+
+// GetActiveTransfer returns the open transfer for a property, if any
+func (c *LandRegistryContract) GetActiveTransfer(
+	ctx contractapi.TransactionContextInterface,
+	propertyID string,
+) (*TransferRequest, error) {
+	transferID, err := getActiveTransferID(ctx, propertyID)
+	if err != nil {
+		return nil, err
+	}
+	if transferID == "" {
+		return nil, fmt.Errorf("property %s has no open transfer", propertyID)
+	}
+	return getTransferRequest(ctx, transferID)
+}
+
+// endTransfer moves an open transfer to a terminal status and releases the property lock
+func (c *LandRegistryContract) endTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transfer *TransferRequest,
+	status string,
+	actor string,
+	reason string,
+	eventName string,
+) (*TransferRequest, error) {
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := transfer.Status
+	transfer.StatusReason = reason
+	advanceTransfer(ctx, transfer, status, actor, reason, txTime)
+	if err := closeTransfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	if err := c.emitTransferEvent(ctx, eventName, transfer, previous); err != nil {
+		fmt.Printf("warning: failed to emit TransferStatusChangedEvent: %v\n", err)
+	}
+
+	return transfer, nil
+}
+
+// openTransferAtStage loads a transfer and checks it is at the expected, unexpired stage
+func openTransferAtStage(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+	stage string,
+) (*TransferRequest, time.Time, error) {
+	transfer, err := getTransferRequest(ctx, transferID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if transfer.Status != stage {
+		return nil, time.Time{}, fmt.Errorf("transfer %s is %s, expected %s", transferID, transfer.Status, stage)
+	}
+
+	txTime, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	expired, err := stageExpired(transfer, txTime)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if expired {
+		return nil, time.Time{}, fmt.Errorf("transfer %s expired at %s", transferID, transfer.ExpiresAt)
+	}
+
+	return transfer, txTime, nil
+}
+
+// advanceTransfer applies a status change, appends history and resets the stage deadline
+func advanceTransfer(
+	ctx contractapi.TransactionContextInterface,
+	transfer *TransferRequest,
+	status string,
+	actor string,
+	note string,
+	txTime time.Time,
+) {
+	transfer.Status = status
+	transfer.UpdatedAt = txTime.Format(time.RFC3339)
+	if isOpenTransfer(status) {
+		transfer.ExpiresAt = txTime.Add(transferStageTimeout).Format(time.RFC3339)
+	}
+	transfer.History = append(transfer.History, TransferStep{
+		Status:    status,
+		Actor:     actor,
+		Note:      note,
+		Timestamp: txTime.Format(time.RFC3339),
+		TxID:      ctx.GetStub().GetTxID(),
+	})
+}
+
+// closeTransfer persists a terminal transfer and clears the property's open-transfer lock
+func closeTransfer(ctx contractapi.TransactionContextInterface, transfer *TransferRequest) error {
+	if err := putTransferRequest(ctx, transfer); err != nil {
+		return err
+	}
+	for _, locked := range transfer.lockedProperties() {
+		activeKey, err := stateKey(ctx, DocTypeActiveTransfer, locked)
+		if err != nil {
+			return err
+		}
+		if err := deleteDocument(ctx, activeKey, activeTransferKeyPrefix+locked); err != nil {
+			return fmt.Errorf("failed to release transfer lock: %v", err)
+		}
+	}
+	return nil
+}
+
+// getActiveTransferID returns the open transfer locking a property, or "" if none
+func getActiveTransferID(ctx contractapi.TransactionContextInterface, propertyID string) (string, error) {
+	activeKey, err := stateKey(ctx, DocTypeActiveTransfer, propertyID)
+	if err != nil {
+		return "", err
+	}
+	transferID, err := getDocument(ctx, activeKey, activeTransferKeyPrefix+propertyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to read active transfer: %v", err)
+	}
+	return string(transferID), nil
+}
+
+// stageExpired reports whether the transfer's current stage deadline has passed
+func stageExpired(transfer *TransferRequest, now time.Time) (bool, error) {
+	expiresAt, err := time.Parse(time.RFC3339, transfer.ExpiresAt)
+	if err != nil {
+		return false, fmt.Errorf("invalid expiry on transfer %s: %v", transfer.TransferID, err)
+	}
+	return now.After(expiresAt), nil
+}
+
+func isOpenTransfer(status string) bool {
+	return status == TransferInitiated || status == TransferAccepted || status == TransferVerified
+}
+
+// transferIDFromTx derives the transfer ID from the initiating tx
+// The whole tx ID is kept, since a tx initiates at most one transfer but two
+// tx IDs may share any prefix.
+func transferIDFromTx(txID string) string {
+	return "TRF-" + txID
+}
+
+func getTransferRequest(
+	ctx contractapi.TransactionContextInterface,
+	transferID string,
+) (*TransferRequest, error) {
+	key, err := stateKey(ctx, DocTypeTransfer, transferID)
+	if err != nil {
+		return nil, err
+	}
+	transferJSON, err := getDocument(ctx, key, transferKeyPrefix+transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transfer request: %v", err)
+	}
+	if transferJSON == nil {
+		return nil, fmt.Errorf("transfer request %s does not exist", transferID)
+	}
+
+	var transfer TransferRequest
+	if err := json.Unmarshal(transferJSON, &transfer); err != nil {
+		return nil, fmt.Errorf("failed to parse transfer request: %v", err)
+	}
+	if transfer.DeedType == "" {
+		// Requests opened before deed types were recorded were all sales
+		transfer.DeedType = DeedSale
+		transfer.StampDutyCategory = StampDutyConveyance
+	}
+	return &transfer, nil
+}
+
+func putTransferRequest(ctx contractapi.TransactionContextInterface, transfer *TransferRequest) error {
+	transfer.DocType = DocTypeTransfer
+	key, err := stateKey(ctx, DocTypeTransfer, transfer.TransferID)
+	if err != nil {
+		return err
+	}
+	if err := putDocument(ctx, key, transferKeyPrefix+transfer.TransferID, transfer); err != nil {
+		return fmt.Errorf("failed to store transfer request: %v", err)
+	}
+
+	for _, personID := range []string{transfer.SellerID, transfer.BuyerID} {
+		partyKey, err := ctx.GetStub().CreateCompositeKey(transferPartyIndex, []string{personID, transfer.TransferID})
+		if err != nil {
+			return fmt.Errorf("failed to create %s index key: %v", transferPartyIndex, err)
+		}
+		if isOpenTransfer(transfer.Status) {
+			err = ctx.GetStub().PutState(partyKey, []byte{0x00})
+		} else {
+			err = ctx.GetStub().DelState(partyKey)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update %s index: %v", transferPartyIndex, err)
+		}
+	}
+	return nil
+}
+
+// getOpenTransfersOf returns the open transfers in which a person is seller or buyer
+func getOpenTransfersOf(ctx contractapi.TransactionContextInterface, personID string) ([]*TransferRequest, error) {
+	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(transferPartyIndex, []string{personID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s index: %v", transferPartyIndex, err)
+	}
+	defer resultsIterator.Close()
+
+	transfers := []*TransferRequest{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, attributes, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil || len(attributes) != 2 {
+			return nil, fmt.Errorf("failed to split index key: %v", err)
+		}
+
+		transfer, err := getTransferRequest(ctx, attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		if isOpenTransfer(transfer.Status) {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestClosedTransferFreesTheParcel(t *testing.T) {
+	tests := []struct {
+		name    string
+		stage   string // How far the transfer gets before it is closed
+		refused func(s *deedScenario, transferID string) (endorsement, error)
+		refusal string
+		close   func(s *deedScenario, transferID string) (endorsement, error)
+		status  string
+	}{
+		{
+			name:  "buyer declines",
+			stage: TransferInitiated,
+			refused: func(s *deedScenario, transferID string) (endorsement, error) {
+				return s.step("tx-decline-seller", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.RejectTransfer(ctx, transferID, "Changed my mind")
+				})
+			},
+			refusal: "only the buyer",
+			close: func(s *deedScenario, transferID string) (endorsement, error) {
+				return s.step("tx-decline", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.RejectTransfer(ctx, transferID, "Price too high")
+				})
+			},
+			status: TransferRejected,
+		},
+		{
+			name:  "sub-registrar rejects",
+			stage: TransferAccepted,
+			refused: func(s *deedScenario, transferID string) (endorsement, error) {
+				return s.step("tx-reject-registrar", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.RejectTransfer(ctx, transferID, "Stamp duty unpaid")
+				})
+			},
+			refusal: "only sub-registrars",
+			close: func(s *deedScenario, transferID string) (endorsement, error) {
+				return s.step("tx-reject", s.subRegistrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.RejectTransfer(ctx, transferID, "Stamp duty unpaid")
+				})
+			},
+			status: TransferRejected,
+		},
+		{
+			name:  "registrar rejects",
+			stage: TransferVerified,
+			refused: func(s *deedScenario, transferID string) (endorsement, error) {
+				return s.step("tx-reject-blank", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.RejectTransfer(ctx, transferID, " ")
+				})
+			},
+			refusal: "reason is required",
+			close: func(s *deedScenario, transferID string) (endorsement, error) {
+				return s.step("tx-reject", s.registrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.RejectTransfer(ctx, transferID, "Deed schedule does not match survey 101/A")
+				})
+			},
+			status: TransferRejected,
+		},
+		{
+			name:  "seller cancels",
+			stage: TransferVerified,
+			refused: func(s *deedScenario, transferID string) (endorsement, error) {
+				return s.step("tx-cancel-buyer", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.CancelTransfer(ctx, transferID, "Loan not sanctioned")
+				})
+			},
+			refusal: "only the seller",
+			close: func(s *deedScenario, transferID string) (endorsement, error) {
+				return s.step("tx-cancel", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.CancelTransfer(ctx, transferID, "Family objected to the sale")
+				})
+			},
+			status: TransferCancelled,
+		},
+		{
+			name:  "stage deadline passes",
+			stage: TransferAccepted,
+			refused: func(s *deedScenario, transferID string) (endorsement, error) {
+				return s.step("tx-expire-early", s.citizens["sita"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.ExpireTransfer(ctx, transferID)
+				})
+			},
+			refusal: "does not expire",
+			close: func(s *deedScenario, transferID string) (endorsement, error) {
+				s.txTime = s.txTime.Add(transferStageTimeout + time.Hour)
+				return s.step("tx-expire", s.citizens["sita"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.ExpireTransfer(ctx, transferID)
+				})
+			},
+			status: TransferExpired,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newDeedScenario(t)
+			raviID, sitaID, arjunID := s.personIDs["ravi"], s.personIDs["sita"], s.personIDs["arjun"]
+			s.record("CCLB-2025-TS-000001", "101/A", CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"})
+
+			transfer := s.must("tx-initiate", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", arjunID, "45 L", testDeedHash)
+			}).(*TransferRequest)
+			if tc.stage != TransferInitiated {
+				s.must("tx-accept", s.citizens["arjun"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.AcceptTransfer(ctx, transfer.TransferID)
+				})
+			}
+			if tc.stage == TransferVerified {
+				s.must("tx-verify", s.subRegistrar, func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+					return s.contract.VerifyTransferDocuments(ctx, transfer.TransferID, "1.5 L", "SD-2025-0001")
+				})
+			}
+
+			if _, err := tc.refused(s, transfer.TransferID); err == nil || !strings.Contains(err.Error(), tc.refusal) {
+				t.Fatalf("err = %v, want a %q error", err, tc.refusal)
+			}
+			closed, err := tc.close(s, transfer.TransferID)
+			if err != nil {
+				t.Fatalf("closing the transfer failed: %v", err)
+			}
+			if ended := closed.result.(*TransferRequest); ended.Status != tc.status {
+				t.Fatalf("transfer is %s, want %s", ended.Status, tc.status)
+			}
+			if _, err := tc.close(s, transfer.TransferID); err == nil || !strings.Contains(err.Error(), "already") {
+				t.Fatalf("closing the transfer again: err = %v, want it already closed", err)
+			}
+
+			active, err := getActiveTransferID(s.peer.ctx, "CCLB-2025-TS-000001")
+			if err != nil || active != "" {
+				t.Fatalf("parcel still locked by transfer %q (%v)", active, err)
+			}
+			if landRecord := s.owners("CCLB-2025-TS-000001"); landRecord.Owners[0].PersonID != raviID {
+				t.Fatalf("owners after a closed transfer %+v, want Ravi", landRecord.Owners)
+			}
+			s.must("tx-initiate-again", s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+				return s.contract.InitiateTransfer(ctx, "CCLB-2025-TS-000001", sitaID, "45 L", testDeedHash)
+			})
+		})
+	}
+}
+
+func TestTransferIDsKeepTheWholeTxID(t *testing.T) {
+	s := newDeedScenario(t)
+	raviID, arjunID := s.personIDs["ravi"], s.personIDs["arjun"]
+	ravi := CoOwner{Name: "Ravi Kumar", PersonID: raviID, Share: "1"}
+	s.record("CCLB-2025-TS-000001", "101/A", ravi)
+	s.record("CCLB-2025-TS-000002", "102", ravi)
+
+	// Fabric tx IDs are 64 hex digits; these two agree on their first 16
+	txIDs := map[string]string{
+		"CCLB-2025-TS-000001": "5f1c0e9a7d3b2c41" + strings.Repeat("a", 48),
+		"CCLB-2025-TS-000002": "5f1c0e9a7d3b2c41" + strings.Repeat("b", 48),
+	}
+	for propertyID, txID := range txIDs {
+		transfer := s.must(txID, s.citizens["ravi"], func(ctx contractapi.TransactionContextInterface) (interface{}, error) {
+			return s.contract.InitiateTransfer(ctx, propertyID, arjunID, "45 L", testDeedHash)
+		}).(*TransferRequest)
+		if transfer.TransferID != "TRF-"+txID {
+			t.Fatalf("transfer ID %s, want TRF- and the whole tx ID", transfer.TransferID)
+		}
+	}
+	for propertyID, txID := range txIDs {
+		transfer, err := getTransferRequest(s.peer.ctx, "TRF-"+txID)
+		if err != nil || transfer.PropertyID != propertyID {
+			t.Fatalf("transfer TRF-%s is %+v (%v), want the one for %s", txID, transfer, err, propertyID)
+		}
+	}
+}
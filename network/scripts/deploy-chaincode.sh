@@ -58,6 +58,19 @@ deploy_chaincode() {
     echo -e "${GREEN}✓ Chaincode deployment complete for $CHANNEL_NAME${NC}"
 }
 
+# Function to write the land registry's private data collection config for a
+# state channel (state-ts -> StateOrgTSMSP) and print its path
+collections_config() {
+    local CHANNEL_NAME=$1
+    local STATE_CODE=$(echo "${CHANNEL_NAME#state-}" | tr '[:lower:]' '[:upper:]')
+    local CONFIG=$NETWORK_DIR/channel-artifacts/collections_config_${STATE_CODE}.json
+
+    mkdir -p "$NETWORK_DIR/channel-artifacts"
+    sed "s/\${STATE_MSP}/StateOrg${STATE_CODE}MSP/" \
+        $PROJECT_ROOT/chaincode/land-registry/collections_config.template.json > "$CONFIG"
+    echo "$CONFIG"
+}
+
 # Function to approve and commit chaincode
 approve_and_commit() {
     local CHANNEL_NAME=$1
@@ -83,6 +96,12 @@ approve_and_commit() {
     
     echo "  Package ID: $PACKAGE_ID"
     
+    # Only landregistry keeps a private data collection, scoped to the channel's state
+    local COLLECTIONS_ARGS=()
+    if [ "$CHAINCODE_NAME" = "landregistry" ]; then
+        COLLECTIONS_ARGS=(--collections-config "$(collections_config $CHANNEL_NAME)")
+    fi
+    
     # 3. Approve chaincode definition
     echo "  [3] Approving chaincode definition..."
     peer lifecycle chaincode approveformyorg \
@@ -91,6 +110,7 @@ approve_and_commit() {
         --version $CHAINCODE_VERSION \
         --package-id $PACKAGE_ID \
         --sequence 1 \
+        "${COLLECTIONS_ARGS[@]}" \
         --tls --cafile $NETWORK_DIR/crypto-config/ordererOrganizations/orderer.landregistry.local/orderers/orderer0.orderer.landregistry.local/tls/ca.crt \
         --ordererTLSHostnameOverride orderer0.orderer.landregistry.local \
         -o localhost:7050
@@ -117,6 +137,8 @@ echo ""
 echo "Note: This script packages and installs chaincode."
 echo "For production deployments, use Fabric lifecycle commands to:"
 echo "  1. Have each org approve the chaincode definition"
+echo "     (landregistry: with --collections-config generated per state channel from"
+echo "      chaincode/land-registry/collections_config.template.json; see collections_config)"
 echo "  2. Commit the definition to the channel"
 echo "  3. Invoke Init transaction if needed"
 echo ""